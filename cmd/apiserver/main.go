@@ -1,14 +1,41 @@
 package main
 
 import (
+	"flag"
+	"log"
+	"time"
+
 	"github.com/joshL1215/k8s-lite/internal/apiserver"
+	"github.com/joshL1215/k8s-lite/internal/store"
+	"github.com/joshL1215/k8s-lite/internal/store/encryption"
 	"github.com/joshL1215/k8s-lite/internal/store/memory"
 )
 
 const DefaultPort = "8080"
 
+// how often the encryption configuration is checked for changes when automatic reload is on
+const encryptionConfigReloadInterval = time.Minute
+
 func main() {
-	dataStore := memory.CreateInMemoryStore()
+	encryptionConfig := flag.String("encryption-provider-config", "", "JSON or YAML file with the resources to encrypt at rest and the keys to encrypt them with, empty to store everything in plain text")
+	encryptionReload := flag.Bool("encryption-provider-config-automatic-reload", false, "Load the encryption configuration again when the file changes, so keys can be rotated without a restart")
+	flag.Parse()
+
+	var dataStore store.StoreInterface = memory.CreateInMemoryStore()
+	if *encryptionConfig != "" {
+		cfg, err := encryption.LoadConfig(*encryptionConfig)
+		if err != nil {
+			log.Fatalf("Error loading encryption configuration: %v", err)
+		}
+		encryptedStore, err := encryption.CreateEncryptedStore(dataStore, cfg)
+		if err != nil {
+			log.Fatalf("Error setting up encryption at rest: %v", err)
+		}
+		if *encryptionReload {
+			go encryptedStore.WatchConfig(*encryptionConfig, encryptionConfigReloadInterval)
+		}
+		dataStore = encryptedStore
+	}
 	apiServer := apiserver.CreateAPIServer(dataStore)
 	apiServer.Serve(":" + DefaultPort)
 }
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/controller"
+)
+
+func main() {
+	apiAddress := flag.String("api-server-url", "http://localhost:8080", "URL of the API server")
+	resyncPeriod := flag.Duration("resync-period", 15*time.Second, "Time between full resyncs of every controller")
+	flag.Parse()
+
+	log.Print("Starting controller manager...")
+
+	cl, err := client.NewClient(*apiAddress)
+	if err != nil {
+		log.Fatalf("Error creating client: %v", err)
+	}
+
+	disruption := &controller.DisruptionController{Client: cl, ResyncPeriod: *resyncPeriod}
+	log.Print("Running disruption controller")
+	if err := disruption.Run(); err != nil {
+		log.Fatalf("Disruption controller stopped: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/descheduler"
+)
+
+func main() {
+	apiAddress := flag.String("api-server-url", "http://localhost:8080", "URL of the API server")
+	policyPath := flag.String("policy", "", "Path to a JSON or YAML descheduler policy, the default policy enables every strategy")
+	interval := flag.Duration("interval", 5*time.Minute, "Time between descheduling passes")
+	once := flag.Bool("once", false, "Run a single pass and exit")
+	dryRun := flag.Bool("dry-run", false, "Log the evictions a pass would make without evicting anything")
+	flag.Parse()
+
+	log.Print("Starting descheduler...")
+
+	cl, err := client.NewClient(*apiAddress)
+	if err != nil {
+		log.Fatalf("Error creating client: %v", err)
+	}
+
+	policy := descheduler.DefaultPolicy()
+	if *policyPath != "" {
+		policy, err = descheduler.LoadPolicy(*policyPath)
+		if err != nil {
+			log.Fatalf("Error loading descheduler policy: %v", err)
+		}
+	}
+
+	d := &descheduler.Descheduler{Client: cl, Policy: policy, DryRun: *dryRun}
+	if *once {
+		if err := d.RunOnce(); err != nil {
+			log.Fatalf("Descheduling pass failed: %v", err)
+		}
+		return
+	}
+	d.Run(*interval)
+}
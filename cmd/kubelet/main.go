@@ -2,9 +2,14 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
+	"net/url"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/joshL1215/k8s-lite/internal/api/models"
 	"github.com/joshL1215/k8s-lite/internal/kubelet"
 )
 
@@ -16,24 +21,119 @@ const DefaultNamespace = "default"
 func main() {
 	nodeName := flag.String("node-name", "", "Name of the node being registered")
 	nodeAddress := flag.String("node-address", "http://localhost:8081", "Address of the node being registered")
+	nodeIP := flag.String("node-ip", "", "IP reported for the node's pods, defaults to the host of -node-address")
 	apiAddress := flag.String("api-server-url", "http://localhost:8080", "URL of the API server")
+	cpuCapacity := flag.Int64("cpu-capacity", int64(runtime.NumCPU())*1000, "CPU offered to pods in millicores")
+	memoryCapacity := flag.Int64("memory-capacity", 0, "Memory offered to pods in bytes, 0 for unbounded")
+	maxPods := flag.Int64("max-pods", 110, "Maximum number of pods the node will run")
+	nodeLabels := flag.String("node-labels", "", "Comma separated key=value labels to register the node with, e.g. topology.kubernetes.io/zone=a")
+	rootDir := flag.String("root-dir", "", "Directory holding pod and container files, defaults to a directory under the system temp dir")
+	logMaxSize := flag.Int64("container-log-max-size", kubelet.DefaultContainerLogMaxSize, "Size in bytes at which a container log file is rotated")
+	logMaxFiles := flag.Int("container-log-max-files", kubelet.DefaultContainerLogMaxFiles, "Number of log files kept per container run, including the current one")
+	nodeTaints := flag.String("register-with-taints", "", "Comma separated key=value:Effect taints to register the node with")
+	evictionHard := flag.String("eviction-hard", kubelet.DefaultEvictionHard, "Comma separated signal<quantity thresholds that evict pods at once, quantities in bytes with an optional Ki/Mi/Gi/Ti suffix or as a percentage of capacity")
+	evictionSoft := flag.String("eviction-soft", "", "Comma separated signal<quantity thresholds that evict pods once met for their grace period")
+	evictionSoftGrace := flag.String("eviction-soft-grace-period", "", "Comma separated signal=duration grace periods for the soft eviction thresholds")
+	evictionInterval := flag.Duration("eviction-monitoring-interval", kubelet.DefaultEvictionMonitoringPeriod, "How often the eviction thresholds are checked")
+	evictionTransition := flag.Duration("eviction-pressure-transition-period", kubelet.DefaultEvictionPressureTransitionPeriod, "How long a node stays under pressure after its last threshold was met")
+	evictionMaxGrace := flag.Duration("eviction-max-pod-grace-period", kubelet.DefaultEvictionMaxPodGracePeriod, "Grace period given to pods evicted for a soft threshold")
+	cgroupRoot := flag.String("cgroup-root", kubelet.DefaultCgroupRoot, "cgroup v2 directory to create pod cgroups under, empty to run pods without resource enforcement")
+	podManifestPath := flag.String("pod-manifest-path", "", "Directory of JSON or YAML pod manifests to run as static pods, empty for none")
+	fileCheckFrequency := flag.Duration("file-check-frequency", kubelet.DefaultFileCheckFrequency, "How often the static pod manifests are checked for changes")
+	imageLayoutDir := flag.String("image-layout-dir", "", "OCI image layout directory to resolve container images against before any registry")
+	imageRegistry := flag.String("image-registry", "", "URL of the registry to pull images from when their reference names no registry, e.g. http://localhost:5000")
+	imageGCHigh := flag.Int("image-gc-high-threshold", kubelet.DefaultImageGCHighThresholdPercent, "Percentage of disk usage at which unused images are garbage collected")
+	imageGCLow := flag.Int("image-gc-low-threshold", kubelet.DefaultImageGCLowThresholdPercent, "Percentage of disk usage image garbage collection frees down to")
+	imageMinimumGCAge := flag.Duration("minimum-image-gc-age", kubelet.DefaultImageMinimumGCAge, "How long an image has to have gone unused before it can be garbage collected")
 	flag.Parse()
 
 	if *nodeName == "" {
 		log.Fatalf("-node-name flag is required")
 	}
 
-	log.Printf("Kubelet starting for node %s at %node address %s, API server at %s", *nodeName, *nodeAddress, *apiAddress)
+	log.Printf("Kubelet starting for node %s at node address %s, API server at %s", *nodeName, *nodeAddress, *apiAddress)
 
 	k, err := kubelet.NewKubelet(*nodeName, *nodeAddress, *apiAddress)
 	if err != nil {
 		log.Fatalf("Error creating kubelet: %v", err)
 	}
+	if *rootDir != "" {
+		k.RootDir = *rootDir
+	}
+	if *nodeIP != "" {
+		k.NodeIP = *nodeIP
+	}
+	k.ContainerLogMaxSize = *logMaxSize
+	k.ContainerLogMaxFiles = *logMaxFiles
+	hardThresholds, err := kubelet.ParseEvictionThresholds(*evictionHard, false, "")
+	if err != nil {
+		log.Fatalf("Error parsing -eviction-hard: %v", err)
+	}
+	softThresholds, err := kubelet.ParseEvictionThresholds(*evictionSoft, true, *evictionSoftGrace)
+	if err != nil {
+		log.Fatalf("Error parsing -eviction-soft: %v", err)
+	}
+	k.EvictionThresholds = append(hardThresholds, softThresholds...)
+	k.EvictionMonitoringPeriod = *evictionInterval
+	k.EvictionPressureTransitionPeriod = *evictionTransition
+	k.EvictionMaxPodGracePeriod = *evictionMaxGrace
+	if *cgroupRoot != "" {
+		k.EnableCgroups(*cgroupRoot)
+	}
+	if *imageGCLow < 0 || *imageGCLow > *imageGCHigh || *imageGCHigh > 100 {
+		log.Fatalf("-image-gc-low-threshold must be between 0 and -image-gc-high-threshold, which must be at most 100")
+	}
+	k.ImageGCHighThresholdPercent = *imageGCHigh
+	k.ImageGCLowThresholdPercent = *imageGCLow
+	k.ImageMinimumGCAge = *imageMinimumGCAge
+	if *imageLayoutDir != "" || *imageRegistry != "" {
+		if err := k.EnableImages(*imageLayoutDir, *imageRegistry); err != nil {
+			log.Fatalf("Error setting up images: %v", err)
+		}
+	}
+	k.StaticPodPath = *podManifestPath
+	k.FileCheckFrequency = *fileCheckFrequency
+	k.Capacity = models.ResourceList{
+		CPU:    *cpuCapacity,
+		Memory: *memoryCapacity,
+		Pods:   *maxPods,
+	}
+	k.Labels, err = parseLabels(*nodeLabels)
+	if err != nil {
+		log.Fatalf("Error parsing -node-labels: %v", err)
+	}
+	k.Labels[models.LabelHostname] = *nodeName
+	k.Taints, err = parseTaints(*nodeTaints)
+	if err != nil {
+		log.Fatalf("Error parsing -register-with-taints: %v", err)
+	}
+
+	// static pods run whether or not the API server can be reached
+	go k.RunStaticPods()
 
-	if err := k.RegisterNode(); err != nil {
-		log.Fatalf("Error registering node: %v", err)
+	for {
+		err := k.RegisterNode()
+		if err == nil {
+			break
+		}
+		log.Printf("Error registering node, retrying in %v: %v", syncInterval, err)
+		time.Sleep(syncInterval)
 	}
 
+	// the kubelet API listens on the port of the address registered for the node, on every interface
+	listenAddress, err := listenAddressFor(*nodeAddress)
+	if err != nil {
+		log.Fatalf("Error parsing -node-address: %v", err)
+	}
+	go func() {
+		if err := k.Serve(listenAddress); err != nil {
+			log.Fatalf("Kubelet API stopped: %v", err)
+		}
+	}()
+	go k.RunEvictionManager()
+	go k.RunConfigWatcher()
+	go k.RunImageManager()
+
 	log.Printf("Successfully registed node %s. Kubelet will synchronize pod state on schedule events and on interval of %v", *nodeName, syncInterval)
 
 	log.Printf("Attempting to watch pods for scheduling events...")
@@ -47,11 +147,18 @@ func main() {
 
 	for {
 		select {
-		case event := <-ch:
+		case event, ok := <-ch:
+			if !ok {
+				// the watch is opened again on the next sync
+				log.Printf("Watch on pods ended, synchronizing pod state on interval only until it is reopened")
+				ch = nil
+				continue
+			}
 			eventPod := *event.Pod
 			log.Printf("Received event: %v", event)
 
-			if eventPod.NodeName != *nodeName {
+			// deletion events only carry the pod's name, so they always trigger a sync
+			if eventPod.NodeName != *nodeName && event.EventType != models.DeletionEvent {
 				log.Printf("Ignoring pod event not associated with registered node")
 				continue
 			}
@@ -61,8 +168,61 @@ func main() {
 
 		case <-ticker.C:
 			log.Printf("Periodic sync interval reached, synchronizing pod state")
+			if ch == nil {
+				if ch, err = k.Client.WatchPods(DefaultNamespace); err != nil {
+					log.Printf("Error watching pods: %v", err)
+				}
+			}
 			k.SyncPods()
 		}
 	}
 
 }
+
+func parseLabels(labelStr string) (map[string]string, error) {
+	labels := make(map[string]string)
+	if labelStr == "" {
+		return labels, nil
+	}
+	for _, pair := range strings.Split(labelStr, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("label %q is not of the form key=value", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+func parseTaints(taintStr string) ([]models.Taint, error) {
+	var taints []models.Taint
+	if taintStr == "" {
+		return taints, nil
+	}
+	for _, spec := range strings.Split(taintStr, ",") {
+		keyValue, effect, ok := strings.Cut(spec, ":")
+		if !ok {
+			return nil, fmt.Errorf("taint %q is not of the form key=value:Effect", spec)
+		}
+		key, value, _ := strings.Cut(keyValue, "=")
+		switch models.TaintEffect(effect) {
+		case models.TaintEffectNoSchedule, models.TaintEffectPreferNoSchedule, models.TaintEffectNoExecute:
+		default:
+			return nil, fmt.Errorf("taint %q has unknown effect %s", spec, effect)
+		}
+		taints = append(taints, models.Taint{Key: key, Value: value, Effect: models.TaintEffect(effect)})
+	}
+	return taints, nil
+}
+
+func listenAddressFor(nodeAddress string) (string, error) {
+	u, err := url.Parse(nodeAddress)
+	if err != nil {
+		return "", err
+	}
+	port := u.Port()
+	if port == "" {
+		return "", fmt.Errorf("node address %s has no port", nodeAddress)
+	}
+	return ":" + port, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store/encryption"
+)
+
+// Rewrites every object of the encrypted resources through the API server, which stores each one again under the
+// newest key of its encryption configuration. Run it after adding a key and before removing the old one. It walks
+// every namespace unless one is given
+
+func main() {
+	apiAddress := flag.String("api-server-url", "http://localhost:8080", "URL of the API server")
+	namespace := flag.String("namespace", "", "Namespace whose objects are re-encrypted, every namespace when empty")
+	resources := flag.String("resources", encryption.ResourceSecrets, "Comma separated resources to re-encrypt, secrets and configmaps")
+	flag.Parse()
+
+	cl, err := client.NewClient(*apiAddress)
+	if err != nil {
+		log.Fatalf("Error creating client: %v", err)
+	}
+
+	failed := false
+	for _, resource := range strings.Split(*resources, ",") {
+		var rewritten int
+		var err error
+		switch resource {
+		case encryption.ResourceSecrets:
+			rewritten, err = rewriteSecrets(cl, *namespace)
+		case encryption.ResourceConfigMaps:
+			rewritten, err = rewriteConfigMaps(cl, *namespace)
+		default:
+			log.Fatalf("unknown resource %s", resource)
+		}
+		if *namespace == "" {
+			fmt.Printf("%s: re-encrypted %d across all namespaces\n", resource, rewritten)
+		} else {
+			fmt.Printf("%s: re-encrypted %d in namespace %s\n", resource, rewritten, *namespace)
+		}
+		if err != nil {
+			log.Printf("Error re-encrypting %s: %v", resource, err)
+			failed = true
+		}
+	}
+	if failed {
+		log.Fatalf("Some objects were not re-encrypted, run again once the errors are fixed")
+	}
+}
+
+// objects are written back unchanged. One that fails is reported and the rest are still rewritten. An empty
+// namespace rewrites those of every namespace
+func rewriteSecrets(cl *client.Client, namespace string) (int, error) {
+	var secrets []models.Secret
+	var err error
+	if namespace == "" {
+		secrets, err = cl.ListAllSecrets()
+	} else {
+		secrets, err = cl.ListSecrets(namespace)
+	}
+	if err != nil {
+		return 0, err
+	}
+	var rewritten int
+	var errs []string
+	for _, secret := range secrets {
+		if _, err := cl.UpdateSecret(&secret); err != nil {
+			errs = append(errs, fmt.Sprintf("%s/%s: %v", secret.Namespace, secret.Name, err))
+			continue
+		}
+		rewritten++
+	}
+	if len(errs) > 0 {
+		return rewritten, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return rewritten, nil
+}
+
+func rewriteConfigMaps(cl *client.Client, namespace string) (int, error) {
+	var configMaps []models.ConfigMap
+	var err error
+	if namespace == "" {
+		configMaps, err = cl.ListAllConfigMaps()
+	} else {
+		configMaps, err = cl.ListConfigMaps(namespace)
+	}
+	if err != nil {
+		return 0, err
+	}
+	var rewritten int
+	var errs []string
+	for _, cm := range configMaps {
+		if _, err := cl.UpdateConfigMap(&cm); err != nil {
+			errs = append(errs, fmt.Sprintf("%s/%s: %v", cm.Namespace, cm.Name, err))
+			continue
+		}
+		rewritten++
+	}
+	if len(errs) > 0 {
+		return rewritten, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return rewritten, nil
+}
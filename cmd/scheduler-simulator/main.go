@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/manifest"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/scheduler"
+)
+
+// Runs the scheduler's placement logic against a cluster snapshot and prints where pending pods would land,
+// without binding, evicting or writing anything
+
+const DefaultNamespace = "default"
+
+func main() {
+	snapshotPath := flag.String("snapshot", "", "JSON or YAML file with nodes, pods, priorityClasses and podGroups to simulate against")
+	apiAddress := flag.String("api-server-url", "", "Pull the snapshot from a running API server instead of a file")
+	configPath := flag.String("config", "", "Path to a JSON scheduler configuration listing the profiles to run")
+	output := flag.String("output", "text", "Output format, text or json")
+	flag.Parse()
+
+	if (*snapshotPath == "") == (*apiAddress == "") {
+		log.Fatalf("exactly one of -snapshot or -api-server-url is required")
+	}
+
+	cfg := scheduler.DefaultConfiguration()
+	if *configPath != "" {
+		var err error
+		cfg, err = scheduler.LoadConfiguration(*configPath)
+		if err != nil {
+			log.Fatalf("Error loading scheduler configuration: %v", err)
+		}
+	}
+
+	var cluster *scheduler.ClusterSnapshot
+	var err error
+	if *snapshotPath != "" {
+		cluster, err = loadSnapshot(*snapshotPath)
+	} else {
+		cluster, err = pullSnapshot(*apiAddress)
+	}
+	if err != nil {
+		log.Fatalf("Error loading cluster snapshot: %v", err)
+	}
+
+	result, err := scheduler.Simulate(cfg, cluster)
+	if err != nil {
+		log.Fatalf("Error running simulation: %v", err)
+	}
+
+	switch *output {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			log.Fatalf("Error encoding result: %v", err)
+		}
+	case "text":
+		printResult(result)
+	default:
+		log.Fatalf("unknown output format %s", *output)
+	}
+}
+
+func loadSnapshot(path string) (*scheduler.ClusterSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cluster scheduler.ClusterSnapshot
+	if err := manifest.Decode(data, &cluster); err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+func pullSnapshot(apiAddress string) (*scheduler.ClusterSnapshot, error) {
+	cl, err := client.NewClient(apiAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	cluster := &scheduler.ClusterSnapshot{}
+	for _, status := range []models.NodeStatus{models.NodeReady, models.NodeNotReady} {
+		nodes, err := cl.ListNodes(status)
+		if err != nil {
+			return nil, err
+		}
+		cluster.Nodes = append(cluster.Nodes, nodes...)
+	}
+	if cluster.Pods, err = cl.ListPods(DefaultNamespace, ""); err != nil {
+		return nil, err
+	}
+	if cluster.PriorityClasses, err = cl.ListPriorityClasses(); err != nil {
+		return nil, err
+	}
+	if cluster.PodGroups, err = cl.ListPodGroups(DefaultNamespace); err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+func printResult(result *scheduler.SimulationResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(w, "PLACEMENTS (%d)\n", len(result.Placements))
+	fmt.Fprintln(w, "POD\tNODE\tPROFILE\tSCORE")
+	for _, p := range result.Placements {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\n", p.Pod, p.Node, p.Profile, p.Score)
+	}
+
+	fmt.Fprintf(w, "\nUNSCHEDULABLE (%d)\n", len(result.Unschedulable))
+	fmt.Fprintln(w, "POD\tREASON\tPREEMPTION")
+	for _, u := range result.Unschedulable {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", u.Pod, u.Reason, u.Preemption)
+	}
+
+	if len(result.Ignored) > 0 {
+		fmt.Fprintf(w, "\nIGNORED (%d)\n", len(result.Ignored))
+		for _, pod := range result.Ignored {
+			fmt.Fprintln(w, pod)
+		}
+	}
+
+	fmt.Fprintln(w, "\nNODE UTILIZATION")
+	fmt.Fprintln(w, "NODE\tREADY\tCPU\tMEMORY\tPODS")
+	for _, u := range result.Utilization {
+		fmt.Fprintf(w, "%s\t%t\t%s\t%s\t%s\n", u.Node, u.Ready,
+			usage(u.Requested.CPU, u.Capacity.CPU, "m"),
+			usage(u.Requested.Memory, u.Capacity.Memory, "B"),
+			usage(u.Requested.Pods, u.Capacity.Pods, ""))
+	}
+	w.Flush()
+}
+
+func usage(requested, capacity int64, unit string) string {
+	if capacity <= 0 {
+		return fmt.Sprintf("%d%s/unbounded", requested, unit)
+	}
+	return fmt.Sprintf("%d%s/%d%s (%d%%)", requested, unit, capacity, unit, requested*100/capacity)
+}
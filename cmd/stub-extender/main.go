@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/scheduler/stubextender"
+)
+
+// Serves the stub scheduler extender for trying out the extender protocol locally, see internal/scheduler/stubextender
+
+func main() {
+	port := flag.String("port", "8090", "Port to serve the extender on")
+	apiAddress := flag.String("api-server-url", "http://localhost:8080", "URL of the API server, used by the bind verb")
+	rejectLabel := flag.String("reject-label", "", "key=value label of nodes the filter verb rejects")
+	preferLabel := flag.String("prefer-label", "", "key=value label of nodes the prioritize verb scores highest")
+	delay := flag.Duration("delay", 0, "Delay before answering each request")
+	fail := flag.Bool("fail", false, "Answer every request with a server error")
+	flag.Parse()
+
+	cl, err := client.NewClient(*apiAddress)
+	if err != nil {
+		log.Fatalf("Error creating client: %v", err)
+	}
+
+	e := &stubextender.Extender{Delay: *delay, Fail: *fail, Client: cl}
+	e.RejectKey, e.RejectValue, _ = strings.Cut(*rejectLabel, "=")
+	e.PreferKey, e.PreferValue, _ = strings.Cut(*preferLabel, "=")
+
+	log.Printf("Stub extender serving filter, prioritize and bind on port %s", *port)
+	if err := http.ListenAndServe(":"+*port, e.Handler()); err != nil {
+		log.Fatalf("Could not serve stub extender: %v", err)
+	}
+}
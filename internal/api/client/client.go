@@ -2,16 +2,25 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+	"github.com/joshL1215/k8s-lite/internal/store"
 )
 
+// ErrEvictionRefused means a pod disruption budget would be violated by the eviction, which may succeed later
+var ErrEvictionRefused = errors.New("eviction refused by a pod disruption budget")
+
 type Client struct {
 	baseURL    *url.URL
 	httpClient *http.Client
@@ -62,6 +71,9 @@ func (c *Client) CreateNode(node *models.Node) (*models.Node, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusConflict {
+		return nil, fmt.Errorf("%w: node %s is already registered", store.ErrNodeExists, node.Name)
+	}
 	if resp.StatusCode != http.StatusCreated {
 		return nil, fmt.Errorf("failed to register node, status code: %d", resp.StatusCode)
 	}
@@ -138,7 +150,7 @@ func (c *Client) DeleteNode(nodeName string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("failed to delete node, status code: %d", resp.StatusCode)
 	}
 	return nil
@@ -222,6 +234,9 @@ func (c *Client) GetPod(namespace, podName string) (*models.Pod, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: pod %s/%s", store.ErrPodNotExist, namespace, podName)
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("failed to fetch pod, status code: %d", resp.StatusCode)
 	}
@@ -233,6 +248,35 @@ func (c *Client) GetPod(namespace, podName string) (*models.Pod, error) {
 	return &fetchedPod, nil
 }
 
+// GetPodStats reads a pod's resource usage from the kubelet running it
+func (c *Client) GetPodStats(namespace, podName string) (*models.PodStats, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "stats")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch pod stats: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch pod stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pod stats, status code: %d", resp.StatusCode)
+	}
+
+	var stats models.PodStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &stats, nil
+}
+
 func (c *Client) ListPods(namespace string, filterPhase models.PodPhase) ([]models.Pod, error) {
 	if namespace == "" {
 		namespace = "default"
@@ -289,12 +333,111 @@ func (c *Client) DeletePod(namespace, podName string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusNoContent {
+	if resp.StatusCode == http.StatusConflict {
+		return fmt.Errorf("%w: pod %s/%s", store.ErrPodIsDeleting, namespace, podName)
+	}
+	// the API server answers deletions with a 200 and a message body
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		return fmt.Errorf("failed to delete pod, status code: %d", resp.StatusCode)
 	}
 	return nil
 }
 
+// GetPodLogs opens a container's log. With Follow set the stream stays open for new output until the container
+// stops or the caller closes it
+func (c *Client) GetPodLogs(namespace, podName string, opts models.PodLogOptions) (io.ReadCloser, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	query := url.Values{}
+	if opts.Container != "" {
+		query.Set("container", opts.Container)
+	}
+	if opts.Follow {
+		query.Set("follow", "true")
+	}
+	if opts.TailLines != nil {
+		query.Set("tailLines", strconv.FormatInt(*opts.TailLines, 10))
+	}
+	if opts.SinceSeconds != nil {
+		query.Set("sinceSeconds", strconv.FormatInt(*opts.SinceSeconds, 10))
+	}
+	if opts.Timestamps {
+		query.Set("timestamps", "true")
+	}
+	if opts.Previous {
+		query.Set("previous", "true")
+	}
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "log")
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch pod logs: %w", err)
+	}
+
+	// the shared client's timeout would cut a followed log off
+	logClient := &http.Client{Transport: c.httpClient.Transport}
+	resp, err := logClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch pod logs: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to fetch pod logs, status code: %d", resp.StatusCode)
+	}
+	return resp.Body, nil
+}
+
+// ExecPod runs a command in a container and returns the upgraded connection. Frames on the stdout and stderr
+// channels carry its output and a stream.Status on the error channel reports how it ended
+func (c *Client) ExecPod(ctx context.Context, namespace, podName string, opts models.PodExecOptions) (*stream.Conn, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	query := streamQuery(opts.Container, opts.Stdin, opts.Stdout, opts.Stderr, opts.TTY)
+	for _, arg := range opts.Command {
+		query.Add("command", arg)
+	}
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "exec") + "?" + query.Encode()
+	conn, err := stream.Dial(ctx, "POST", urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening exec stream: %w", err)
+	}
+	return conn, nil
+}
+
+// AttachPod connects to the main process of a container and returns the upgraded connection
+func (c *Client) AttachPod(ctx context.Context, namespace, podName string, opts models.PodAttachOptions) (*stream.Conn, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	query := streamQuery(opts.Container, opts.Stdin, opts.Stdout, opts.Stderr, opts.TTY)
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "attach") + "?" + query.Encode()
+	conn, err := stream.Dial(ctx, "POST", urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening attach stream: %w", err)
+	}
+	return conn, nil
+}
+
+func streamQuery(container string, stdin, stdout, stderr, tty bool) url.Values {
+	query := url.Values{}
+	if container != "" {
+		query.Set("container", container)
+	}
+	for name, set := range map[string]bool{"stdin": stdin, "stdout": stdout, "stderr": stderr, "tty": tty} {
+		if set {
+			query.Set(name, "true")
+		}
+	}
+	return query
+}
+
 func (c *Client) UpdatePod(pod *models.Pod) (*models.Pod, error) {
 	body, err := json.Marshal(pod)
 	if err != nil {
@@ -325,15 +468,920 @@ func (c *Client) UpdatePod(pod *models.Pod) (*models.Pod, error) {
 	return &updatedPod, nil
 }
 
-func (c *Client) WatchPods(namespace string) (<-chan models.WatchEvent, error) {
+// PriorityClass operations from client
+
+func (c *Client) CreatePriorityClass(pc *models.PriorityClass) (*models.PriorityClass, error) {
+	body, err := json.Marshal(pc)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling priority class: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", c.buildURL("api", "v1", "priorityclasses"), bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create priority class: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create priority class: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create priority class, status code: %d", resp.StatusCode)
+	}
+
+	var createdPC models.PriorityClass
+	if err := json.NewDecoder(resp.Body).Decode(&createdPC); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &createdPC, nil
+}
+
+func (c *Client) GetPriorityClass(name string) (*models.PriorityClass, error) {
+	req, err := http.NewRequest("GET", c.buildURL("api", "v1", "priorityclasses", name), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch priority class: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch priority class: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch priority class, status code: %d", resp.StatusCode)
+	}
+
+	var fetchedPC models.PriorityClass
+	if err := json.NewDecoder(resp.Body).Decode(&fetchedPC); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetchedPC, nil
+}
+
+func (c *Client) ListPriorityClasses() ([]models.PriorityClass, error) {
+	req, err := http.NewRequest("GET", c.buildURL("api", "v1", "priorityclasses"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list priority classes: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list priority classes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list priority classes, status code: %d", resp.StatusCode)
+	}
+
+	var pcs []models.PriorityClass
+	if err := json.NewDecoder(resp.Body).Decode(&pcs); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return pcs, nil
+}
+
+func (c *Client) DeletePriorityClass(name string) error {
+	req, err := http.NewRequest("DELETE", c.buildURL("api", "v1", "priorityclasses", name), nil)
+	if err != nil {
+		return fmt.Errorf("error while creating DELETE request to delete priority class: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making DELETE request to delete priority class: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete priority class, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PodGroup operations from client
+
+func (c *Client) CreatePodGroup(pg *models.PodGroup) (*models.PodGroup, error) {
+	body, err := json.Marshal(pg)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling pod group: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", pg.Namespace, "podgroups")
+	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create pod group: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create pod group: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create pod group, status code: %d", resp.StatusCode)
+	}
+
+	var createdPG models.PodGroup
+	if err := json.NewDecoder(resp.Body).Decode(&createdPG); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &createdPG, nil
+}
+
+func (c *Client) GetPodGroup(namespace, name string) (*models.PodGroup, error) {
 	if namespace == "" {
 		namespace = "default"
 	}
 
-	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods") + "?watch=true"
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "podgroups", name)
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch pod group: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch pod group: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pod group, status code: %d", resp.StatusCode)
+	}
+
+	var fetchedPG models.PodGroup
+	if err := json.NewDecoder(resp.Body).Decode(&fetchedPG); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetchedPG, nil
+}
+
+func (c *Client) ListPodGroups(namespace string) ([]models.PodGroup, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "podgroups")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list pod groups: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list pod groups: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list pod groups, status code: %d", resp.StatusCode)
+	}
+
+	var pgs []models.PodGroup
+	if err := json.NewDecoder(resp.Body).Decode(&pgs); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return pgs, nil
+}
+
+func (c *Client) DeletePodGroup(namespace, name string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "podgroups", name)
+	req, err := http.NewRequest("DELETE", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("error while creating DELETE request to delete pod group: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making DELETE request to delete pod group: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete pod group, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// PodDisruptionBudget operations from client
+
+func (c *Client) CreatePodDisruptionBudget(pdb *models.PodDisruptionBudget) (*models.PodDisruptionBudget, error) {
+	body, err := json.Marshal(pdb)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling pod disruption budget: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", pdb.Namespace, "poddisruptionbudgets")
+	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create pod disruption budget: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create pod disruption budget: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create pod disruption budget, status code: %d", resp.StatusCode)
+	}
+
+	var createdPDB models.PodDisruptionBudget
+	if err := json.NewDecoder(resp.Body).Decode(&createdPDB); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &createdPDB, nil
+}
+
+func (c *Client) GetPodDisruptionBudget(namespace, name string) (*models.PodDisruptionBudget, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "poddisruptionbudgets", name)
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch pod disruption budget: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch pod disruption budget: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch pod disruption budget, status code: %d", resp.StatusCode)
+	}
+
+	var fetchedPDB models.PodDisruptionBudget
+	if err := json.NewDecoder(resp.Body).Decode(&fetchedPDB); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetchedPDB, nil
+}
+
+func (c *Client) ListPodDisruptionBudgets(namespace string) ([]models.PodDisruptionBudget, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "poddisruptionbudgets")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list pod disruption budgets: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list pod disruption budgets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list pod disruption budgets, status code: %d", resp.StatusCode)
+	}
+
+	var pdbs []models.PodDisruptionBudget
+	if err := json.NewDecoder(resp.Body).Decode(&pdbs); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return pdbs, nil
+}
+
+// UpdatePodDisruptionBudgetStatus writes only the budget's status, the spec is left as stored
+func (c *Client) UpdatePodDisruptionBudgetStatus(pdb *models.PodDisruptionBudget) (*models.PodDisruptionBudget, error) {
+	body, err := json.Marshal(pdb.Status)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling pod disruption budget status: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", pdb.Namespace, "poddisruptionbudgets", pdb.Name, "status")
+	req, err := http.NewRequest("PUT", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating PUT request to update pod disruption budget status: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making PUT request to update pod disruption budget status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to update pod disruption budget status, status code: %d", resp.StatusCode)
+	}
+
+	var updatedPDB models.PodDisruptionBudget
+	if err := json.NewDecoder(resp.Body).Decode(&updatedPDB); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &updatedPDB, nil
+}
+
+func (c *Client) DeletePodDisruptionBudget(namespace, name string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "poddisruptionbudgets", name)
+	req, err := http.NewRequest("DELETE", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("error while creating DELETE request to delete pod disruption budget: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making DELETE request to delete pod disruption budget: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete pod disruption budget, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// EvictPod asks the API server to delete the pod within its disruption budgets. ErrEvictionRefused is returned
+// when a budget cannot spare the pod right now
+func (c *Client) EvictPod(namespace, podName string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "eviction")
+	req, err := http.NewRequest("POST", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("error while creating POST request to evict pod: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making POST request to evict pod: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusCreated, http.StatusOK:
+		return nil
+	case http.StatusTooManyRequests:
+		var body struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf("%w: %s", ErrEvictionRefused, body.Error)
+	default:
+		return fmt.Errorf("failed to evict pod, status code: %d", resp.StatusCode)
+	}
+}
+
+// Event operations from client
+
+func (c *Client) CreateEvent(event *models.Event) (*models.Event, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling event: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", event.Namespace, "events")
+	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create event: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create event, status code: %d", resp.StatusCode)
+	}
+
+	var createdEvent models.Event
+	if err := json.NewDecoder(resp.Body).Decode(&createdEvent); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &createdEvent, nil
+}
+
+func (c *Client) GetEvent(namespace, name string) (*models.Event, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "events", name)
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch event: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch event, status code: %d", resp.StatusCode)
+	}
+
+	var fetchedEvent models.Event
+	if err := json.NewDecoder(resp.Body).Decode(&fetchedEvent); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetchedEvent, nil
+}
+
+func (c *Client) UpdateEvent(event *models.Event) (*models.Event, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling event: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", event.Namespace, "events", event.Name)
+	req, err := http.NewRequest("PUT", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating PUT request to update event: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making PUT request to update event: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to update event, status code: %d", resp.StatusCode)
+	}
+
+	var updatedEvent models.Event
+	if err := json.NewDecoder(resp.Body).Decode(&updatedEvent); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &updatedEvent, nil
+}
+
+// ListEvents lists the events in a namespace, narrowed to one object when kind and name are given
+func (c *Client) ListEvents(namespace, kind, name string) ([]models.Event, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	query := url.Values{}
+	if kind != "" {
+		query.Set("kind", kind)
+	}
+	if name != "" {
+		query.Set("name", name)
+	}
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "events")
+	if len(query) > 0 {
+		urlStr += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list events: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list events: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list events, status code: %d", resp.StatusCode)
+	}
+
+	var events []models.Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return events, nil
+}
+
+// RecordEvent creates the event, or if one of the same name exists, bumps its count and replaces its contents
+func (c *Client) RecordEvent(event *models.Event) error {
+	existing, err := c.GetEvent(event.Namespace, event.Name)
+	if err != nil {
+		_, err = c.CreateEvent(event)
+		return err
+	}
+
+	now := time.Now()
+	updated := *event
+	updated.Count = existing.Count + 1
+	updated.FirstTimestamp = existing.FirstTimestamp
+	updated.LastTimestamp = now
+	_, err = c.UpdateEvent(&updated)
+	return err
+}
+
+// ConfigMap operations from client
+
+func (c *Client) CreateConfigMap(cm *models.ConfigMap) (*models.ConfigMap, error) {
+	body, err := json.Marshal(cm)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling config map: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", cm.Namespace, "configmaps")
+	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create config map: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create config map: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create config map, status code: %d", resp.StatusCode)
+	}
+
+	var created models.ConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &created, nil
+}
+
+func (c *Client) GetConfigMap(namespace, name string) (*models.ConfigMap, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "configmaps", name)
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch config map: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch config map: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: config map %s/%s", store.ErrConfigMapNotExist, namespace, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config map, status code: %d", resp.StatusCode)
+	}
+
+	var fetched models.ConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetched, nil
+}
+
+func (c *Client) ListConfigMaps(namespace string) ([]models.ConfigMap, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "configmaps")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list config maps: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list config maps: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list config maps, status code: %d", resp.StatusCode)
+	}
+
+	var list []models.ConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return list, nil
+}
+
+// ListAllConfigMaps lists the config maps of every namespace
+func (c *Client) ListAllConfigMaps() ([]models.ConfigMap, error) {
+	urlStr := c.buildURL("api", "v1", "configmaps")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list config maps: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list config maps: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list config maps, status code: %d", resp.StatusCode)
+	}
+
+	var list []models.ConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return list, nil
+}
+
+func (c *Client) UpdateConfigMap(cm *models.ConfigMap) (*models.ConfigMap, error) {
+	body, err := json.Marshal(cm)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling config map: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", cm.Namespace, "configmaps", cm.Name)
+	req, err := http.NewRequest("PUT", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating PUT request to update config map: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making PUT request to update config map: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: config map %s/%s", store.ErrConfigMapNotExist, cm.Namespace, cm.Name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to update config map, status code: %d", resp.StatusCode)
+	}
+
+	var updated models.ConfigMap
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteConfigMap(namespace, name string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "configmaps", name)
+	req, err := http.NewRequest("DELETE", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("error while creating DELETE request to delete config map: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making DELETE request to delete config map: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: config map %s/%s", store.ErrConfigMapNotExist, namespace, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete config map, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) WatchConfigMaps(namespace string) (<-chan models.WatchEvent, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "configmaps") + "?watch=true"
+	return c.watch(urlStr, "configmap")
+}
+
+// Secret operations from client
+
+func (c *Client) CreateSecret(secret *models.Secret) (*models.Secret, error) {
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling secret: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", secret.Namespace, "secrets")
+	req, err := http.NewRequest("POST", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating POST request to create secret: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making POST request to create secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("failed to create secret, status code: %d", resp.StatusCode)
+	}
+
+	var created models.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &created, nil
+}
+
+func (c *Client) GetSecret(namespace, name string) (*models.Secret, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "secrets", name)
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to fetch secret: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to fetch secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: secret %s/%s", store.ErrSecretNotExist, namespace, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch secret, status code: %d", resp.StatusCode)
+	}
+
+	var fetched models.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&fetched); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &fetched, nil
+}
+
+func (c *Client) ListSecrets(namespace string) ([]models.Secret, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "secrets")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list secrets: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list secrets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list secrets, status code: %d", resp.StatusCode)
+	}
+
+	var list []models.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return list, nil
+}
+
+// ListAllSecrets lists the secrets of every namespace
+func (c *Client) ListAllSecrets() ([]models.Secret, error) {
+	urlStr := c.buildURL("api", "v1", "secrets")
+	req, err := http.NewRequest("GET", urlStr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating GET request to list secrets: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making GET request to list secrets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to list secrets, status code: %d", resp.StatusCode)
+	}
+
+	var list []models.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return list, nil
+}
+
+func (c *Client) UpdateSecret(secret *models.Secret) (*models.Secret, error) {
+	body, err := json.Marshal(secret)
+	if err != nil {
+		return nil, fmt.Errorf("error while marshalling secret: %w", err)
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", secret.Namespace, "secrets", secret.Name)
+	req, err := http.NewRequest("PUT", urlStr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, fmt.Errorf("error while creating PUT request to update secret: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while making PUT request to update secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: secret %s/%s", store.ErrSecretNotExist, secret.Namespace, secret.Name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to update secret, status code: %d", resp.StatusCode)
+	}
+
+	var updated models.Secret
+	if err := json.NewDecoder(resp.Body).Decode(&updated); err != nil {
+		return nil, fmt.Errorf("error while decoding response body: %w", err)
+	}
+	return &updated, nil
+}
+
+func (c *Client) DeleteSecret(namespace, name string) error {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "secrets", name)
+	req, err := http.NewRequest("DELETE", urlStr, nil)
+	if err != nil {
+		return fmt.Errorf("error while creating DELETE request to delete secret: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error while making DELETE request to delete secret: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("%w: secret %s/%s", store.ErrSecretNotExist, namespace, name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to delete secret, status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) WatchSecrets(namespace string) (<-chan models.WatchEvent, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "secrets") + "?watch=true"
+	return c.watch(urlStr, "secret")
+}
+
+func (c *Client) WatchPods(namespace string) (<-chan models.WatchEvent, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods") + "?watch=true"
+	return c.watch(urlStr, "pod")
+}
+
+func (c *Client) WatchNodes() (<-chan models.WatchEvent, error) {
+	urlStr := c.buildURL("api", "v1", "nodes") + "?watch=true"
+	return c.watch(urlStr, "node")
+}
+
+// watch opens a long lived request and decodes events of the given object type until the stream ends
+func (c *Client) watch(urlStr string, objectType models.EventObject) (<-chan models.WatchEvent, error) {
 	req, err := http.NewRequest("GET", urlStr, nil)
 	if err != nil {
-		return nil, fmt.Errorf("error while creating GET request to watch pods: %w", err)
+		return nil, fmt.Errorf("error while creating GET request to watch %ss: %w", objectType, err)
 	}
 
 	events := make(chan models.WatchEvent)
@@ -341,15 +1389,17 @@ func (c *Client) WatchPods(namespace string) (<-chan models.WatchEvent, error) {
 
 		defer close(events)
 
-		resp, err := c.httpClient.Do(req)
+		// the shared client's timeout would cut the stream off
+		watchClient := &http.Client{Transport: c.httpClient.Transport}
+		resp, err := watchClient.Do(req)
 		if err != nil {
-			log.Printf("error while making GET request to watch pods: %v", err)
+			log.Printf("error while making GET request to watch %ss: %v", objectType, err)
 			return
 		}
 		defer resp.Body.Close()
 
 		if resp.StatusCode != http.StatusOK {
-			log.Printf("failed to watch pods, status code: %d", resp.StatusCode)
+			log.Printf("failed to watch %ss, status code: %d", objectType, resp.StatusCode)
 			return
 		}
 
@@ -361,7 +1411,7 @@ func (c *Client) WatchPods(namespace string) (<-chan models.WatchEvent, error) {
 				log.Printf("Error decoding watch event: %v", err)
 				return
 			}
-			if event.EventObject == "pod" {
+			if event.EventObject == objectType {
 				events <- event
 			}
 		}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+)
+
+// PortForwarder listens on local ports and tunnels every connection to the matching pod port over a single
+// connection to the API server
+type PortForwarder struct {
+	conn      *stream.Conn
+	tunnels   *stream.Tunnels
+	listeners []net.Listener
+	ports     []models.ForwardedPort
+
+	closeOnce sync.Once
+	done      chan struct{}
+	err       error
+}
+
+// PortForward starts forwarding the given ports of a pod. It returns once the local ports are listening, and
+// forwarding goes on until Close is called or the connection to the API server ends
+func (c *Client) PortForward(ctx context.Context, namespace, podName string, opts models.PortForwardOptions) (*PortForwarder, error) {
+	if namespace == "" {
+		namespace = "default"
+	}
+	if len(opts.Ports) == 0 {
+		return nil, errors.New("at least one port must be forwarded")
+	}
+	address := opts.Address
+	if address == "" {
+		address = "127.0.0.1"
+	}
+
+	remotes := make([]string, 0, len(opts.Ports))
+	for _, port := range opts.Ports {
+		remotes = append(remotes, strconv.Itoa(int(port.Remote)))
+	}
+	query := url.Values{}
+	query.Set("ports", strings.Join(remotes, ","))
+	urlStr := c.buildURL("api", "v1", "namespace", namespace, "pods", podName, "portforward") + "?" + query.Encode()
+	conn, err := stream.Dial(ctx, "POST", urlStr)
+	if err != nil {
+		return nil, fmt.Errorf("error while opening port-forward stream: %w", err)
+	}
+
+	pf := &PortForwarder{
+		conn:    conn,
+		tunnels: stream.NewClientTunnels(conn),
+		done:    make(chan struct{}),
+	}
+	pf.tunnels.OnError = func(port uint16, err error) {
+		log.Printf("Error forwarding to port %d of pod %s/%s: %v", port, namespace, podName, err)
+	}
+	for _, port := range opts.Ports {
+		listener, err := net.Listen("tcp", net.JoinHostPort(address, strconv.Itoa(int(port.Local))))
+		if err != nil {
+			pf.Close()
+			return nil, fmt.Errorf("error while listening for port %d: %w", port.Local, err)
+		}
+		pf.listeners = append(pf.listeners, listener)
+		local := uint16(listener.Addr().(*net.TCPAddr).Port)
+		pf.ports = append(pf.ports, models.ForwardedPort{Local: local, Remote: port.Remote})
+	}
+
+	for i, listener := range pf.listeners {
+		go pf.accept(listener, pf.ports[i].Remote)
+	}
+	go func() {
+		pf.err = pf.tunnels.Serve()
+		pf.Close()
+		close(pf.done)
+	}()
+	return pf, nil
+}
+
+func (pf *PortForwarder) accept(listener net.Listener, remote uint16) {
+	for {
+		local, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		if err := pf.tunnels.Forward(local, remote); err != nil {
+			return
+		}
+	}
+}
+
+// Ports reports the forwarded ports with the local ports actually listened on
+func (pf *PortForwarder) Ports() []models.ForwardedPort {
+	return append([]models.ForwardedPort(nil), pf.ports...)
+}
+
+// Done is closed once forwarding has stopped, after which Err reports why
+func (pf *PortForwarder) Done() <-chan struct{} {
+	return pf.done
+}
+
+func (pf *PortForwarder) Err() error {
+	<-pf.done
+	return pf.err
+}
+
+// Close stops listening and ends every forwarded connection
+func (pf *PortForwarder) Close() error {
+	pf.closeOnce.Do(func() {
+		for _, listener := range pf.listeners {
+			listener.Close()
+		}
+		pf.conn.Close()
+	})
+	return nil
+}
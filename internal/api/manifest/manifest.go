@@ -0,0 +1,20 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/goccy/go-yaml"
+)
+
+// Decode reads a JSON or YAML document into v. YAML is converted to JSON first so the models' json tags apply to both
+func Decode(data []byte, v any) error {
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return fmt.Errorf("error while converting manifest to JSON: %w", err)
+	}
+	if err := json.Unmarshal(jsonData, v); err != nil {
+		return fmt.Errorf("error while decoding manifest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package models
+
+// MaxConfigDataSize caps the combined size of the values of a config map or secret, as upstream
+const MaxConfigDataSize = 1 << 20
+
+// A ConfigMap holds configuration for pods to consume, text under Data and anything else under BinaryData. A key
+// may only appear in one of them
+type ConfigMap struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Data       map[string]string `json:"data,omitempty"`
+	BinaryData map[string][]byte `json:"binaryData,omitempty"`
+}
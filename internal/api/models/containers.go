@@ -0,0 +1,205 @@
+package models
+
+import "time"
+
+// Probe defaults, applied by the API server when a probe leaves them unset
+const (
+	DefaultProbeTimeoutSeconds   = 1
+	DefaultProbePeriodSeconds    = 10
+	DefaultProbeSuccessThreshold = 1
+	DefaultProbeFailureThreshold = 3
+)
+
+// Container restart policy enum
+type ContainerRestartPolicy string
+
+const ContainerRestartPolicyAlways ContainerRestartPolicy = "Always"
+
+// A Container is a process the kubelet runs on behalf of the pod. Command replaces the image's entrypoint and Args
+// are appended to it. A container without an image of its own uses the pod's
+type Container struct {
+	Name  string `json:"name"`
+	Image string `json:"image,omitempty"`
+	// defaults to Always for images tagged latest or not tagged at all, IfNotPresent otherwise
+	ImagePullPolicy PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	Command    []string `json:"command,omitempty"`
+	Args       []string `json:"args,omitempty"`
+	WorkingDir string   `json:"workingDir,omitempty"`
+	Env        []EnvVar `json:"env,omitempty"`
+	// EnvFrom is applied before Env, which wins where the two set the same variable
+	EnvFrom []EnvFromSource `json:"envFrom,omitempty"`
+
+	VolumeMounts []VolumeMount `json:"volumeMounts,omitempty"`
+
+	// RestartPolicy Always makes an init container a sidecar: it starts in its turn among the init containers, the
+	// next one waiting only until it has started, then runs alongside the containers and is stopped after them. It
+	// is restarted whatever the pod's restart policy. Only init containers may set it
+	RestartPolicy ContainerRestartPolicy `json:"restartPolicy,omitempty"`
+
+	// Stdin keeps the container's stdin open for attach, TTY runs it in a terminal
+	Stdin bool `json:"stdin,omitempty"`
+	TTY   bool `json:"tty,omitempty"`
+
+	LivenessProbe  *Probe `json:"livenessProbe,omitempty"`
+	ReadinessProbe *Probe `json:"readinessProbe,omitempty"`
+	StartupProbe   *Probe `json:"startupProbe,omitempty"`
+
+	Lifecycle *Lifecycle `json:"lifecycle,omitempty"`
+}
+
+// An EnvVar takes its value either from Value or from ValueFrom. $(VAR) in Value, Command and Args is replaced by
+// the value of a variable defined before it, $$ escapes a $, and a reference to an undefined variable is left as it is
+type EnvVar struct {
+	Name      string        `json:"name"`
+	Value     string        `json:"value,omitempty"`
+	ValueFrom *EnvVarSource `json:"valueFrom,omitempty"`
+}
+
+// EnvVarSource has exactly one of its fields set
+type EnvVarSource struct {
+	ConfigMapKeyRef  *ConfigMapKeySelector  `json:"configMapKeyRef,omitempty"`
+	SecretKeyRef     *SecretKeySelector     `json:"secretKeyRef,omitempty"`
+	FieldRef         *ObjectFieldSelector   `json:"fieldRef,omitempty"`
+	ResourceFieldRef *ResourceFieldSelector `json:"resourceFieldRef,omitempty"`
+}
+
+// ConfigMapKeySelector picks a key of a config map. A missing config map or key fails the container unless
+// Optional is set, in which case the variable is left out
+type ConfigMapKeySelector struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type SecretKeySelector struct {
+	Name     string `json:"name"`
+	Key      string `json:"key"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// EnvFromSource sets a variable for every key of a config map or secret, named after the key with Prefix in
+// front. Exactly one of its references is set
+type EnvFromSource struct {
+	Prefix       string              `json:"prefix,omitempty"`
+	ConfigMapRef *ConfigMapEnvSource `json:"configMapRef,omitempty"`
+	SecretRef    *SecretEnvSource    `json:"secretRef,omitempty"`
+}
+
+type ConfigMapEnvSource struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+type SecretEnvSource struct {
+	Name     string `json:"name"`
+	Optional bool   `json:"optional,omitempty"`
+}
+
+// ProbeHandler describes a single check. Exactly one of its actions is set
+type ProbeHandler struct {
+	Exec      *ExecAction      `json:"exec,omitempty"`
+	HTTPGet   *HTTPGetAction   `json:"httpGet,omitempty"`
+	TCPSocket *TCPSocketAction `json:"tcpSocket,omitempty"`
+}
+
+// ExecAction runs Command in the container's working directory and environment, succeeding when it exits 0
+type ExecAction struct {
+	Command []string `json:"command"`
+}
+
+// URI scheme enum
+type URIScheme string
+
+const (
+	URISchemeHTTP  URIScheme = "HTTP"
+	URISchemeHTTPS URIScheme = "HTTPS"
+)
+
+// HTTPGetAction succeeds on any status from 200 to 399. Host defaults to localhost since pods share the node's network
+type HTTPGetAction struct {
+	Path        string       `json:"path,omitempty"`
+	Port        int32        `json:"port"`
+	Host        string       `json:"host,omitempty"`
+	Scheme      URIScheme    `json:"scheme,omitempty"`
+	HTTPHeaders []HTTPHeader `json:"httpHeaders,omitempty"`
+}
+
+type HTTPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// TCPSocketAction succeeds when a connection to the port can be opened
+type TCPSocketAction struct {
+	Port int32  `json:"port"`
+	Host string `json:"host,omitempty"`
+}
+
+// A Probe runs its handler every PeriodSeconds once InitialDelaySeconds have passed since the container started.
+// The result flips after SuccessThreshold consecutive successes or FailureThreshold consecutive failures
+type Probe struct {
+	ProbeHandler
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty"`
+	SuccessThreshold    int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty"`
+}
+
+// Lifecycle holds the hooks the kubelet runs around a container. PostStart runs as soon as the container has
+// started, which only counts as running once the hook has succeeded, and a failed hook kills the container.
+// PreStop runs before the container is sent SIGTERM, within the pod's termination grace period
+type Lifecycle struct {
+	PostStart *LifecycleHandler `json:"postStart,omitempty"`
+	PreStop   *LifecycleHandler `json:"preStop,omitempty"`
+}
+
+// LifecycleHandler runs like a probe's handler. Exactly one of its actions is set
+type LifecycleHandler struct {
+	Exec    *ExecAction    `json:"exec,omitempty"`
+	HTTPGet *HTTPGetAction `json:"httpGet,omitempty"`
+}
+
+// A ContainerState has exactly one of its fields set
+type ContainerState struct {
+	Waiting    *ContainerStateWaiting    `json:"waiting,omitempty"`
+	Running    *ContainerStateRunning    `json:"running,omitempty"`
+	Terminated *ContainerStateTerminated `json:"terminated,omitempty"`
+}
+
+type ContainerStateWaiting struct {
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+type ContainerStateRunning struct {
+	StartedAt time.Time `json:"startedAt"`
+}
+
+type ContainerStateTerminated struct {
+	ExitCode   int32     `json:"exitCode"`
+	Signal     string    `json:"signal,omitempty"`
+	Reason     string    `json:"reason,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	StartedAt  time.Time `json:"startedAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// IsSidecar reports whether an init container is a sidecar
+func (c *Container) IsSidecar() bool {
+	return c.RestartPolicy == ContainerRestartPolicyAlways
+}
+
+// ContainerStatus is reported by the kubelet. Started turns true once the startup probe, if any, has passed, and
+// Ready once the readiness probe, if any, has
+type ContainerStatus struct {
+	Name                 string         `json:"name"`
+	Image                string         `json:"image,omitempty"`
+	ImageID              string         `json:"imageID,omitempty"`
+	State                ContainerState `json:"state"`
+	LastTerminationState ContainerState `json:"lastState"`
+	Ready                bool           `json:"ready"`
+	Started              bool           `json:"started"`
+	RestartCount         int32          `json:"restartCount"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ObjectFieldSelector picks a field of the pod for the downward API: metadata.name, metadata.namespace,
+// metadata.labels['<key>'], metadata.annotations['<key>'], spec.nodeName, spec.schedulerName, status.hostIP or
+// status.podIP. Volume files may also take all of metadata.labels or metadata.annotations, one key="value" per line
+type ObjectFieldSelector struct {
+	FieldPath string `json:"fieldPath"`
+}
+
+// Resources the downward API can expose
+const (
+	ResourceLimitsCPU      = "limits.cpu"
+	ResourceLimitsMemory   = "limits.memory"
+	ResourceRequestsCPU    = "requests.cpu"
+	ResourceRequestsMemory = "requests.memory"
+)
+
+// ResourceFieldSelector picks one of the pod's resources for the downward API. The value is divided by Divisor,
+// in millicores or bytes like the resource, and rounded up. Divisor defaults to one core for CPU and one byte for
+// memory. An unset limit reads as what the node can offer
+type ResourceFieldSelector struct {
+	Resource string `json:"resource"`
+	Divisor  int64  `json:"divisor,omitempty"`
+}
+
+// IsWholeMapField reports whether the field path selects all labels or annotations rather than a single key
+func IsWholeMapField(fieldPath string) bool {
+	return fieldPath == "metadata.labels" || fieldPath == "metadata.annotations"
+}
+
+// ExtractFieldPath returns the value of the pod's field at fieldPath, or an error for a path the downward API does
+// not support
+func ExtractFieldPath(pod *Pod, fieldPath string) (string, error) {
+	switch fieldPath {
+	case "metadata.name":
+		return pod.Name, nil
+	case "metadata.namespace":
+		return pod.Namespace, nil
+	case "metadata.labels":
+		return formatMap(pod.Labels), nil
+	case "metadata.annotations":
+		return formatMap(pod.Annotations), nil
+	case "spec.nodeName":
+		return pod.NodeName, nil
+	case "spec.schedulerName":
+		return pod.SchedulerName, nil
+	case "status.hostIP":
+		return pod.HostIP, nil
+	case "status.podIP":
+		return pod.PodIP, nil
+	}
+	for prefix, values := range map[string]map[string]string{"metadata.labels": pod.Labels, "metadata.annotations": pod.Annotations} {
+		if key, ok := strings.CutPrefix(fieldPath, prefix+"['"); ok {
+			if key, ok = strings.CutSuffix(key, "']"); ok && key != "" {
+				return values[key], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("field path %q is not supported", fieldPath)
+}
+
+// formatMap writes one key="value" line per entry, sorted by key and with the value quoted
+func formatMap(values map[string]string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		fmt.Fprintf(&b, "%s=%s\n", key, strconv.Quote(values[key]))
+	}
+	return b.String()
+}
+
+// ExtractResourceValue returns the pod's resource selected by selector. Allocatable is what the node offers, used
+// for a limit the pod leaves unset. A request left unset counts as equal to its limit
+func ExtractResourceValue(pod *Pod, selector *ResourceFieldSelector, allocatable ResourceList) (string, error) {
+	requests, limits := pod.Resources.Requests, pod.Resources.Limits
+	var value, divisor int64
+	switch selector.Resource {
+	case ResourceLimitsCPU:
+		value, divisor = cmp.Or(limits.CPU, allocatable.CPU), 1000
+	case ResourceLimitsMemory:
+		value, divisor = cmp.Or(limits.Memory, allocatable.Memory), 1
+	case ResourceRequestsCPU:
+		value, divisor = cmp.Or(requests.CPU, limits.CPU), 1000
+	case ResourceRequestsMemory:
+		value, divisor = cmp.Or(requests.Memory, limits.Memory), 1
+	default:
+		return "", fmt.Errorf("resource %q is not supported", selector.Resource)
+	}
+	if selector.Divisor < 0 {
+		return "", fmt.Errorf("divisor cannot be negative")
+	}
+	if selector.Divisor > 0 {
+		divisor = selector.Divisor
+	}
+	return strconv.FormatInt((value+divisor-1)/divisor, 10), nil
+}
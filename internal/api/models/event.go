@@ -14,4 +14,6 @@ type WatchEvent struct {
 	EventObject EventObject `json:"objectType"`
 	Pod         *Pod        `json:"pod,omitempty"`
 	Node        *Node       `json:"node,omitempty"`
+	ConfigMap   *ConfigMap  `json:"configMap,omitempty"`
+	Secret      *Secret     `json:"secret,omitempty"`
 }
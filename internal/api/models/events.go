@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// Event severity enum
+type EventSeverity string
+
+const (
+	EventNormal  EventSeverity = "Normal"
+	EventWarning EventSeverity = "Warning"
+)
+
+type ObjectReference struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// An Event records something that happened to an object, such as a failed scheduling attempt. Repeats of the same
+// event bump Count and LastTimestamp instead of creating a new one
+type Event struct {
+	Name           string          `json:"name"`
+	Namespace      string          `json:"namespace"`
+	InvolvedObject ObjectReference `json:"involvedObject"`
+	Reason         string          `json:"reason"`
+	Message        string          `json:"message"`
+	Type           EventSeverity   `json:"type"`
+	Source         string          `json:"source,omitempty"`
+	Count          int32           `json:"count"`
+	FirstTimestamp time.Time       `json:"firstTimestamp"`
+	LastTimestamp  time.Time       `json:"lastTimestamp"`
+
+	SchedulingDiagnosis *SchedulingDiagnosis `json:"schedulingDiagnosis,omitempty"`
+}
+
+// SchedulingDiagnosis explains one scheduling attempt: why each rejected node was filtered out and how the
+// remaining nodes scored
+type SchedulingDiagnosis struct {
+	Profile       string            `json:"profile"`
+	Attempt       int               `json:"attempt"`
+	Time          time.Time         `json:"time"`
+	NodeCount     int               `json:"nodeCount"`
+	FilteredNodes map[string]string `json:"filteredNodes,omitempty"`
+	Scores        []NodeScore       `json:"scores,omitempty"`
+	SelectedNode  string            `json:"selectedNode,omitempty"`
+	Preemption    string            `json:"preemption,omitempty"`
+}
+
+type NodeScore struct {
+	Node    string           `json:"node"`
+	Total   int64            `json:"total"`
+	Plugins map[string]int64 `json:"plugins,omitempty"`
+}
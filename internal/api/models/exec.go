@@ -0,0 +1,22 @@
+package models
+
+// PodExecOptions run Command inside a container of a running pod. At least one of the streams must be requested,
+// and with TTY set the command runs in a terminal whose output all arrives on stdout
+type PodExecOptions struct {
+	Container string
+	Command   []string
+	Stdin     bool
+	Stdout    bool
+	Stderr    bool
+	TTY       bool
+}
+
+// PodAttachOptions connect to the main process of a container. Stdin and TTY need the container to have been
+// created with them
+type PodAttachOptions struct {
+	Container string
+	Stdin     bool
+	Stdout    bool
+	Stderr    bool
+	TTY       bool
+}
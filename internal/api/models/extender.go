@@ -0,0 +1,33 @@
+package models
+
+// Scores returned by an extender's prioritize verb range from 0 to MaxExtenderPriority
+const MaxExtenderPriority int64 = 10
+
+// ExtenderArgs is the body sent to an extender's filter and prioritize verbs
+type ExtenderArgs struct {
+	Pod   *Pod   `json:"pod"`
+	Nodes []Node `json:"nodes"`
+}
+
+// ExtenderFilterResult lists the nodes that passed the extender's filter and why the rest failed
+type ExtenderFilterResult struct {
+	Nodes       []Node            `json:"nodes"`
+	FailedNodes map[string]string `json:"failedNodes,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+type HostPriority struct {
+	Host  string `json:"host"`
+	Score int64  `json:"score"`
+}
+
+// ExtenderBindingArgs is the body sent to an extender's bind verb, which takes over binding the pod
+type ExtenderBindingArgs struct {
+	PodName      string `json:"podName"`
+	PodNamespace string `json:"podNamespace"`
+	Node         string `json:"node"`
+}
+
+type ExtenderBindingResult struct {
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,104 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Image pull policy enum
+type PullPolicy string
+
+const (
+	PullAlways       PullPolicy = "Always"
+	PullIfNotPresent PullPolicy = "IfNotPresent"
+	PullNever        PullPolicy = "Never"
+)
+
+// the tag of an image reference that names neither a tag nor a digest
+const DefaultImageTag = "latest"
+
+// ContainerImage is an image held by a node's kubelet, under every reference it was pulled by
+type ContainerImage struct {
+	Names     []string `json:"names"`
+	SizeBytes int64    `json:"sizeBytes,omitempty"`
+}
+
+var (
+	imageRepositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	imageTagPattern        = regexp.MustCompile(`^\w[\w.-]{0,127}$`)
+	imageDigestPattern     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// ImageReference names an image as [registry/]repository[:tag][@digest]. The first component of the name is only
+// taken for a registry when it has a dot or a port or is localhost, as in localhost:5000/app:v1
+type ImageReference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImageReference splits an image reference into its parts. Only sha256 digests are understood
+func ParseImageReference(image string) (ImageReference, error) {
+	var ref ImageReference
+	name, digest, hasDigest := strings.Cut(image, "@")
+	if hasDigest {
+		if !imageDigestPattern.MatchString(digest) {
+			return ref, fmt.Errorf("image %q has an invalid digest", image)
+		}
+		ref.Digest = digest
+	}
+	if first, rest, ok := strings.Cut(name, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		ref.Registry, name = first, rest
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		ref.Tag = name[i+1:]
+		name = name[:i]
+		if !imageTagPattern.MatchString(ref.Tag) {
+			return ref, fmt.Errorf("image %q has an invalid tag", image)
+		}
+	}
+	if !imageRepositoryPattern.MatchString(name) {
+		return ref, fmt.Errorf("image %q has an invalid repository name", image)
+	}
+	ref.Repository = name
+	return ref, nil
+}
+
+// Name is the reference without its tag or digest
+func (r ImageReference) Name() string {
+	if r.Registry == "" {
+		return r.Repository
+	}
+	return r.Registry + "/" + r.Repository
+}
+
+// String is the reference in full, with the default tag filled in when it names neither a tag nor a digest
+func (r ImageReference) String() string {
+	name := r.Name()
+	if r.Tag != "" || r.Digest == "" {
+		name += ":" + r.TagOrDefault()
+	}
+	if r.Digest != "" {
+		name += "@" + r.Digest
+	}
+	return name
+}
+
+func (r ImageReference) TagOrDefault() string {
+	if r.Tag == "" {
+		return DefaultImageTag
+	}
+	return r.Tag
+}
+
+// DefaultPullPolicy is the pull policy of a container that sets none: images tagged latest, or not at all, may
+// change and are always pulled, anything else only when the node does not have it yet
+func DefaultPullPolicy(image string) PullPolicy {
+	ref, err := ParseImageReference(image)
+	if err == nil && ref.Digest == "" && ref.TagOrDefault() == DefaultImageTag {
+		return PullAlways
+	}
+	return PullIfNotPresent
+}
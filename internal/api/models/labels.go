@@ -0,0 +1,74 @@
+package models
+
+// Well known node labels used as topology keys
+const (
+	LabelHostname     = "kubernetes.io/hostname"
+	LabelTopologyZone = "topology.kubernetes.io/zone"
+)
+
+// Label selector operator enum
+type LabelSelectorOperator string
+
+const (
+	LabelSelectorOpIn           LabelSelectorOperator = "In"
+	LabelSelectorOpNotIn        LabelSelectorOperator = "NotIn"
+	LabelSelectorOpExists       LabelSelectorOperator = "Exists"
+	LabelSelectorOpDoesNotExist LabelSelectorOperator = "DoesNotExist"
+)
+
+type LabelSelectorRequirement struct {
+	Key      string                `json:"key"`
+	Operator LabelSelectorOperator `json:"operator"`
+	Values   []string              `json:"values,omitempty"`
+}
+
+// A LabelSelector matches when every label in MatchLabels and every expression in MatchExpressions is satisfied
+type LabelSelector struct {
+	MatchLabels      map[string]string          `json:"matchLabels,omitempty"`
+	MatchExpressions []LabelSelectorRequirement `json:"matchExpressions,omitempty"`
+}
+
+// Matches reports whether the labels satisfy the selector. A nil selector matches nothing
+func (s *LabelSelector) Matches(labels map[string]string) bool {
+	if s == nil {
+		return false
+	}
+	for key, value := range s.MatchLabels {
+		if labels[key] != value {
+			return false
+		}
+	}
+	for _, req := range s.MatchExpressions {
+		value, exists := labels[req.Key]
+		switch req.Operator {
+		case LabelSelectorOpIn:
+			if !exists || !containsString(req.Values, value) {
+				return false
+			}
+		case LabelSelectorOpNotIn:
+			if exists && containsString(req.Values, value) {
+				return false
+			}
+		case LabelSelectorOpExists:
+			if !exists {
+				return false
+			}
+		case LabelSelectorOpDoesNotExist:
+			if exists {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package models
+
+// PodLogOptions select which container's log to read and how much of it. Previous reads the run before the
+// current one, which is where a crashed container's last output ends up
+type PodLogOptions struct {
+	Container    string
+	Follow       bool
+	TailLines    *int64
+	SinceSeconds *int64
+	Timestamps   bool
+	Previous     bool
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // Node status enum
 type NodeStatus string
 
@@ -8,8 +10,68 @@ const (
 	NodeNotReady NodeStatus = "NotReady"
 )
 
+// Node condition type enum
+type NodeConditionType string
+
+const (
+	NodeMemoryPressure NodeConditionType = "MemoryPressure"
+	NodeDiskPressure   NodeConditionType = "DiskPressure"
+)
+
+type NodeCondition struct {
+	Type               NodeConditionType `json:"type"`
+	Status             ConditionStatus   `json:"status"`
+	Reason             string            `json:"reason,omitempty"`
+	Message            string            `json:"message,omitempty"`
+	LastTransitionTime time.Time         `json:"lastTransitionTime"`
+}
+
 type Node struct {
-	Name    string     `json:"name"`
-	Address string     `json:"address"`
-	Status  NodeStatus `json:"status"`
+	Name    string            `json:"name"`
+	Address string            `json:"address"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Status  NodeStatus        `json:"status"`
+
+	// zero values are treated as unbounded so nodes registered without a capacity still accept pods
+	Capacity ResourceList `json:"capacity,omitempty"`
+
+	Taints []Taint `json:"taints,omitempty"`
+
+	// set by the node's kubelet
+	Conditions []NodeCondition `json:"conditions,omitempty"`
+	// largest first
+	Images []ContainerImage `json:"images,omitempty"`
+}
+
+// GetCondition returns the node's condition of the given type, or nil if it has none
+func (n *Node) GetCondition(conditionType NodeConditionType) *NodeCondition {
+	for i := range n.Conditions {
+		if n.Conditions[i].Type == conditionType {
+			return &n.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or replaces a condition, keeping the transition time unless the status changed. It reports
+// whether anything changed
+func (n *Node) SetCondition(condition NodeCondition) bool {
+	existing := n.GetCondition(condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = time.Now()
+		}
+		n.Conditions = append(n.Conditions, condition)
+		return true
+	}
+	if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return false
+	}
+	if existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	} else if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = time.Now()
+	}
+	*existing = condition
+	return true
 }
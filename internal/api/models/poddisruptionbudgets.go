@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// A PodDisruptionBudget limits how many of the pods matched by Selector may be voluntarily evicted at once. Exactly
+// one of MinAvailable and MaxUnavailable is set
+type PodDisruptionBudget struct {
+	Name           string         `json:"name"`
+	Namespace      string         `json:"namespace"`
+	Selector       *LabelSelector `json:"selector"`
+	MinAvailable   *int32         `json:"minAvailable,omitempty"`
+	MaxUnavailable *int32         `json:"maxUnavailable,omitempty"`
+
+	Status PodDisruptionBudgetStatus `json:"status"`
+}
+
+// PodDisruptionBudgetStatus is kept current by the disruption controller. DisruptedPods holds the pods the API
+// server has evicted that the controller may not have seen go away yet, so they are not counted as healthy twice
+type PodDisruptionBudgetStatus struct {
+	ExpectedPods       int32                `json:"expectedPods"`
+	CurrentHealthy     int32                `json:"currentHealthy"`
+	DesiredHealthy     int32                `json:"desiredHealthy"`
+	DisruptionsAllowed int32                `json:"disruptionsAllowed"`
+	DisruptedPods      map[string]time.Time `json:"disruptedPods,omitempty"`
+
+	// ObservedTime is when the controller listed the pods these figures were computed from, nil until the budget
+	// has been processed once
+	ObservedTime *time.Time `json:"observedTime,omitempty"`
+}
+
+// IsHealthy reports whether the pod counts towards the availability a disruption budget protects
+func (p *Pod) IsHealthy() bool {
+	return p.Phase == PodRunning && p.DeletionTimestamp == nil && p.IsReady()
+}
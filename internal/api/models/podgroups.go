@@ -0,0 +1,13 @@
+package models
+
+// pod groups wait this long for all their members to fit before their reservations are released
+const DefaultPodGroupScheduleTimeoutSeconds = 60
+
+// A PodGroup is a set of pods that must be scheduled together. Pods join a group through PodGroupName and none
+// of them is bound until at least MinMember of them fit at once
+type PodGroup struct {
+	Name                   string `json:"name"`
+	Namespace              string `json:"namespace"`
+	MinMember              int32  `json:"minMember"`
+	ScheduleTimeoutSeconds int32  `json:"scheduleTimeoutSeconds,omitempty"`
+}
@@ -12,13 +12,183 @@ const (
 	PodRunning     PodPhase = "Running"
 	PodTerminating PodPhase = "Terminating"
 	PodDeleted     PodPhase = "Deleted"
+
+	// every container has exited for good, Failed if any of them exited non-zero
+	PodSucceeded PodPhase = "Succeeded"
+	PodFailed    PodPhase = "Failed"
+)
+
+// Restart policy enum
+type RestartPolicy string
+
+const (
+	RestartPolicyAlways    RestartPolicy = "Always"
+	RestartPolicyOnFailure RestartPolicy = "OnFailure"
+	RestartPolicyNever     RestartPolicy = "Never"
+)
+
+// pods that do not name a scheduler are scheduled by the default profile
+const DefaultSchedulerName = "default-scheduler"
+
+// containers are given this long to stop, pre-stop hooks included, unless the pod says otherwise
+const DefaultTerminationGracePeriodSeconds int64 = 30
+
+// Annotations the kubelet puts on static pods, the pods it runs from manifest files on its own node
+const (
+	// AnnotationConfigSource says where the kubelet read the pod from, "file" for a static pod
+	AnnotationConfigSource = "kubernetes.io/config.source"
+	// AnnotationConfigHash identifies the version of the manifest a static pod was started from
+	AnnotationConfigHash = "kubernetes.io/config.hash"
+	// AnnotationConfigMirror marks the read-only copy of a static pod published to the API server
+	AnnotationConfigMirror = "kubernetes.io/config.mirror"
 )
 
+const ConfigSourceFile = "file"
+
 type Pod struct {
-	Name              string     `json:"name"`
-	Namespace         string     `json:"namespace"`
-	Image             string     `json:"image"`
-	NodeName          string     `json:"nodeName,omitempty"`
-	Phase             PodPhase   `json:"phase"`
-	DeletionTimestamp *time.Time `json:"deleteTime,omitempty"`
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	Labels            map[string]string `json:"labels,omitempty"`
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	Image             string            `json:"image"`
+	NodeName          string            `json:"nodeName,omitempty"`
+	SchedulerName     string            `json:"schedulerName,omitempty"`
+	Phase             PodPhase          `json:"phase"`
+	DeletionTimestamp *time.Time        `json:"deleteTime,omitempty"`
+
+	// Reason and Message explain why the kubelet failed a pod, as when it was Evicted
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	Resources ResourceRequirements `json:"resources,omitempty"`
+
+	// pods without containers are only tracked, the kubelet marks them running without starting anything
+	Containers    []Container   `json:"containers,omitempty"`
+	RestartPolicy RestartPolicy `json:"restartPolicy,omitempty"`
+	Volumes       []Volume      `json:"volumes,omitempty"`
+	// InitContainers run one at a time, each to completion, before any of the containers start. Those with
+	// restart policy Always are sidecars instead, see Container
+	InitContainers []Container `json:"initContainers,omitempty"`
+	// TerminationGracePeriodSeconds is set by the API server when left out
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// Priority is resolved from PriorityClassName by the API server when the pod is created
+	PriorityClassName string           `json:"priorityClassName,omitempty"`
+	Priority          int32            `json:"priority,omitempty"`
+	PreemptionPolicy  PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	NominatedNodeName string           `json:"nominatedNodeName,omitempty"`
+
+	TopologySpreadConstraints []TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	NodeSelector              map[string]string          `json:"nodeSelector,omitempty"`
+	NodeAffinity              *NodeAffinity              `json:"nodeAffinity,omitempty"`
+	Tolerations               []Toleration               `json:"tolerations,omitempty"`
+
+	PodGroupName string `json:"podGroupName,omitempty"`
+
+	Conditions []PodCondition `json:"conditions,omitempty"`
+	StartTime  *time.Time     `json:"startTime,omitempty"`
+	// set by the kubelet. Pods share their node's network, so both are the node's address
+	HostIP                string            `json:"hostIP,omitempty"`
+	PodIP                 string            `json:"podIP,omitempty"`
+	InitContainerStatuses []ContainerStatus `json:"initContainerStatuses,omitempty"`
+	ContainerStatuses     []ContainerStatus `json:"containerStatuses,omitempty"`
+}
+
+// Pod condition type enum
+type PodConditionType string
+
+const (
+	PodScheduledCondition PodConditionType = "PodScheduled"
+	PodInitialized        PodConditionType = "Initialized"
+	ContainersReady       PodConditionType = "ContainersReady"
+	PodReady              PodConditionType = "Ready"
+)
+
+// Condition status enum
+type ConditionStatus string
+
+const (
+	ConditionTrue    ConditionStatus = "True"
+	ConditionFalse   ConditionStatus = "False"
+	ConditionUnknown ConditionStatus = "Unknown"
+)
+
+type PodCondition struct {
+	Type               PodConditionType `json:"type"`
+	Status             ConditionStatus  `json:"status"`
+	Reason             string           `json:"reason,omitempty"`
+	Message            string           `json:"message,omitempty"`
+	LastTransitionTime time.Time        `json:"lastTransitionTime"`
+}
+
+// GetCondition returns the pod's condition of the given type, or nil if it has none
+func (p *Pod) GetCondition(conditionType PodConditionType) *PodCondition {
+	for i := range p.Conditions {
+		if p.Conditions[i].Type == conditionType {
+			return &p.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or replaces a condition, keeping the transition time unless the status changed. It reports
+// whether anything changed
+func (p *Pod) SetCondition(condition PodCondition) bool {
+	existing := p.GetCondition(condition.Type)
+	if existing == nil {
+		if condition.LastTransitionTime.IsZero() {
+			condition.LastTransitionTime = time.Now()
+		}
+		p.Conditions = append(p.Conditions, condition)
+		return true
+	}
+	if existing.Status == condition.Status && existing.Reason == condition.Reason && existing.Message == condition.Message {
+		return false
+	}
+	if existing.Status == condition.Status {
+		condition.LastTransitionTime = existing.LastTransitionTime
+	} else if condition.LastTransitionTime.IsZero() {
+		condition.LastTransitionTime = time.Now()
+	}
+	*existing = condition
+	return true
+}
+
+// IsTerminated reports whether the pod is done running, so it no longer holds anything on its node
+func (p *Pod) IsTerminated() bool {
+	return p.Phase == PodSucceeded || p.Phase == PodFailed || p.Phase == PodDeleted
+}
+
+// IsReady reports whether the kubelet has marked the pod Ready
+func (p *Pod) IsReady() bool {
+	condition := p.GetCondition(PodReady)
+	return condition != nil && condition.Status == ConditionTrue
+}
+
+// IsMirror reports whether the pod is the API server's copy of a static pod, which only its kubelet may change
+func (p *Pod) IsMirror() bool {
+	_, ok := p.Annotations[AnnotationConfigMirror]
+	return ok
+}
+
+// IsStatic reports whether the kubelet runs the pod from a manifest file rather than for the API server
+func (p *Pod) IsStatic() bool {
+	return p.Annotations[AnnotationConfigSource] == ConfigSourceFile
+}
+
+// Unsatisfiable constraint action enum
+type UnsatisfiableConstraintAction string
+
+const (
+	DoNotSchedule  UnsatisfiableConstraintAction = "DoNotSchedule"
+	ScheduleAnyway UnsatisfiableConstraintAction = "ScheduleAnyway"
+)
+
+// A TopologySpreadConstraint limits how unevenly the pods matched by LabelSelector may be spread across the
+// domains formed by nodes sharing a value for TopologyKey
+type TopologySpreadConstraint struct {
+	MaxSkew           int32                         `json:"maxSkew"`
+	TopologyKey       string                        `json:"topologyKey"`
+	WhenUnsatisfiable UnsatisfiableConstraintAction `json:"whenUnsatisfiable"`
+	LabelSelector     *LabelSelector                `json:"labelSelector,omitempty"`
 }
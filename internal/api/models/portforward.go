@@ -0,0 +1,13 @@
+package models
+
+// ForwardedPort maps a local port to a port of the pod. A zero Local picks any free port
+type ForwardedPort struct {
+	Local  uint16
+	Remote uint16
+}
+
+// PortForwardOptions list the ports to forward and the local address to listen on, the loopback address if empty
+type PortForwardOptions struct {
+	Address string
+	Ports   []ForwardedPort
+}
@@ -0,0 +1,18 @@
+package models
+
+// Preemption policy enum
+type PreemptionPolicy string
+
+const (
+	PreemptLowerPriority PreemptionPolicy = "PreemptLowerPriority"
+	PreemptNever         PreemptionPolicy = "Never"
+)
+
+// A PriorityClass maps a name to the integer priority given to pods that reference it
+type PriorityClass struct {
+	Name             string           `json:"name"`
+	Value            int32            `json:"value"`
+	GlobalDefault    bool             `json:"globalDefault,omitempty"`
+	PreemptionPolicy PreemptionPolicy `json:"preemptionPolicy,omitempty"`
+	Description      string           `json:"description,omitempty"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// CPU is measured in millicores and memory in bytes. Pods is only meaningful on a node's capacity
+type ResourceList struct {
+	CPU    int64 `json:"cpu,omitempty"`
+	Memory int64 `json:"memory,omitempty"`
+	Pods   int64 `json:"pods,omitempty"`
+}
+
+type ResourceRequirements struct {
+	Requests ResourceList `json:"requests,omitempty"`
+	Limits   ResourceList `json:"limits,omitempty"`
+}
+
+// Pod QoS class enum
+type PodQOSClass string
+
+const (
+	PodQOSGuaranteed PodQOSClass = "Guaranteed"
+	PodQOSBurstable  PodQOSClass = "Burstable"
+	PodQOSBestEffort PodQOSClass = "BestEffort"
+)
+
+// QOSClass is Guaranteed when the pod limits both CPU and memory and requests no less, BestEffort when it neither
+// requests nor limits either and Burstable otherwise. A request left unset counts as equal to its limit
+func (p *Pod) QOSClass() PodQOSClass {
+	requests, limits := p.Resources.Requests, p.Resources.Limits
+	if requests.CPU == 0 && requests.Memory == 0 && limits.CPU == 0 && limits.Memory == 0 {
+		return PodQOSBestEffort
+	}
+	guaranteed := func(request, limit int64) bool {
+		return limit > 0 && (request == 0 || request == limit)
+	}
+	if guaranteed(requests.CPU, limits.CPU) && guaranteed(requests.Memory, limits.Memory) {
+		return PodQOSGuaranteed
+	}
+	return PodQOSBurstable
+}
+
+// SchedulingRequests is what the pod takes up on its node, as the scheduler and descheduler account for it: its
+// requests plus one of the node's pod slots
+func (p *Pod) SchedulingRequests() ResourceList {
+	requests := p.Resources.Requests
+	requests.Pods = 1
+	return requests
+}
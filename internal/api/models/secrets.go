@@ -0,0 +1,16 @@
+package models
+
+// Secret type enum
+type SecretType string
+
+const SecretTypeOpaque SecretType = "Opaque"
+
+// A Secret holds sensitive data for pods to consume. Values are base64 encoded in JSON. StringData is a
+// write-only convenience for plain text values, merged into Data by the API server and taking precedence there
+type Secret struct {
+	Name       string            `json:"name"`
+	Namespace  string            `json:"namespace"`
+	Type       SecretType        `json:"type,omitempty"`
+	Data       map[string][]byte `json:"data,omitempty"`
+	StringData map[string]string `json:"stringData,omitempty"`
+}
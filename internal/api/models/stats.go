@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PodStats is a pod's resource usage as read by the kubelet from its cgroups. CPU usage is cumulative since the pod
+// started, so a rate takes two samples
+type PodStats struct {
+	Name                 string           `json:"name"`
+	Namespace            string           `json:"namespace"`
+	Time                 time.Time        `json:"time"`
+	CPUUsageMicroseconds uint64           `json:"cpuUsageMicroseconds"`
+	MemoryUsageBytes     int64            `json:"memoryUsageBytes"`
+	OOMKills             uint64           `json:"oomKills,omitempty"`
+	Containers           []ContainerStats `json:"containers,omitempty"`
+}
+
+type ContainerStats struct {
+	Name                 string `json:"name"`
+	CPUUsageMicroseconds uint64 `json:"cpuUsageMicroseconds"`
+	MemoryUsageBytes     int64  `json:"memoryUsageBytes"`
+	OOMKills             uint64 `json:"oomKills,omitempty"`
+}
@@ -0,0 +1,88 @@
+package models
+
+// Taint effect enum
+type TaintEffect string
+
+const (
+	TaintEffectNoSchedule       TaintEffect = "NoSchedule"
+	TaintEffectPreferNoSchedule TaintEffect = "PreferNoSchedule"
+	TaintEffectNoExecute        TaintEffect = "NoExecute"
+)
+
+// Taints the kubelet puts on its node while the node is under resource pressure
+const (
+	TaintNodeMemoryPressure = "node.kubernetes.io/memory-pressure"
+	TaintNodeDiskPressure   = "node.kubernetes.io/disk-pressure"
+)
+
+// A Taint repels pods from a node unless they carry a matching Toleration
+type Taint struct {
+	Key    string      `json:"key"`
+	Value  string      `json:"value,omitempty"`
+	Effect TaintEffect `json:"effect"`
+}
+
+// Toleration operator enum
+type TolerationOperator string
+
+const (
+	TolerationOpEqual  TolerationOperator = "Equal"
+	TolerationOpExists TolerationOperator = "Exists"
+)
+
+// An empty Key with the Exists operator tolerates every taint. An empty Effect matches every effect
+type Toleration struct {
+	Key      string             `json:"key,omitempty"`
+	Operator TolerationOperator `json:"operator,omitempty"`
+	Value    string             `json:"value,omitempty"`
+	Effect   TaintEffect        `json:"effect,omitempty"`
+}
+
+func (t Toleration) Tolerates(taint Taint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Key == "" {
+		return t.Operator == TolerationOpExists
+	}
+	if t.Key != taint.Key {
+		return false
+	}
+	if t.Operator == TolerationOpExists {
+		return true
+	}
+	return t.Value == taint.Value
+}
+
+// ToleratesTaint reports whether any of the pod's tolerations covers the taint
+func (p *Pod) ToleratesTaint(taint Taint) bool {
+	for _, toleration := range p.Tolerations {
+		if toleration.Tolerates(taint) {
+			return true
+		}
+	}
+	return false
+}
+
+// NodeAffinity restricts a pod to nodes whose labels match at least one of the Required terms
+type NodeAffinity struct {
+	Required []LabelSelector `json:"required,omitempty"`
+}
+
+// MatchesNodeLabels reports whether a node satisfies both the pod's nodeSelector and its required node affinity
+func (p *Pod) MatchesNodeLabels(nodeLabels map[string]string) bool {
+	for key, value := range p.NodeSelector {
+		if nodeLabels[key] != value {
+			return false
+		}
+	}
+	if p.NodeAffinity == nil || len(p.NodeAffinity.Required) == 0 {
+		return true
+	}
+	for i := range p.NodeAffinity.Required {
+		if p.NodeAffinity.Required[i].Matches(nodeLabels) {
+			return true
+		}
+	}
+	return false
+}
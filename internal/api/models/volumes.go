@@ -0,0 +1,99 @@
+package models
+
+// A Volume is a directory the kubelet prepares for a pod, shared by the containers that mount it. Exactly one of
+// its sources is set
+type Volume struct {
+	Name string `json:"name"`
+	VolumeSource
+}
+
+type VolumeSource struct {
+	EmptyDir  *EmptyDirVolumeSource  `json:"emptyDir,omitempty"`
+	HostPath  *HostPathVolumeSource  `json:"hostPath,omitempty"`
+	ConfigMap *ConfigMapVolumeSource `json:"configMap,omitempty"`
+	Secret    *SecretVolumeSource    `json:"secret,omitempty"`
+
+	DownwardAPI *DownwardAPIVolumeSource `json:"downwardAPI,omitempty"`
+}
+
+// Storage medium enum
+type StorageMedium string
+
+const (
+	StorageMediumDefault StorageMedium = ""
+	StorageMediumMemory  StorageMedium = "Memory"
+)
+
+// EmptyDirVolumeSource starts out empty and lives as long as the pod. With the Memory medium it is a tmpfs, whose
+// size SizeLimit caps in bytes
+type EmptyDirVolumeSource struct {
+	Medium    StorageMedium `json:"medium,omitempty"`
+	SizeLimit int64         `json:"sizeLimit,omitempty"`
+}
+
+// Host path type enum. The empty type mounts whatever is at the path without checking it
+type HostPathType string
+
+const (
+	HostPathUnset             HostPathType = ""
+	HostPathDirectoryOrCreate HostPathType = "DirectoryOrCreate"
+	HostPathDirectory         HostPathType = "Directory"
+	HostPathFileOrCreate      HostPathType = "FileOrCreate"
+	HostPathFile              HostPathType = "File"
+)
+
+// HostPathVolumeSource mounts a file or directory of the node. It is never cleaned up
+type HostPathVolumeSource struct {
+	Path string       `json:"path"`
+	Type HostPathType `json:"type,omitempty"`
+}
+
+// projected files are readable by everyone unless a mode says otherwise
+const DefaultProjectedFileMode int32 = 0o644
+
+// KeyToPath projects the value of Key to the file at Path, relative to the volume
+type KeyToPath struct {
+	Key  string `json:"key"`
+	Path string `json:"path"`
+	Mode *int32 `json:"mode,omitempty"`
+}
+
+// ConfigMapVolumeSource projects the keys of a config map into files named after them, or only those listed in
+// Items to the paths given there. A missing config map or key fails the pod unless Optional is set
+type ConfigMapVolumeSource struct {
+	Name        string      `json:"name"`
+	Items       []KeyToPath `json:"items,omitempty"`
+	DefaultMode *int32      `json:"defaultMode,omitempty"`
+	Optional    bool        `json:"optional,omitempty"`
+}
+
+// SecretVolumeSource projects a secret the same way ConfigMapVolumeSource does a config map
+type SecretVolumeSource struct {
+	SecretName  string      `json:"secretName"`
+	Items       []KeyToPath `json:"items,omitempty"`
+	DefaultMode *int32      `json:"defaultMode,omitempty"`
+	Optional    bool        `json:"optional,omitempty"`
+}
+
+// DownwardAPIVolumeSource writes fields of the pod and its resources into files, kept up to date as labels and
+// annotations change
+type DownwardAPIVolumeSource struct {
+	Items       []DownwardAPIVolumeFile `json:"items"`
+	DefaultMode *int32                  `json:"defaultMode,omitempty"`
+}
+
+// DownwardAPIVolumeFile is one file of a downward API volume. Exactly one of its references is set
+type DownwardAPIVolumeFile struct {
+	Path             string                 `json:"path"`
+	FieldRef         *ObjectFieldSelector   `json:"fieldRef,omitempty"`
+	ResourceFieldRef *ResourceFieldSelector `json:"resourceFieldRef,omitempty"`
+	Mode             *int32                 `json:"mode,omitempty"`
+}
+
+// A VolumeMount exposes a volume, or the SubPath within it, at MountPath. A relative MountPath is taken from the
+// container's working directory
+type VolumeMount struct {
+	Name      string `json:"name"`
+	MountPath string `json:"mountPath"`
+	SubPath   string `json:"subPath,omitempty"`
+}
@@ -0,0 +1,295 @@
+package stream
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+// Port-forward channels. Every payload starts with the four byte big endian id of the tunnel it belongs to. Open
+// is sent by the client with the two byte pod port after the id, Close means the sender will write no more to the
+// tunnel and Error tears it down with a message, as when the pod port refused the connection
+const (
+	ChannelPortOpen  byte = 5
+	ChannelPortData  byte = 6
+	ChannelPortClose byte = 7
+	ChannelPortError byte = 8
+)
+
+// incoming data queued for a slow tunnel beyond this holds up every tunnel on the connection
+const tunnelQueueSize = 64
+
+// Tunnels carries any number of TCP connections over one Conn. The client side calls Forward for each accepted
+// connection, the server side passes a dial function that opens the matching connection inside the pod
+type Tunnels struct {
+	conn *Conn
+	dial func(port uint16) (net.Conn, error)
+
+	mu      sync.Mutex
+	tunnels map[uint32]*tunnel
+	nextID  uint32
+	closed  bool
+
+	// OnError, if set, is called with errors reported for a tunnel
+	OnError func(port uint16, err error)
+}
+
+type tunnel struct {
+	id    uint32
+	port  uint16
+	queue chan []byte
+
+	// eof is closed when the peer has sent Close, dropped once the tunnel is gone
+	eof      chan struct{}
+	eofOnce  sync.Once
+	dropped  chan struct{}
+	dropOnce sync.Once
+
+	// each direction is done once its writer has closed, the tunnel is dropped once both are
+	mu         sync.Mutex
+	conn       net.Conn
+	readerDone bool
+	writerDone bool
+}
+
+func NewClientTunnels(conn *Conn) *Tunnels {
+	return &Tunnels{conn: conn, tunnels: make(map[uint32]*tunnel)}
+}
+
+func NewServerTunnels(conn *Conn, dial func(port uint16) (net.Conn, error)) *Tunnels {
+	return &Tunnels{conn: conn, dial: dial, tunnels: make(map[uint32]*tunnel)}
+}
+
+// Forward tunnels local to the given pod port. It returns straight away and closes local once the tunnel is done
+func (t *Tunnels) Forward(local net.Conn, port uint16) error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		local.Close()
+		return errors.New("port-forward connection is closed")
+	}
+	t.nextID++
+	tun := t.addLocked(t.nextID, port, local)
+	t.mu.Unlock()
+
+	payload := make([]byte, 6)
+	binary.BigEndian.PutUint32(payload, tun.id)
+	binary.BigEndian.PutUint16(payload[4:], port)
+	if err := t.conn.WriteFrame(ChannelPortOpen, payload); err != nil {
+		t.drop(tun)
+		return err
+	}
+	t.start(tun)
+	return nil
+}
+
+// Serve reads frames until the connection ends and then closes every tunnel left
+func (t *Tunnels) Serve() error {
+	defer t.closeAll()
+	for {
+		channel, payload, err := t.conn.ReadFrame()
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		if len(payload) < 4 {
+			continue
+		}
+		id := binary.BigEndian.Uint32(payload)
+		payload = payload[4:]
+
+		switch channel {
+		case ChannelPortOpen:
+			if t.dial == nil || len(payload) < 2 {
+				continue
+			}
+			t.open(id, binary.BigEndian.Uint16(payload))
+
+		case ChannelPortData:
+			if tun := t.get(id); tun != nil {
+				select {
+				case tun.queue <- payload:
+				case <-tun.dropped:
+				}
+			}
+
+		case ChannelPortClose:
+			if tun := t.get(id); tun != nil {
+				tun.eofOnce.Do(func() { close(tun.eof) })
+			}
+
+		case ChannelPortError:
+			if tun := t.get(id); tun != nil {
+				if t.OnError != nil {
+					t.OnError(tun.port, errors.New(string(payload)))
+				}
+				t.drop(tun)
+			}
+		}
+	}
+}
+
+// open dials the pod port for a tunnel the client asked for, reporting failure back on the error channel
+func (t *Tunnels) open(id uint32, port uint16) {
+	t.mu.Lock()
+	if _, exists := t.tunnels[id]; exists || t.closed {
+		t.mu.Unlock()
+		return
+	}
+	// registered before dialing so data sent right after the open is queued rather than lost
+	tun := t.addLocked(id, port, nil)
+	t.mu.Unlock()
+
+	go func() {
+		conn, err := t.dial(port)
+		if err != nil {
+			t.sendError(id, fmt.Sprintf("error forwarding port %d: %v", port, err))
+			t.drop(tun)
+			return
+		}
+		tun.mu.Lock()
+		tun.conn = conn
+		tun.mu.Unlock()
+		select {
+		case <-tun.dropped:
+			conn.Close()
+			return
+		default:
+		}
+		t.start(tun)
+	}()
+}
+
+func (t *Tunnels) addLocked(id uint32, port uint16, conn net.Conn) *tunnel {
+	tun := &tunnel{
+		id:      id,
+		port:    port,
+		conn:    conn,
+		queue:   make(chan []byte, tunnelQueueSize),
+		eof:     make(chan struct{}),
+		dropped: make(chan struct{}),
+	}
+	t.tunnels[id] = tun
+	return tun
+}
+
+func (t *Tunnels) get(id uint32) *tunnel {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.tunnels[id]
+}
+
+// start copies the tunnel's connection both ways once it is connected
+func (t *Tunnels) start(tun *tunnel) {
+	go func() {
+		buf := make([]byte, 32*1024)
+		header := make([]byte, 4)
+		binary.BigEndian.PutUint32(header, tun.id)
+		for {
+			n, err := tun.conn.Read(buf)
+			if n > 0 {
+				if writeErr := t.conn.WriteFrame(ChannelPortData, append(header, buf[:n]...)); writeErr != nil {
+					break
+				}
+			}
+			if err != nil {
+				break
+			}
+		}
+		t.conn.WriteFrame(ChannelPortClose, header)
+		t.finish(tun, true)
+	}()
+
+	go func() {
+		if !tun.writeQueued() {
+			// the peer may still be sending, so it has to be told the tunnel is gone
+			t.sendError(tun.id, fmt.Sprintf("error writing to port %d connection", tun.port))
+			t.drop(tun)
+			return
+		}
+		if cw, ok := tun.conn.(interface{ CloseWrite() error }); ok {
+			cw.CloseWrite()
+		}
+		t.finish(tun, false)
+	}()
+}
+
+// writeQueued passes on data from the peer until it sends Close, reporting false if the tunnel broke first
+func (tun *tunnel) writeQueued() bool {
+	for {
+		select {
+		case data := <-tun.queue:
+			if _, err := tun.conn.Write(data); err != nil {
+				return false
+			}
+		case <-tun.eof:
+			// everything sent before the Close was queued before it
+			for {
+				select {
+				case data := <-tun.queue:
+					if _, err := tun.conn.Write(data); err != nil {
+						return false
+					}
+				default:
+					return true
+				}
+			}
+		case <-tun.dropped:
+			return false
+		}
+	}
+}
+
+func (t *Tunnels) finish(tun *tunnel, reader bool) {
+	tun.mu.Lock()
+	if reader {
+		tun.readerDone = true
+	} else {
+		tun.writerDone = true
+	}
+	done := tun.readerDone && tun.writerDone
+	tun.mu.Unlock()
+	if done {
+		t.drop(tun)
+	}
+}
+
+// drop forgets a tunnel and closes its connection, which also ends whichever copy is still running
+func (t *Tunnels) drop(tun *tunnel) {
+	t.mu.Lock()
+	if t.tunnels[tun.id] == tun {
+		delete(t.tunnels, tun.id)
+	}
+	t.mu.Unlock()
+
+	tun.dropOnce.Do(func() { close(tun.dropped) })
+	tun.mu.Lock()
+	if tun.conn != nil {
+		tun.conn.Close()
+	}
+	tun.mu.Unlock()
+}
+
+func (t *Tunnels) sendError(id uint32, message string) {
+	payload := make([]byte, 4, 4+len(message))
+	binary.BigEndian.PutUint32(payload, id)
+	t.conn.WriteFrame(ChannelPortError, append(payload, message...))
+}
+
+func (t *Tunnels) closeAll() {
+	t.mu.Lock()
+	t.closed = true
+	tunnels := make([]*tunnel, 0, len(t.tunnels))
+	for _, tun := range t.tunnels {
+		tunnels = append(tunnels, tun)
+	}
+	t.mu.Unlock()
+	for _, tun := range tunnels {
+		t.drop(tun)
+	}
+}
@@ -0,0 +1,211 @@
+// Package stream carries several byte streams over a single connection that was upgraded from HTTP. Each frame
+// is a one byte channel id, a four byte big endian length and that many bytes of payload. It is used for exec,
+// attach and port-forward, both between clients and the API server and between the API server and kubelets,
+// which lets the API server proxy the upgraded connection without looking inside it
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Protocol is the value of the Upgrade header both sides agree on
+const Protocol = "k8s-lite-stream.v1"
+
+// frames larger than this are split when written and refused when read
+const MaxFrameSize = 1 << 20
+
+// Exec and attach channels. An empty stdin frame means stdin was closed. The error channel carries a single
+// Status once the command has finished, and the resize channel carries TerminalSize updates
+const (
+	ChannelStdin  byte = 0
+	ChannelStdout byte = 1
+	ChannelStderr byte = 2
+	ChannelError  byte = 3
+	ChannelResize byte = 4
+)
+
+// Status ends an exec or attach session
+type Status struct {
+	ExitCode int32  `json:"exitCode"`
+	Message  string `json:"message,omitempty"`
+}
+
+type TerminalSize struct {
+	Width  uint16 `json:"width"`
+	Height uint16 `json:"height"`
+}
+
+// Conn reads and writes frames. Writes may come from several goroutines, reads from one
+type Conn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	wmu    sync.Mutex
+}
+
+func newConn(conn net.Conn, reader *bufio.Reader) *Conn {
+	return &Conn{conn: conn, reader: reader}
+}
+
+func (c *Conn) WriteFrame(channel byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	for {
+		chunk := payload
+		if len(chunk) > MaxFrameSize {
+			chunk = chunk[:MaxFrameSize]
+		}
+		var header [5]byte
+		header[0] = channel
+		binary.BigEndian.PutUint32(header[1:], uint32(len(chunk)))
+		if _, err := c.conn.Write(append(header[:], chunk...)); err != nil {
+			return err
+		}
+		payload = payload[len(chunk):]
+		if len(payload) == 0 {
+			return nil
+		}
+	}
+}
+
+func (c *Conn) ReadFrame() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFrameSize {
+		return 0, nil, fmt.Errorf("frame of %d bytes exceeds the limit of %d", size, MaxFrameSize)
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// Writer returns a writer that sends everything written to it on the channel
+func (c *Conn) Writer(channel byte) io.Writer {
+	return channelWriter{conn: c, channel: channel}
+}
+
+func (c *Conn) Close() error {
+	return c.conn.Close()
+}
+
+// Raw exposes the underlying connection and its buffered reader for proxies that pass frames through untouched
+func (c *Conn) Raw() (net.Conn, *bufio.Reader) {
+	return c.conn, c.reader
+}
+
+type channelWriter struct {
+	conn    *Conn
+	channel byte
+}
+
+func (w channelWriter) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if err := w.conn.WriteFrame(w.channel, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// IsUpgradeRequest reports whether the request asks for this protocol
+func IsUpgradeRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), Protocol) &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// Upgrade takes over the connection of a request made with Dial and answers it with 101 Switching Protocols.
+// Nothing may have been written to w before
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if !IsUpgradeRequest(r) {
+		return nil, fmt.Errorf("request does not ask to upgrade to %s", Protocol)
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, errors.New("connection cannot be taken over")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, fmt.Errorf("error taking over connection: %w", err)
+	}
+
+	response := "HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: " + Protocol + "\r\n\r\n"
+	if _, err := conn.Write([]byte(response)); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error answering upgrade: %w", err)
+	}
+	return newConn(conn, rw.Reader), nil
+}
+
+// UpgradeError is returned by Dial when the server answered with something other than 101. Body holds what it
+// said instead
+type UpgradeError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *UpgradeError) Error() string {
+	return fmt.Sprintf("upgrade refused with status code %d: %s", e.StatusCode, strings.TrimSpace(e.Body))
+}
+
+// Dial sends a request asking to upgrade to this protocol and returns the connection once the server agrees
+func Dial(ctx context.Context, method, rawURL string) (*Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing URL: %w", err)
+	}
+	if u.Scheme != "http" {
+		return nil, fmt.Errorf("unsupported scheme %s", u.Scheme)
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "80")
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", host, err)
+	}
+
+	req, err := http.NewRequest(method, rawURL, nil)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", Protocol)
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error sending upgrade request: %w", err)
+	}
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error reading upgrade response: %w", err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		resp.Body.Close()
+		conn.Close()
+		return nil, &UpgradeError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return newConn(conn, reader), nil
+}
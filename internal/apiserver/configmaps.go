@@ -0,0 +1,207 @@
+package apiserver
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+	"github.com/joshL1215/k8s-lite/internal/store/encryption"
+)
+
+// keys become file names in projected volumes, so they are held to the characters upstream allows
+var configKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+const maxConfigKeyLength = 253
+
+func (s *APIServer) createConfigMapHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var cm models.ConfigMap
+	if err := c.ShouldBindJSON(&cm); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if cm.Name == "" {
+		c.JSON(400, gin.H{"error": "A config map name must be provided"})
+		return
+	}
+	cm.Namespace = namespace
+	if err := validateConfigMap(&cm); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid config map", "detail": err.Error()})
+		return
+	}
+
+	if err := s.store.CreateConfigMap(&cm); err != nil {
+		log.Printf("Error creating config map %s/%s: %v", cm.Namespace, cm.Name, err)
+		if errors.Is(err, store.ErrConfigMapExists) {
+			c.JSON(409, gin.H{"error": "Failed to create config map", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create config map", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Created config map %s/%s successfully", cm.Namespace, cm.Name)
+
+	s.watchManager.Publish(objectScope(namespace, "configmap"), models.WatchEvent{
+		EventType:   models.AddEvent,
+		EventObject: "configmap",
+		ConfigMap:   &cm,
+	})
+
+	c.JSON(201, cm)
+}
+
+func (s *APIServer) getConfigMapHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	cm, err := s.store.GetConfigMap(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Config map not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, cm)
+}
+
+func (s *APIServer) listConfigMapsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if c.Query("watch") == "true" {
+		s.serveWatch(c, objectScope(namespace, "configmap"))
+		return
+	}
+
+	cmList, err := s.store.ListConfigMaps(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch config map list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, cmList)
+}
+
+// listAllConfigMapsHandler lists the config maps of every namespace
+func (s *APIServer) listAllConfigMapsHandler(c *gin.Context) {
+	cmList, err := s.store.ListConfigMaps("")
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch config map list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, cmList)
+}
+
+func (s *APIServer) updateConfigMapHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var cm models.ConfigMap
+	if err := c.ShouldBindJSON(&cm); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+	if cm.Name != name || cm.Namespace != namespace {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Config map in body does not match %s/%s", namespace, name)})
+		return
+	}
+	if err := validateConfigMap(&cm); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid config map", "detail": err.Error()})
+		return
+	}
+
+	if err := s.store.UpdateConfigMap(&cm); err != nil {
+		log.Printf("Error updating config map %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrConfigMapNotExist) {
+			c.JSON(404, gin.H{"error": "Config map does not exist", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to update config map", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Updated config map %s/%s", namespace, name)
+
+	s.watchManager.Publish(objectScope(namespace, "configmap"), models.WatchEvent{
+		EventType:   models.ModificationEvent,
+		EventObject: "configmap",
+		ConfigMap:   &cm,
+	})
+
+	c.JSON(200, cm)
+}
+
+func (s *APIServer) deleteConfigMapHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if err := s.store.DeleteConfigMap(namespace, name); err != nil {
+		log.Printf("Error deleting config map %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrConfigMapNotExist) {
+			c.JSON(404, gin.H{"error": "Config map not found for deletion", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Unable to delete config map", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Config map %s/%s successfully deleted", namespace, name)
+
+	s.watchManager.Publish(objectScope(namespace, "configmap"), models.WatchEvent{
+		EventType:   models.DeletionEvent,
+		EventObject: "configmap",
+		ConfigMap:   &models.ConfigMap{Name: name, Namespace: namespace},
+	})
+
+	c.JSON(200, gin.H{"message": fmt.Sprintf("Config map %s/%s successfully deleted", namespace, name)})
+}
+
+func validateConfigMap(cm *models.ConfigMap) error {
+	size := 0
+	for key, value := range cm.Data {
+		if err := validateConfigKey(key); err != nil {
+			return err
+		}
+		if _, ok := cm.BinaryData[key]; ok {
+			return fmt.Errorf("key %s is in both data and binaryData", key)
+		}
+		if err := validateConfigValue(key, []byte(value)); err != nil {
+			return err
+		}
+		size += len(value)
+	}
+	for key, value := range cm.BinaryData {
+		if err := validateConfigKey(key); err != nil {
+			return err
+		}
+		if err := validateConfigValue(key, value); err != nil {
+			return err
+		}
+		size += len(value)
+	}
+	if size > models.MaxConfigDataSize {
+		return fmt.Errorf("data is %d bytes, more than the limit of %d", size, models.MaxConfigDataSize)
+	}
+	return nil
+}
+
+// validateConfigValue rejects values that would be taken for encrypted ones once read back from the store
+func validateConfigValue(key string, value []byte) error {
+	if bytes.HasPrefix(value, []byte(encryption.EncryptedPrefix)) {
+		return fmt.Errorf("value of key %s starts with %s, which is reserved for encrypted values", key, encryption.EncryptedPrefix)
+	}
+	return nil
+}
+
+// validateConfigKey rejects keys that could not be used as file names in a volume. Names starting with .. are
+// reserved for the kubelet's own links in projected volumes
+func validateConfigKey(key string) error {
+	if len(key) > maxConfigKeyLength {
+		return fmt.Errorf("key %.20s... is longer than %d characters", key, maxConfigKeyLength)
+	}
+	if !configKeyPattern.MatchString(key) {
+		return fmt.Errorf("key %q must consist of alphanumeric characters, '-', '_' or '.'", key)
+	}
+	if key == "." || strings.HasPrefix(key, "..") {
+		return fmt.Errorf("key %q must not be '.' or start with '..'", key)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package apiserver
+
+import (
+	"errors"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *APIServer) createEventHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var event models.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if event.Name == "" {
+		c.JSON(400, gin.H{"error": "An event name must be provided"})
+		return
+	}
+	if event.InvolvedObject.Name == "" || event.InvolvedObject.Kind == "" {
+		c.JSON(400, gin.H{"error": "An event must reference the object it involves"})
+		return
+	}
+	event.Namespace = namespace
+	if event.Type == "" {
+		event.Type = models.EventNormal
+	}
+	if event.Count == 0 {
+		event.Count = 1
+	}
+	now := time.Now()
+	if event.FirstTimestamp.IsZero() {
+		event.FirstTimestamp = now
+	}
+	if event.LastTimestamp.IsZero() {
+		event.LastTimestamp = now
+	}
+
+	if err := s.store.CreateEvent(&event); err != nil {
+		if errors.Is(err, store.ErrEventExists) {
+			c.JSON(409, gin.H{"error": "Failed to create event", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create event", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Recorded event %s/%s: %s %s", event.Namespace, event.Name, event.Reason, event.Message)
+	c.JSON(201, event)
+}
+
+func (s *APIServer) getEventHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	event, err := s.store.GetEvent(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Event not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, event)
+}
+
+func (s *APIServer) updateEventHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	var event models.Event
+	if err := c.ShouldBindJSON(&event); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+	if event.Name != name || event.Namespace != namespace {
+		c.JSON(400, gin.H{"error": "Event name and namespace must match the request path"})
+		return
+	}
+
+	if err := s.store.UpdateEvent(&event); err != nil {
+		if errors.Is(err, store.ErrEventNotExist) {
+			c.JSON(404, gin.H{"error": "Event does not exist", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to update event", "detail": err.Error()})
+		}
+		return
+	}
+	c.JSON(200, event)
+}
+
+// listEventsHandler supports ?kind= and ?name= to narrow the list to the events of one object, newest first
+func (s *APIServer) listEventsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	kind := c.Query("kind")
+	name := c.Query("name")
+
+	eventList, err := s.store.ListEvents(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch event list", "detail": err.Error()})
+		return
+	}
+
+	filtered := make([]*models.Event, 0, len(eventList))
+	for _, event := range eventList {
+		if kind != "" && event.InvolvedObject.Kind != kind {
+			continue
+		}
+		if name != "" && event.InvolvedObject.Name != name {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	sort.Slice(filtered, func(i, j int) bool {
+		return filtered[i].LastTimestamp.After(filtered[j].LastTimestamp)
+	})
+	c.JSON(200, filtered)
+}
+
+// deletePodEvents removes the events of a pod once it is gone, so they neither pile up nor carry over to a pod
+// created later under the same name
+func (s *APIServer) deletePodEvents(namespace, name string) {
+	eventList, err := s.store.ListEvents(namespace)
+	if err != nil {
+		log.Printf("Error listing events of pod %s/%s: %v", namespace, name, err)
+		return
+	}
+	for _, event := range eventList {
+		if event.InvolvedObject.Kind != "Pod" || event.InvolvedObject.Name != name {
+			continue
+		}
+		if err := s.store.DeleteEvent(namespace, event.Name); err != nil && !errors.Is(err, store.ErrEventNotExist) {
+			log.Printf("Error deleting event %s/%s: %v", namespace, event.Name, err)
+		}
+	}
+}
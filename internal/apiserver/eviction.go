@@ -0,0 +1,107 @@
+package apiserver
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// evictPodHandler deletes a pod only if every disruption budget covering it can spare one more disruption. A pod
+// that is not healthy does not count towards any budget and may always be evicted
+func (s *APIServer) evictPodHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("podname")
+
+	// budgets are checked and charged as one step so two evictions cannot both spend the last disruption
+	s.evictionMutex.Lock()
+	defer s.evictionMutex.Unlock()
+
+	pod, err := s.store.GetPod(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod not found for eviction", "detail": err.Error()})
+		return
+	}
+	if pod.DeletionTimestamp != nil {
+		c.JSON(200, gin.H{"message": fmt.Sprintf("Pod %s/%s is already being deleted", namespace, name)})
+		return
+	}
+
+	pdbList, err := s.store.ListPodDisruptionBudgets(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch pod disruption budgets", "detail": err.Error()})
+		return
+	}
+	var covering []*models.PodDisruptionBudget
+	for _, pdb := range pdbList {
+		if pdb.Selector.Matches(pod.Labels) {
+			covering = append(covering, pdb)
+		}
+	}
+
+	// budgets as they were before this eviction charged them, put back should it not go through
+	var charged []*models.PodDisruptionBudget
+	if pod.IsHealthy() {
+		for _, pdb := range covering {
+			if pdb.Status.ObservedTime == nil {
+				c.Header("Retry-After", "10")
+				c.JSON(429, gin.H{"error": fmt.Sprintf("Cannot evict pod as the disruption budget %s has not been processed yet", pdb.Name)})
+				return
+			}
+			if pdb.Status.DisruptionsAllowed <= 0 {
+				c.Header("Retry-After", "10")
+				c.JSON(429, gin.H{"error": fmt.Sprintf("Cannot evict pod as it would violate the pod's disruption budget %s: needs %d healthy pods and has %d",
+					pdb.Name, pdb.Status.DesiredHealthy, pdb.Status.CurrentHealthy)})
+				return
+			}
+		}
+
+		now := time.Now()
+		for _, pdb := range covering {
+			updated := *pdb
+			updated.Status.DisruptionsAllowed--
+			updated.Status.DisruptedPods = make(map[string]time.Time, len(pdb.Status.DisruptedPods)+1)
+			for podName, evictedAt := range pdb.Status.DisruptedPods {
+				updated.Status.DisruptedPods[podName] = evictedAt
+			}
+			updated.Status.DisruptedPods[pod.Name] = now
+			if err := s.store.UpdatePodDisruptionBudget(&updated); err != nil {
+				s.refundDisruptionBudgets(charged)
+				c.JSON(500, gin.H{"error": "Failed to charge pod disruption budget", "detail": err.Error()})
+				return
+			}
+			charged = append(charged, pdb)
+		}
+	}
+
+	if err := s.store.DeletePod(namespace, name); err != nil {
+		log.Printf("Error evicting pod %s/%s: %v", namespace, name, err)
+		s.refundDisruptionBudgets(charged)
+		c.JSON(500, gin.H{"error": "Failed to evict pod", "detail": err.Error()})
+		return
+	}
+	log.Printf("Pod %s/%s evicted and set for deletion", namespace, name)
+
+	s.watchManager.Publish(namespace, models.WatchEvent{
+		EventType:   models.DeletionEvent,
+		EventObject: "pod",
+		Pod: &models.Pod{
+			Name:      name,
+			Namespace: namespace,
+		},
+	})
+
+	c.JSON(201, gin.H{"message": fmt.Sprintf("Pod %s/%s evicted", namespace, name)})
+}
+
+// refundDisruptionBudgets restores budgets charged for an eviction that did not go through. Every budget write
+// holds the eviction mutex, so nothing can have changed them since they were charged
+func (s *APIServer) refundDisruptionBudgets(budgets []*models.PodDisruptionBudget) {
+	for _, pdb := range budgets {
+		if err := s.store.UpdatePodDisruptionBudget(pdb); err != nil {
+			log.Printf("Error refunding pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+)
+
+// kubelet requests can stream for as long as the caller keeps them open, so they are made without a timeout
+var kubeletClient = &http.Client{}
+
+// podTarget looks up the pod and the node it is bound to. It answers the request itself and returns false when
+// that fails
+func (s *APIServer) podTarget(c *gin.Context) (*models.Pod, *models.Node, bool) {
+	namespace := c.Param("namespace")
+	name := c.Param("podname")
+
+	pod, err := s.store.GetPod(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod not found", "detail": err.Error()})
+		return nil, nil, false
+	}
+	if pod.NodeName == "" {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Pod %s/%s is not bound to a node", namespace, name)})
+		return nil, nil, false
+	}
+	node, err := s.store.GetNode(pod.NodeName)
+	if err != nil {
+		c.JSON(500, gin.H{"error": fmt.Sprintf("Node %s of pod %s/%s not found", pod.NodeName, namespace, name), "detail": err.Error()})
+		return nil, nil, false
+	}
+	return pod, node, true
+}
+
+// podContainerTarget is podTarget that also works out which of the pod's containers a request is for, defaulting
+// to the only one. Init containers can be named too
+func (s *APIServer) podContainerTarget(c *gin.Context) (*models.Pod, *models.Node, string, bool) {
+	pod, node, ok := s.podTarget(c)
+	if !ok {
+		return nil, nil, "", false
+	}
+	namespace, name := pod.Namespace, pod.Name
+
+	container := c.Query("container")
+	if container == "" {
+		if len(pod.Containers) != 1 {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("A container name must be specified for pod %s/%s", namespace, name)})
+			return nil, nil, "", false
+		}
+		container = pod.Containers[0].Name
+	}
+	found := false
+	for _, spec := range append(slices.Clone(pod.InitContainers), pod.Containers...) {
+		found = found || spec.Name == container
+	}
+	if !found {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s is not valid for pod %s/%s", container, namespace, name)})
+		return nil, nil, "", false
+	}
+	return pod, node, container, true
+}
+
+// kubeletURL builds the URL of a kubelet route, passing on the caller's query minus the container selection
+func kubeletURL(node *models.Node, query url.Values, segments ...string) string {
+	for i := range segments {
+		segments[i] = url.PathEscape(segments[i])
+	}
+	forwarded := url.Values{}
+	for key, values := range query {
+		if key != "container" {
+			forwarded[key] = values
+		}
+	}
+
+	target := strings.TrimSuffix(node.Address, "/") + "/" + strings.Join(segments, "/")
+	if len(forwarded) > 0 {
+		target += "?" + forwarded.Encode()
+	}
+	return target
+}
+
+// podLogsHandler proxies to the logs endpoint of the kubelet running the pod, streaming the response back as it
+// arrives so that followed logs keep flowing
+func (s *APIServer) podLogsHandler(c *gin.Context) {
+	pod, node, container, ok := s.podContainerTarget(c)
+	if !ok {
+		return
+	}
+	target := kubeletURL(node, c.Request.URL.Query(), "containerLogs", pod.Namespace, pod.Name, container)
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", target, nil)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to build kubelet request", "detail": err.Error()})
+		return
+	}
+	resp, err := kubeletClient.Do(req)
+	if err != nil {
+		c.JSON(502, gin.H{"error": fmt.Sprintf("Failed to reach kubelet on node %s", node.Name), "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	c.Header("Content-Type", resp.Header.Get("Content-Type"))
+	c.Status(resp.StatusCode)
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := c.Writer.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+		if err != nil {
+			if err != io.EOF && c.Request.Context().Err() == nil {
+				log.Printf("Error proxying logs of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+			}
+			return
+		}
+	}
+}
+
+// podExecHandler proxies to the exec endpoint of the kubelet running the pod
+func (s *APIServer) podExecHandler(c *gin.Context) {
+	s.proxyContainerStream(c, "exec")
+}
+
+// podAttachHandler proxies to the attach endpoint of the kubelet running the pod
+func (s *APIServer) podAttachHandler(c *gin.Context) {
+	s.proxyContainerStream(c, "attach")
+}
+
+// podPortForwardHandler proxies to the port-forward endpoint of the kubelet running the pod, which tunnels every
+// port listed in the ports query parameter over the one connection
+func (s *APIServer) podPortForwardHandler(c *gin.Context) {
+	if !stream.IsUpgradeRequest(c.Request) {
+		c.JSON(400, gin.H{"error": "The port-forward request must upgrade the connection to " + stream.Protocol})
+		return
+	}
+	pod, node, ok := s.podTarget(c)
+	if !ok {
+		return
+	}
+	s.proxyStream(c, pod, node, "port-forward", "portForward", pod.Namespace, pod.Name)
+}
+
+func (s *APIServer) proxyContainerStream(c *gin.Context, route string) {
+	if !stream.IsUpgradeRequest(c.Request) {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("The %s request must upgrade the connection to %s", route, stream.Protocol)})
+		return
+	}
+	pod, node, container, ok := s.podContainerTarget(c)
+	if !ok {
+		return
+	}
+	s.proxyStream(c, pod, node, route, route, pod.Namespace, pod.Name, container)
+}
+
+// proxyStream upgrades the connection to the kubelet first, so that anything it refuses is answered as a normal
+// HTTP error, then upgrades the caller's and passes frames between the two untouched until either side closes
+func (s *APIServer) proxyStream(c *gin.Context, pod *models.Pod, node *models.Node, route string, segments ...string) {
+	target := kubeletURL(node, c.Request.URL.Query(), segments...)
+
+	backend, err := stream.Dial(c.Request.Context(), "POST", target)
+	if err != nil {
+		var upgradeErr *stream.UpgradeError
+		if errors.As(err, &upgradeErr) {
+			c.Data(upgradeErr.StatusCode, "application/json; charset=utf-8", []byte(upgradeErr.Body))
+			return
+		}
+		c.JSON(502, gin.H{"error": fmt.Sprintf("Failed to reach kubelet on node %s", node.Name), "detail": err.Error()})
+		return
+	}
+	defer backend.Close()
+
+	client, err := stream.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		log.Printf("Error upgrading %s connection for pod %s/%s: %v", route, pod.Namespace, pod.Name, err)
+		return
+	}
+	defer client.Close()
+
+	clientConn, clientReader := client.Raw()
+	backendConn, backendReader := backend.Raw()
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(backendConn, clientReader)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(clientConn, backendReader)
+		done <- struct{}{}
+	}()
+	// whichever side closes first ends the session for both
+	<-done
+}
+
+// podStatsHandler proxies to the stats endpoint of the kubelet running the pod
+func (s *APIServer) podStatsHandler(c *gin.Context) {
+	pod, node, ok := s.podTarget(c)
+	if !ok {
+		return
+	}
+	target := kubeletURL(node, nil, "stats", pod.Namespace, pod.Name)
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", target, nil)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Failed to build kubelet request", "detail": err.Error()})
+		return
+	}
+	resp, err := kubeletClient.Do(req)
+	if err != nil {
+		c.JSON(502, gin.H{"error": fmt.Sprintf("Failed to reach kubelet on node %s", node.Name), "detail": err.Error()})
+		return
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(502, gin.H{"error": fmt.Sprintf("Failed to read response of kubelet on node %s", node.Name), "detail": err.Error()})
+		return
+	}
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), body)
+}
@@ -36,6 +36,13 @@ func (s *APIServer) createNodeHandler(c *gin.Context) {
 		return
 	}
 	log.Printf("Created node %s successfully", node.Name)
+
+	s.watchManager.Publish(clusterScope, models.WatchEvent{
+		EventType:   models.AddEvent,
+		EventObject: "node",
+		Node:        &node,
+	})
+
 	c.JSON(201, node)
 }
 
@@ -66,6 +73,7 @@ func (s *APIServer) updateNodeHandler(c *gin.Context) {
 		} else {
 			c.JSON(500, gin.H{"error": "Failed to find pod", "detail": err.Error()})
 		}
+		return
 	}
 
 	if err := s.store.UpdateNode(&node); err != nil {
@@ -74,6 +82,12 @@ func (s *APIServer) updateNodeHandler(c *gin.Context) {
 		return
 	}
 
+	s.watchManager.Publish(clusterScope, models.WatchEvent{
+		EventType:   models.ModificationEvent,
+		EventObject: "node",
+		Node:        &node,
+	})
+
 	c.JSON(200, node)
 }
 
@@ -91,13 +105,25 @@ func (s *APIServer) deleteNodeHandler(c *gin.Context) {
 	}
 
 	log.Printf("Node %s successfully deleted", name)
+
+	s.watchManager.Publish(clusterScope, models.WatchEvent{
+		EventType:   models.DeletionEvent,
+		EventObject: "node",
+		Node:        &models.Node{Name: name},
+	})
 	c.JSON(200, gin.H{"message": fmt.Sprintf("Node %s successfully deleted", name)})
 }
 
 func (s *APIServer) listNodesHandler(c *gin.Context) {
+	if c.Query("watch") == "true" {
+		s.serveWatch(c, clusterScope)
+		return
+	}
+
 	nodeList, err := s.store.ListNodes()
 	if err != nil {
 		c.JSON(500, gin.H{"error": "Unable to list nodes", "detail": err.Error()})
+		return
 	}
 
 	c.JSON(200, nodeList)
@@ -0,0 +1,181 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *APIServer) createPodDisruptionBudgetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var pdb models.PodDisruptionBudget
+	if err := c.ShouldBindJSON(&pdb); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if pdb.Name == "" {
+		c.JSON(400, gin.H{"error": "A pod disruption budget name must be provided"})
+		return
+	}
+	if err := validatePodDisruptionBudget(&pdb); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid pod disruption budget", "detail": err.Error()})
+		return
+	}
+	pdb.Namespace = namespace
+	// nothing may be evicted until the controller has worked out the budget's status
+	pdb.Status = models.PodDisruptionBudgetStatus{}
+
+	if err := s.store.CreatePodDisruptionBudget(&pdb); err != nil {
+		log.Printf("Error creating pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+		if errors.Is(err, store.ErrPodDisruptionBudgetExists) {
+			c.JSON(409, gin.H{"error": "Failed to create pod disruption budget", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create pod disruption budget", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Created pod disruption budget %s/%s", pdb.Namespace, pdb.Name)
+	c.JSON(201, pdb)
+}
+
+func (s *APIServer) getPodDisruptionBudgetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	pdb, err := s.store.GetPodDisruptionBudget(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod disruption budget not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pdb)
+}
+
+// updatePodDisruptionBudgetHandler replaces the budget's spec. Its status is only written through the status route
+func (s *APIServer) updatePodDisruptionBudgetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var pdb models.PodDisruptionBudget
+	if err := c.ShouldBindJSON(&pdb); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+	if pdb.Name != name || pdb.Namespace != namespace {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Pod disruption budget in body does not match %s/%s", namespace, name)})
+		return
+	}
+	if err := validatePodDisruptionBudget(&pdb); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid pod disruption budget", "detail": err.Error()})
+		return
+	}
+
+	s.evictionMutex.Lock()
+	defer s.evictionMutex.Unlock()
+
+	existing, err := s.store.GetPodDisruptionBudget(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod disruption budget does not exist", "detail": err.Error()})
+		return
+	}
+	pdb.Status = existing.Status
+
+	if err := s.store.UpdatePodDisruptionBudget(&pdb); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to update pod disruption budget", "detail": err.Error()})
+		return
+	}
+	log.Printf("Updated pod disruption budget %s/%s", namespace, name)
+	c.JSON(200, pdb)
+}
+
+// updatePodDisruptionBudgetStatusHandler takes the figures the disruption controller computed. Evictions the API
+// server made after the controller listed pods are not reflected in them yet, so those are carried over and
+// charged against the allowed disruptions again
+func (s *APIServer) updatePodDisruptionBudgetStatusHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var status models.PodDisruptionBudgetStatus
+	if err := c.ShouldBindJSON(&status); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+	if status.ObservedTime == nil {
+		c.JSON(400, gin.H{"error": "An observedTime must be provided"})
+		return
+	}
+
+	s.evictionMutex.Lock()
+	defer s.evictionMutex.Unlock()
+
+	existing, err := s.store.GetPodDisruptionBudget(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod disruption budget does not exist", "detail": err.Error()})
+		return
+	}
+
+	for podName, evictedAt := range existing.Status.DisruptedPods {
+		if _, seen := status.DisruptedPods[podName]; seen || !evictedAt.After(*status.ObservedTime) {
+			continue
+		}
+		if status.DisruptedPods == nil {
+			status.DisruptedPods = make(map[string]time.Time)
+		}
+		status.DisruptedPods[podName] = evictedAt
+		status.DisruptionsAllowed = max(status.DisruptionsAllowed-1, 0)
+	}
+
+	updated := *existing
+	updated.Status = status
+	if err := s.store.UpdatePodDisruptionBudget(&updated); err != nil {
+		c.JSON(500, gin.H{"error": "Failed to update pod disruption budget status", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, updated)
+}
+
+func (s *APIServer) deletePodDisruptionBudgetHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if err := s.store.DeletePodDisruptionBudget(namespace, name); err != nil {
+		log.Printf("Error deleting pod disruption budget %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrPodDisruptionBudgetNotExist) {
+			c.JSON(404, gin.H{"error": "Pod disruption budget not found for deletion", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Unable to delete pod disruption budget", "detail": err.Error()})
+		}
+		return
+	}
+
+	log.Printf("Pod disruption budget %s/%s successfully deleted", namespace, name)
+	c.JSON(200, gin.H{"message": fmt.Sprintf("Pod disruption budget %s/%s successfully deleted", namespace, name)})
+}
+
+func (s *APIServer) listPodDisruptionBudgetsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	pdbList, err := s.store.ListPodDisruptionBudgets(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch pod disruption budget list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pdbList)
+}
+
+func validatePodDisruptionBudget(pdb *models.PodDisruptionBudget) error {
+	if pdb.Selector == nil {
+		return fmt.Errorf("a selector must be provided")
+	}
+	if (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+		return fmt.Errorf("exactly one of minAvailable and maxUnavailable must be set")
+	}
+	if pdb.MinAvailable != nil && *pdb.MinAvailable < 0 {
+		return fmt.Errorf("minAvailable cannot be negative")
+	}
+	if pdb.MaxUnavailable != nil && *pdb.MaxUnavailable < 0 {
+		return fmt.Errorf("maxUnavailable cannot be negative")
+	}
+	return nil
+}
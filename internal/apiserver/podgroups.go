@@ -0,0 +1,84 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *APIServer) createPodGroupHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var pg models.PodGroup
+	if err := c.ShouldBindJSON(&pg); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if pg.Name == "" {
+		c.JSON(400, gin.H{"error": "A pod group name must be provided"})
+		return
+	}
+	if pg.MinMember < 1 {
+		c.JSON(400, gin.H{"error": "minMember must be at least 1"})
+		return
+	}
+	if pg.ScheduleTimeoutSeconds <= 0 {
+		pg.ScheduleTimeoutSeconds = models.DefaultPodGroupScheduleTimeoutSeconds
+	}
+	pg.Namespace = namespace
+
+	if err := s.store.CreatePodGroup(&pg); err != nil {
+		log.Printf("Error creating pod group %s/%s: %v", pg.Namespace, pg.Name, err)
+		if errors.Is(err, store.ErrPodGroupExists) {
+			c.JSON(409, gin.H{"error": "Failed to create pod group", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create pod group", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Created pod group %s/%s with minMember %d", pg.Namespace, pg.Name, pg.MinMember)
+	c.JSON(201, pg)
+}
+
+func (s *APIServer) getPodGroupHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	pg, err := s.store.GetPodGroup(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod group not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pg)
+}
+
+func (s *APIServer) deletePodGroupHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if err := s.store.DeletePodGroup(namespace, name); err != nil {
+		log.Printf("Error deleting pod group %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrPodGroupNotExist) {
+			c.JSON(404, gin.H{"error": "Pod group not found for deletion", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Unable to delete pod group", "detail": err.Error()})
+		}
+		return
+	}
+
+	log.Printf("Pod group %s/%s successfully deleted", namespace, name)
+	c.JSON(200, gin.H{"message": fmt.Sprintf("Pod group %s/%s successfully deleted", namespace, name)})
+}
+
+func (s *APIServer) listPodGroupsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	pgList, err := s.store.ListPodGroups(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch pod group list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pgList)
+}
@@ -1,11 +1,14 @@
 package apiserver
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"path"
+	"slices"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joshL1215/k8s-lite/internal/api/models"
@@ -29,7 +32,74 @@ func (s *APIServer) createPodHandler(c *gin.Context) {
 	}
 	pod.Namespace = namespace
 	pod.Phase = models.PodPending
-	pod.NodeName = ""
+	// a mirror pod is already running on the node of the kubelet that publishes it
+	if pod.IsMirror() {
+		if pod.NodeName == "" {
+			c.JSON(400, gin.H{"error": "A mirror pod must name the node running it"})
+			return
+		}
+		pod.Phase = models.PodScheduled
+	} else {
+		pod.NodeName = ""
+	}
+	pod.NominatedNodeName = ""
+	pod.Conditions = nil
+	pod.StartTime = nil
+	pod.HostIP, pod.PodIP = "", ""
+	pod.InitContainerStatuses = nil
+	pod.ContainerStatuses = nil
+	pod.Reason = ""
+	pod.Message = ""
+	switch pod.RestartPolicy {
+	case "":
+		pod.RestartPolicy = models.RestartPolicyAlways
+	case models.RestartPolicyAlways, models.RestartPolicyOnFailure, models.RestartPolicyNever:
+	default:
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Unknown restart policy %s", pod.RestartPolicy)})
+		return
+	}
+	if pod.SchedulerName == "" {
+		pod.SchedulerName = models.DefaultSchedulerName
+	}
+	if pod.TerminationGracePeriodSeconds == nil {
+		grace := models.DefaultTerminationGracePeriodSeconds
+		pod.TerminationGracePeriodSeconds = &grace
+	} else if *pod.TerminationGracePeriodSeconds < 0 {
+		c.JSON(400, gin.H{"error": "terminationGracePeriodSeconds cannot be negative"})
+		return
+	}
+	// a container using the pod's image is left to the kubelet
+	for _, containers := range [][]models.Container{pod.InitContainers, pod.Containers} {
+		for i := range containers {
+			if containers[i].ImagePullPolicy == "" && containers[i].Image != "" {
+				containers[i].ImagePullPolicy = models.DefaultPullPolicy(containers[i].Image)
+			}
+		}
+	}
+
+	if err := validateTopologySpreadConstraints(pod.TopologySpreadConstraints); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid topology spread constraints", "detail": err.Error()})
+		return
+	}
+
+	if err := validateContainers(pod.InitContainers, pod.Containers); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid containers", "detail": err.Error()})
+		return
+	}
+
+	if err := validateVolumes(pod.Volumes, append(slices.Clone(pod.InitContainers), pod.Containers...)); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid volumes", "detail": err.Error()})
+		return
+	}
+
+	if err := s.resolvePodPriority(&pod); err != nil {
+		if errors.Is(err, store.ErrPriorityClassNotExist) {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("Priority class %s does not exist", pod.PriorityClassName), "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to resolve pod priority", "detail": err.Error()})
+		}
+		return
+	}
 
 	if err := s.store.CreatePod(&pod); err != nil {
 		log.Printf("Error creating pod %s/%s: %v", pod.Namespace, pod.Name, err)
@@ -41,6 +111,8 @@ func (s *APIServer) createPodHandler(c *gin.Context) {
 		return
 	}
 	log.Printf("Created pod %s/%s successfully", pod.Namespace, pod.Name)
+	// whatever a former pod of the same name left behind is not about this one
+	s.deletePodEvents(pod.Namespace, pod.Name)
 
 	s.watchManager.Publish(namespace, models.WatchEvent{
 		EventType:   models.AddEvent,
@@ -57,6 +129,7 @@ func (s *APIServer) getPodHandler(c *gin.Context) {
 	pod, err := s.store.GetPod(namespace, name)
 	if err != nil {
 		c.JSON(404, gin.H{"error": "Pod not found", "detail": err.Error()})
+		return
 	}
 	c.JSON(200, pod)
 }
@@ -81,17 +154,27 @@ func (s *APIServer) updatePodHandler(c *gin.Context) {
 		return
 	}
 
-	if _, err := s.store.GetPod(namespace, originalName); err != nil {
+	existing, err := s.store.GetPod(namespace, originalName)
+	if err != nil {
 		c.JSON(404, gin.H{"error": "Pod does not exist", "detail": err.Error()})
 		return
 	}
 
+	// only the status of a mirror pod changes here, its spec follows the manifest on its node
+	if existing.IsMirror() && !sameMirrorSpec(existing, &pod) {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Pod %s/%s is the mirror of a static pod, edit its manifest on node %s instead", namespace, originalName, existing.NodeName)})
+		return
+	}
+
 	if err := s.store.UpdatePod(&pod); err != nil {
 		log.Printf("Failed to update pod: %v", err)
 		c.JSON(500, gin.H{"error": "Failed to update pod", "detail": err.Error()})
 		return
 	}
 	log.Printf("Updated pod %s/%s successfully", pod.Namespace, pod.Name)
+	if pod.Phase == models.PodDeleted && existing.Phase != models.PodDeleted {
+		s.deletePodEvents(pod.Namespace, pod.Name)
+	}
 
 	s.watchManager.Publish(namespace, models.WatchEvent{
 		EventType:   models.ModificationEvent,
@@ -148,34 +231,427 @@ func (s *APIServer) listPodsHandler(c *gin.Context) {
 }
 
 func (s *APIServer) watchPods(c *gin.Context) {
-	namespace := c.Param("namespace")
+	s.serveWatch(c, c.Param("namespace"))
+}
 
-	c.Writer.Header().Set("Content-Type", "application/json")
-	c.Writer.Header().Set("Transfer-Encoding", "chunked")
-	c.Writer.WriteHeader(200)
+func validateTopologySpreadConstraints(constraints []models.TopologySpreadConstraint) error {
+	for i := range constraints {
+		constraint := &constraints[i]
+		if constraint.MaxSkew < 1 {
+			return fmt.Errorf("maxSkew must be at least 1, got %d", constraint.MaxSkew)
+		}
+		if constraint.TopologyKey == "" {
+			return fmt.Errorf("a topologyKey must be provided")
+		}
+		switch constraint.WhenUnsatisfiable {
+		case "":
+			constraint.WhenUnsatisfiable = models.DoNotSchedule
+		case models.DoNotSchedule, models.ScheduleAnyway:
+		default:
+			return fmt.Errorf("unknown whenUnsatisfiable action %s", constraint.WhenUnsatisfiable)
+		}
+	}
+	return nil
+}
 
-	flusher, ok := c.Writer.(http.Flusher)
-	if !ok {
-		c.JSON(500, gin.H{"error": "Failed assertion"})
+// validateContainers checks the init containers and the containers together, as their names share one namespace
+func validateContainers(initContainers, containers []models.Container) error {
+	names := make(map[string]bool, len(initContainers)+len(containers))
+	for i := range len(initContainers) + len(containers) {
+		var container *models.Container
+		init := i < len(initContainers)
+		if init {
+			container = &initContainers[i]
+		} else {
+			container = &containers[i-len(initContainers)]
+		}
+		if container.Name == "" {
+			return fmt.Errorf("every container must have a name")
+		}
+		if names[container.Name] {
+			return fmt.Errorf("container name %s is used more than once", container.Name)
+		}
+		names[container.Name] = true
+
+		if container.Image != "" {
+			if _, err := models.ParseImageReference(container.Image); err != nil {
+				return fmt.Errorf("container %s: %w", container.Name, err)
+			}
+		}
+		switch container.ImagePullPolicy {
+		case "", models.PullAlways, models.PullIfNotPresent, models.PullNever:
+		default:
+			return fmt.Errorf("container %s: unknown image pull policy %s", container.Name, container.ImagePullPolicy)
+		}
+
+		switch {
+		case container.RestartPolicy == "":
+		case !init:
+			return fmt.Errorf("container %s: only init containers can have a restart policy", container.Name)
+		case container.RestartPolicy != models.ContainerRestartPolicyAlways:
+			return fmt.Errorf("init container %s: unknown restart policy %s", container.Name, container.RestartPolicy)
+		}
+		// an init container that runs to completion is never ready or live, it only has to finish
+		if init && !container.IsSidecar() && (container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil) {
+			return fmt.Errorf("init container %s cannot have probes unless it is a sidecar", container.Name)
+		}
+		if lifecycle := container.Lifecycle; lifecycle != nil {
+			if init && !container.IsSidecar() {
+				return fmt.Errorf("init container %s cannot have lifecycle hooks unless it is a sidecar", container.Name)
+			}
+			hooks := map[string]*models.LifecycleHandler{"postStart": lifecycle.PostStart, "preStop": lifecycle.PreStop}
+			for kind, hook := range hooks {
+				if hook == nil {
+					continue
+				}
+				if (hook.Exec == nil) == (hook.HTTPGet == nil) {
+					return fmt.Errorf("container %s %s: exactly one of exec and httpGet must be set", container.Name, kind)
+				}
+				if err := validateHandler(models.ProbeHandler{Exec: hook.Exec, HTTPGet: hook.HTTPGet}); err != nil {
+					return fmt.Errorf("container %s %s: %w", container.Name, kind, err)
+				}
+			}
+		}
+
+		if err := validateEnv(container.Env, container.EnvFrom); err != nil {
+			return fmt.Errorf("container %s: %w", container.Name, err)
+		}
+
+		probes := map[string]*models.Probe{
+			"livenessProbe":  container.LivenessProbe,
+			"readinessProbe": container.ReadinessProbe,
+			"startupProbe":   container.StartupProbe,
+		}
+		for kind, probe := range probes {
+			if probe == nil {
+				continue
+			}
+			if err := validateProbe(probe); err != nil {
+				return fmt.Errorf("container %s %s: %w", container.Name, kind, err)
+			}
+		}
+		// a liveness or startup probe only ever needs one success to count
+		if container.LivenessProbe != nil && container.LivenessProbe.SuccessThreshold != 1 {
+			return fmt.Errorf("container %s livenessProbe successThreshold must be 1", container.Name)
+		}
+		if container.StartupProbe != nil && container.StartupProbe.SuccessThreshold != 1 {
+			return fmt.Errorf("container %s startupProbe successThreshold must be 1", container.Name)
+		}
 	}
+	return nil
+}
 
-	watchCh := s.watchManager.Subscribe(namespace)
-	defer s.watchManager.Unsubscribe(namespace, watchCh)
+func validateEnv(env []models.EnvVar, envFrom []models.EnvFromSource) error {
+	for _, envVar := range env {
+		if envVar.Name == "" {
+			return fmt.Errorf("every env var must have a name")
+		}
+		source := envVar.ValueFrom
+		if source == nil {
+			continue
+		}
+		if envVar.Value != "" {
+			return fmt.Errorf("env var %s cannot have both value and valueFrom", envVar.Name)
+		}
+		set := 0
+		if source.ConfigMapKeyRef != nil {
+			set++
+			if source.ConfigMapKeyRef.Name == "" || source.ConfigMapKeyRef.Key == "" {
+				return fmt.Errorf("env var %s configMapKeyRef must give a name and a key", envVar.Name)
+			}
+		}
+		if source.SecretKeyRef != nil {
+			set++
+			if source.SecretKeyRef.Name == "" || source.SecretKeyRef.Key == "" {
+				return fmt.Errorf("env var %s secretKeyRef must give a name and a key", envVar.Name)
+			}
+		}
+		if source.FieldRef != nil {
+			set++
+			// a whole map only makes sense as a file
+			if models.IsWholeMapField(source.FieldRef.FieldPath) {
+				return fmt.Errorf("env var %s fieldRef must select a single label or annotation", envVar.Name)
+			}
+			if err := validateFieldRef(source.FieldRef); err != nil {
+				return fmt.Errorf("env var %s %w", envVar.Name, err)
+			}
+		}
+		if source.ResourceFieldRef != nil {
+			set++
+			if err := validateResourceFieldRef(source.ResourceFieldRef); err != nil {
+				return fmt.Errorf("env var %s %w", envVar.Name, err)
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("env var %s valueFrom must set exactly one of configMapKeyRef, secretKeyRef, fieldRef and resourceFieldRef", envVar.Name)
+		}
+	}
+	for _, source := range envFrom {
+		switch {
+		case source.ConfigMapRef != nil && source.SecretRef == nil:
+			if source.ConfigMapRef.Name == "" {
+				return fmt.Errorf("envFrom configMapRef must give a name")
+			}
+		case source.SecretRef != nil && source.ConfigMapRef == nil:
+			if source.SecretRef.Name == "" {
+				return fmt.Errorf("envFrom secretRef must give a name")
+			}
+		default:
+			return fmt.Errorf("envFrom must set exactly one of configMapRef and secretRef")
+		}
+	}
+	return nil
+}
+
+// validateProbe checks that exactly one handler is set and fills in the defaults for unset timings
+func validateProbe(probe *models.Probe) error {
+	if err := validateHandler(probe.ProbeHandler); err != nil {
+		return err
+	}
+	if probe.InitialDelaySeconds < 0 || probe.TimeoutSeconds < 0 || probe.PeriodSeconds < 0 ||
+		probe.SuccessThreshold < 0 || probe.FailureThreshold < 0 {
+		return fmt.Errorf("probe timings and thresholds cannot be negative")
+	}
+	if probe.TimeoutSeconds == 0 {
+		probe.TimeoutSeconds = models.DefaultProbeTimeoutSeconds
+	}
+	if probe.PeriodSeconds == 0 {
+		probe.PeriodSeconds = models.DefaultProbePeriodSeconds
+	}
+	if probe.SuccessThreshold == 0 {
+		probe.SuccessThreshold = models.DefaultProbeSuccessThreshold
+	}
+	if probe.FailureThreshold == 0 {
+		probe.FailureThreshold = models.DefaultProbeFailureThreshold
+	}
+	return nil
+}
 
-	ctx := c.Request.Context()
+func validateHandler(handler models.ProbeHandler) error {
+	set := 0
+	if handler.Exec != nil {
+		set++
+		if len(handler.Exec.Command) == 0 {
+			return fmt.Errorf("exec handler needs a command")
+		}
+	}
+	if handler.HTTPGet != nil {
+		set++
+		if handler.HTTPGet.Port < 1 || handler.HTTPGet.Port > 65535 {
+			return fmt.Errorf("httpGet port %d is out of range", handler.HTTPGet.Port)
+		}
+		switch handler.HTTPGet.Scheme {
+		case "", models.URISchemeHTTP, models.URISchemeHTTPS:
+		default:
+			return fmt.Errorf("unknown httpGet scheme %s", handler.HTTPGet.Scheme)
+		}
+	}
+	if handler.TCPSocket != nil {
+		set++
+		if handler.TCPSocket.Port < 1 || handler.TCPSocket.Port > 65535 {
+			return fmt.Errorf("tcpSocket port %d is out of range", handler.TCPSocket.Port)
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of exec, httpGet and tcpSocket must be set")
+	}
+	return nil
+}
 
-	for {
-		select {
-		case event := <-watchCh:
-			if err := json.NewEncoder(c.Writer).Encode(event); err != nil {
-				log.Printf("Error encoding watch event: %v", err)
-				return
+// validateVolumes checks that every volume has exactly one valid source and that containers only mount volumes of
+// the pod
+func validateVolumes(volumes []models.Volume, containers []models.Container) error {
+	names := make(map[string]bool, len(volumes))
+	for _, volume := range volumes {
+		if volume.Name == "" {
+			return fmt.Errorf("every volume must have a name")
+		}
+		if names[volume.Name] {
+			return fmt.Errorf("volume name %s is used more than once", volume.Name)
+		}
+		names[volume.Name] = true
+		if err := validateVolumeSource(volume.VolumeSource); err != nil {
+			return fmt.Errorf("volume %s: %w", volume.Name, err)
+		}
+	}
+
+	for _, container := range containers {
+		mountPaths := make(map[string]bool, len(container.VolumeMounts))
+		for _, mount := range container.VolumeMounts {
+			if !names[mount.Name] {
+				return fmt.Errorf("container %s mounts volume %s, which the pod does not have", container.Name, mount.Name)
+			}
+			if mount.MountPath == "" {
+				return fmt.Errorf("container %s must give a mountPath for volume %s", container.Name, mount.Name)
 			}
-			flusher.Flush()
+			if mountPaths[path.Clean(mount.MountPath)] {
+				return fmt.Errorf("container %s mounts more than one volume at %s", container.Name, mount.MountPath)
+			}
+			mountPaths[path.Clean(mount.MountPath)] = true
+			if mount.SubPath != "" && !isRelativeSubpath(mount.SubPath) {
+				return fmt.Errorf("container %s subPath %s of volume %s must be relative and stay within the volume", container.Name, mount.SubPath, mount.Name)
+			}
+		}
+	}
+	return nil
+}
 
-		case <-ctx.Done():
-			log.Printf("Client connection closed for namespace %s", namespace)
-			return
+func validateVolumeSource(source models.VolumeSource) error {
+	set := 0
+	if source.EmptyDir != nil {
+		set++
+		switch source.EmptyDir.Medium {
+		case models.StorageMediumDefault, models.StorageMediumMemory:
+		default:
+			return fmt.Errorf("unknown emptyDir medium %s", source.EmptyDir.Medium)
+		}
+		if source.EmptyDir.SizeLimit < 0 {
+			return fmt.Errorf("emptyDir sizeLimit cannot be negative")
+		}
+	}
+	if source.HostPath != nil {
+		set++
+		if !path.IsAbs(source.HostPath.Path) {
+			return fmt.Errorf("hostPath path %q must be absolute", source.HostPath.Path)
+		}
+		switch source.HostPath.Type {
+		case models.HostPathUnset, models.HostPathDirectoryOrCreate, models.HostPathDirectory, models.HostPathFileOrCreate, models.HostPathFile:
+		default:
+			return fmt.Errorf("unknown hostPath type %s", source.HostPath.Type)
+		}
+	}
+	if source.ConfigMap != nil {
+		set++
+		if source.ConfigMap.Name == "" {
+			return fmt.Errorf("configMap name must be provided")
+		}
+		if err := validateProjection(source.ConfigMap.Items, source.ConfigMap.DefaultMode); err != nil {
+			return fmt.Errorf("configMap %w", err)
+		}
+	}
+	if source.Secret != nil {
+		set++
+		if source.Secret.SecretName == "" {
+			return fmt.Errorf("secret secretName must be provided")
+		}
+		if err := validateProjection(source.Secret.Items, source.Secret.DefaultMode); err != nil {
+			return fmt.Errorf("secret %w", err)
+		}
+	}
+	if source.DownwardAPI != nil {
+		set++
+		if err := validateDownwardAPI(source.DownwardAPI); err != nil {
+			return fmt.Errorf("downwardAPI %w", err)
+		}
+	}
+	if set != 1 {
+		return fmt.Errorf("exactly one of emptyDir, hostPath, configMap, secret and downwardAPI must be set")
+	}
+	return nil
+}
+
+func validateDownwardAPI(source *models.DownwardAPIVolumeSource) error {
+	if !validFileMode(source.DefaultMode) {
+		return fmt.Errorf("defaultMode %o is out of range", *source.DefaultMode)
+	}
+	paths := make(map[string]bool, len(source.Items))
+	for _, item := range source.Items {
+		if !isRelativeSubpath(item.Path) {
+			return fmt.Errorf("item path %q must be relative and stay within the volume", item.Path)
 		}
+		if paths[path.Clean(item.Path)] {
+			return fmt.Errorf("item path %s is used more than once", item.Path)
+		}
+		paths[path.Clean(item.Path)] = true
+		if !validFileMode(item.Mode) {
+			return fmt.Errorf("item %s mode %o is out of range", item.Path, *item.Mode)
+		}
+		switch {
+		case item.FieldRef != nil && item.ResourceFieldRef == nil:
+			if err := validateFieldRef(item.FieldRef); err != nil {
+				return fmt.Errorf("item %s %w", item.Path, err)
+			}
+		case item.ResourceFieldRef != nil && item.FieldRef == nil:
+			if err := validateResourceFieldRef(item.ResourceFieldRef); err != nil {
+				return fmt.Errorf("item %s %w", item.Path, err)
+			}
+		default:
+			return fmt.Errorf("item %s must set exactly one of fieldRef and resourceFieldRef", item.Path)
+		}
+	}
+	return nil
+}
+
+// field and resource references are checked by resolving them against an empty pod
+func validateFieldRef(ref *models.ObjectFieldSelector) error {
+	if _, err := models.ExtractFieldPath(&models.Pod{}, ref.FieldPath); err != nil {
+		return fmt.Errorf("fieldRef: %w", err)
+	}
+	return nil
+}
+
+func validateResourceFieldRef(ref *models.ResourceFieldSelector) error {
+	if _, err := models.ExtractResourceValue(&models.Pod{}, ref, models.ResourceList{}); err != nil {
+		return fmt.Errorf("resourceFieldRef: %w", err)
+	}
+	return nil
+}
+
+func validateProjection(items []models.KeyToPath, defaultMode *int32) error {
+	if !validFileMode(defaultMode) {
+		return fmt.Errorf("defaultMode %o is out of range", *defaultMode)
+	}
+	paths := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.Key == "" {
+			return fmt.Errorf("items must name a key")
+		}
+		if !isRelativeSubpath(item.Path) {
+			return fmt.Errorf("item path %q of key %s must be relative and stay within the volume", item.Path, item.Key)
+		}
+		if paths[path.Clean(item.Path)] {
+			return fmt.Errorf("item path %s is used more than once", item.Path)
+		}
+		paths[path.Clean(item.Path)] = true
+		if !validFileMode(item.Mode) {
+			return fmt.Errorf("mode %o of key %s is out of range", *item.Mode, item.Key)
+		}
+	}
+	return nil
+}
+
+func validFileMode(mode *int32) bool {
+	return mode == nil || (*mode >= 0 && *mode <= 0o777)
+}
+
+// isRelativeSubpath reports whether p names something inside the directory it is relative to. Names starting with
+// .. are reserved for the kubelet's own bookkeeping in projected volumes
+func isRelativeSubpath(p string) bool {
+	if p == "" || path.IsAbs(p) {
+		return false
+	}
+	for _, element := range strings.Split(p, "/") {
+		if strings.HasPrefix(element, "..") {
+			return false
+		}
+	}
+	return true
+}
+
+// sameMirrorSpec compares two copies of a mirror pod leaving out everything the kubelet and the API server fill in
+func sameMirrorSpec(a, b *models.Pod) bool {
+	spec := func(pod *models.Pod) []byte {
+		p := *pod
+		p.Phase = ""
+		p.DeletionTimestamp = nil
+		p.Reason, p.Message = "", ""
+		p.Conditions = nil
+		p.StartTime = nil
+		p.HostIP, p.PodIP = "", ""
+		p.InitContainerStatuses = nil
+		p.ContainerStatuses = nil
+		data, _ := json.Marshal(p)
+		return data
 	}
+	return bytes.Equal(spec(a), spec(b))
 }
@@ -0,0 +1,124 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *APIServer) createPriorityClassHandler(c *gin.Context) {
+	var pc models.PriorityClass
+	if err := c.ShouldBindJSON(&pc); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if pc.Name == "" {
+		c.JSON(400, gin.H{"error": "A priority class name must be provided"})
+		return
+	}
+
+	if pc.PreemptionPolicy == "" {
+		pc.PreemptionPolicy = models.PreemptLowerPriority
+	}
+
+	if pc.GlobalDefault {
+		if existing, err := s.globalDefaultPriorityClass(); err == nil && existing != nil {
+			c.JSON(409, gin.H{"error": fmt.Sprintf("Priority class %s is already the global default", existing.Name)})
+			return
+		}
+	}
+
+	if err := s.store.CreatePriorityClass(&pc); err != nil {
+		log.Printf("Error creating priority class %s: %v", pc.Name, err)
+		if errors.Is(err, store.ErrPriorityClassExists) {
+			c.JSON(409, gin.H{"error": "Failed to create priority class", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create priority class", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Created priority class %s with value %d", pc.Name, pc.Value)
+	c.JSON(201, pc)
+}
+
+func (s *APIServer) getPriorityClassHandler(c *gin.Context) {
+	name := c.Param("name")
+	pc, err := s.store.GetPriorityClass(name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Priority class not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pc)
+}
+
+func (s *APIServer) deletePriorityClassHandler(c *gin.Context) {
+	name := c.Param("name")
+
+	if err := s.store.DeletePriorityClass(name); err != nil {
+		log.Printf("Error deleting priority class %s: %v", name, err)
+		if errors.Is(err, store.ErrPriorityClassNotExist) {
+			c.JSON(404, gin.H{"error": "Priority class not found for deletion", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Unable to delete priority class", "detail": err.Error()})
+		}
+		return
+	}
+
+	log.Printf("Priority class %s successfully deleted", name)
+	c.JSON(200, gin.H{"message": fmt.Sprintf("Priority class %s successfully deleted", name)})
+}
+
+func (s *APIServer) listPriorityClassesHandler(c *gin.Context) {
+	pcList, err := s.store.ListPriorityClasses()
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Unable to list priority classes", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, pcList)
+}
+
+func (s *APIServer) globalDefaultPriorityClass() (*models.PriorityClass, error) {
+	pcList, err := s.store.ListPriorityClasses()
+	if err != nil {
+		return nil, err
+	}
+	for _, pc := range pcList {
+		if pc.GlobalDefault {
+			return pc, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolvePodPriority fills in the priority of a new pod from its priority class, or from the global default class when none is named
+func (s *APIServer) resolvePodPriority(pod *models.Pod) error {
+	var pc *models.PriorityClass
+	if pod.PriorityClassName != "" {
+		found, err := s.store.GetPriorityClass(pod.PriorityClassName)
+		if err != nil {
+			return err
+		}
+		pc = found
+	} else {
+		found, err := s.globalDefaultPriorityClass()
+		if err != nil {
+			return err
+		}
+		pc = found
+	}
+
+	if pc == nil {
+		pod.Priority = 0
+		pod.PreemptionPolicy = models.PreemptLowerPriority
+		return nil
+	}
+	pod.PriorityClassName = pc.Name
+	pod.Priority = pc.Value
+	pod.PreemptionPolicy = pc.PreemptionPolicy
+	return nil
+}
@@ -0,0 +1,178 @@
+package apiserver
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *APIServer) createSecretHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	var secret models.Secret
+	if err := c.ShouldBindJSON(&secret); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+
+	if secret.Name == "" {
+		c.JSON(400, gin.H{"error": "A secret name must be provided"})
+		return
+	}
+	secret.Namespace = namespace
+	if secret.Type == "" {
+		secret.Type = models.SecretTypeOpaque
+	}
+	if err := validateSecret(&secret); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid secret", "detail": err.Error()})
+		return
+	}
+
+	if err := s.store.CreateSecret(&secret); err != nil {
+		log.Printf("Error creating secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		if errors.Is(err, store.ErrSecretExists) {
+			c.JSON(409, gin.H{"error": "Failed to create secret", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to create secret", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Created secret %s/%s successfully", secret.Namespace, secret.Name)
+
+	s.watchManager.Publish(objectScope(namespace, "secret"), models.WatchEvent{
+		EventType:   models.AddEvent,
+		EventObject: "secret",
+		Secret:      &secret,
+	})
+
+	c.JSON(201, secret)
+}
+
+func (s *APIServer) getSecretHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	secret, err := s.store.GetSecret(namespace, name)
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Secret not found", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, secret)
+}
+
+func (s *APIServer) listSecretsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	if c.Query("watch") == "true" {
+		s.serveWatch(c, objectScope(namespace, "secret"))
+		return
+	}
+
+	secretList, err := s.store.ListSecrets(namespace)
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch secret list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, secretList)
+}
+
+// listAllSecretsHandler lists the secrets of every namespace
+func (s *APIServer) listAllSecretsHandler(c *gin.Context) {
+	secretList, err := s.store.ListSecrets("")
+	if err != nil {
+		c.JSON(500, gin.H{"error": "Could not fetch secret list", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, secretList)
+}
+
+func (s *APIServer) updateSecretHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+	var secret models.Secret
+	if err := c.ShouldBindJSON(&secret); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid request body", "detail": err.Error()})
+		return
+	}
+	if secret.Name != name || secret.Namespace != namespace {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Secret in body does not match %s/%s", namespace, name)})
+		return
+	}
+	if secret.Type == "" {
+		secret.Type = models.SecretTypeOpaque
+	}
+	if err := validateSecret(&secret); err != nil {
+		c.JSON(400, gin.H{"error": "Invalid secret", "detail": err.Error()})
+		return
+	}
+
+	if err := s.store.UpdateSecret(&secret); err != nil {
+		log.Printf("Error updating secret %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrSecretNotExist) {
+			c.JSON(404, gin.H{"error": "Secret does not exist", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Failed to update secret", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Updated secret %s/%s", namespace, name)
+
+	s.watchManager.Publish(objectScope(namespace, "secret"), models.WatchEvent{
+		EventType:   models.ModificationEvent,
+		EventObject: "secret",
+		Secret:      &secret,
+	})
+
+	c.JSON(200, secret)
+}
+
+func (s *APIServer) deleteSecretHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	name := c.Param("name")
+
+	if err := s.store.DeleteSecret(namespace, name); err != nil {
+		log.Printf("Error deleting secret %s/%s: %v", namespace, name, err)
+		if errors.Is(err, store.ErrSecretNotExist) {
+			c.JSON(404, gin.H{"error": "Secret not found for deletion", "detail": err.Error()})
+		} else {
+			c.JSON(500, gin.H{"error": "Unable to delete secret", "detail": err.Error()})
+		}
+		return
+	}
+	log.Printf("Secret %s/%s successfully deleted", namespace, name)
+
+	s.watchManager.Publish(objectScope(namespace, "secret"), models.WatchEvent{
+		EventType:   models.DeletionEvent,
+		EventObject: "secret",
+		Secret:      &models.Secret{Name: name, Namespace: namespace},
+	})
+
+	c.JSON(200, gin.H{"message": fmt.Sprintf("Secret %s/%s successfully deleted", namespace, name)})
+}
+
+// validateSecret merges StringData into Data and checks the result
+func validateSecret(secret *models.Secret) error {
+	for key, value := range secret.StringData {
+		if secret.Data == nil {
+			secret.Data = make(map[string][]byte)
+		}
+		secret.Data[key] = []byte(value)
+	}
+	secret.StringData = nil
+
+	size := 0
+	for key, value := range secret.Data {
+		if err := validateConfigKey(key); err != nil {
+			return err
+		}
+		if err := validateConfigValue(key, value); err != nil {
+			return err
+		}
+		size += len(value)
+	}
+	if size > models.MaxConfigDataSize {
+		return fmt.Errorf("data is %d bytes, more than the limit of %d", size, models.MaxConfigDataSize)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package apiserver
 
 import (
 	"log"
+	"sync"
 
 	"github.com/gin-gonic/gin"
 	"github.com/joshL1215/k8s-lite/internal/store"
@@ -13,6 +14,9 @@ type APIServer struct {
 	router       *gin.Engine
 	store        store.StoreInterface // having an interface here makes it store-implementation-agnostic
 	watchManager watchManager
+
+	// serializes evictions and budget status writes
+	evictionMutex sync.Mutex
 }
 
 func (s *APIServer) Serve(port string) {
@@ -30,8 +34,62 @@ func (s *APIServer) registerRoutes() {
 		podsGroup.GET("/:podname", s.getPodHandler)
 		podsGroup.PUT("/:podname", s.updatePodHandler)
 		podsGroup.DELETE(":podname", s.deletePodHandler)
+		podsGroup.POST("/:podname/eviction", s.evictPodHandler)          // deletes the pod unless a disruption budget forbids it
+		podsGroup.GET("/:podname/log", s.podLogsHandler)                 // proxied to the pod's kubelet, ?container=&follow=&tailLines=&sinceSeconds=&timestamps=&previous=
+		podsGroup.POST("/:podname/exec", s.podExecHandler)               // upgraded to a stream to the pod's kubelet, ?container=&command=&stdin=&stdout=&stderr=&tty=
+		podsGroup.POST("/:podname/attach", s.podAttachHandler)           // upgraded to a stream to the pod's kubelet, ?container=&stdin=&stdout=&stderr=&tty=
+		podsGroup.POST("/:podname/portforward", s.podPortForwardHandler) // upgraded to a stream to the pod's kubelet, ?ports=
+		podsGroup.GET("/:podname/stats", s.podStatsHandler)              // proxied to the pod's kubelet, usage read from the pod's cgroups
+	}
+
+	pdbGroup := s.router.Group("/api/v1/namespace/:namespace/poddisruptionbudgets")
+	{
+		pdbGroup.POST("", s.createPodDisruptionBudgetHandler)
+		pdbGroup.GET("", s.listPodDisruptionBudgetsHandler)
+		pdbGroup.GET("/:name", s.getPodDisruptionBudgetHandler)
+		pdbGroup.PUT("/:name", s.updatePodDisruptionBudgetHandler)
+		pdbGroup.PUT("/:name/status", s.updatePodDisruptionBudgetStatusHandler)
+		pdbGroup.DELETE("/:name", s.deletePodDisruptionBudgetHandler)
+	}
+
+	podGroupsGroup := s.router.Group("/api/v1/namespace/:namespace/podgroups")
+	{
+		podGroupsGroup.POST("", s.createPodGroupHandler)
+		podGroupsGroup.GET("", s.listPodGroupsHandler)
+		podGroupsGroup.GET("/:name", s.getPodGroupHandler)
+		podGroupsGroup.DELETE("/:name", s.deletePodGroupHandler)
+	}
+
+	eventsGroup := s.router.Group("/api/v1/namespace/:namespace/events")
+	{
+		eventsGroup.POST("", s.createEventHandler)
+		eventsGroup.GET("", s.listEventsHandler) // ?kind=Pod&name=<pod> lists the events of a single pod
+		eventsGroup.GET("/:name", s.getEventHandler)
+		eventsGroup.PUT("/:name", s.updateEventHandler)
 	}
 
+	configMapsGroup := s.router.Group("/api/v1/namespace/:namespace/configmaps")
+	{
+		configMapsGroup.POST("", s.createConfigMapHandler)
+		configMapsGroup.GET("", s.listConfigMapsHandler) // ?watch=true streams changes
+		configMapsGroup.GET("/:name", s.getConfigMapHandler)
+		configMapsGroup.PUT("/:name", s.updateConfigMapHandler)
+		configMapsGroup.DELETE("/:name", s.deleteConfigMapHandler)
+	}
+
+	secretsGroup := s.router.Group("/api/v1/namespace/:namespace/secrets")
+	{
+		secretsGroup.POST("", s.createSecretHandler)
+		secretsGroup.GET("", s.listSecretsHandler) // ?watch=true streams changes
+		secretsGroup.GET("/:name", s.getSecretHandler)
+		secretsGroup.PUT("/:name", s.updateSecretHandler)
+		secretsGroup.DELETE("/:name", s.deleteSecretHandler)
+	}
+
+	// across every namespace, for tools that walk them all
+	s.router.GET("/api/v1/configmaps", s.listAllConfigMapsHandler)
+	s.router.GET("/api/v1/secrets", s.listAllSecretsHandler)
+
 	nodesGroup := s.router.Group("/api/v1/nodes")
 	{
 		nodesGroup.POST("", s.createNodeHandler)
@@ -40,6 +98,14 @@ func (s *APIServer) registerRoutes() {
 		nodesGroup.PUT("/:nodename", s.updateNodeHandler)
 		nodesGroup.DELETE("/:nodename", s.deleteNodeHandler)
 	}
+
+	priorityClassesGroup := s.router.Group("/api/v1/priorityclasses")
+	{
+		priorityClassesGroup.POST("", s.createPriorityClassHandler)
+		priorityClassesGroup.GET("", s.listPriorityClassesHandler)
+		priorityClassesGroup.GET("/:name", s.getPriorityClassHandler)
+		priorityClassesGroup.DELETE("/:name", s.deletePriorityClassHandler)
+	}
 }
 
 func CreateAPIServer(s store.StoreInterface) *APIServer {
@@ -1,12 +1,24 @@
 package apiserver
 
 import (
+	"encoding/json"
 	"log"
+	"net/http"
 	"sync"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joshL1215/k8s-lite/internal/api/models"
 )
 
+// nodes are not namespaced, so their events are published under a key no namespace can take
+const clusterScope = ""
+
+// config maps and secrets are published apart from the pods of their namespace, so pod watchers are never sent
+// secret data
+func objectScope(namespace string, object models.EventObject) string {
+	return namespace + "/" + string(object)
+}
+
 type watchManager struct {
 	mu       sync.Mutex
 	watchers map[string][]chan models.WatchEvent
@@ -54,3 +66,35 @@ func (wm *watchManager) Unsubscribe(namespace string, ch chan models.WatchEvent)
 		}
 	}
 }
+
+// serveWatch streams every event published under key to the client until it disconnects
+func (s *APIServer) serveWatch(c *gin.Context, key string) {
+	c.Writer.Header().Set("Content-Type", "application/json")
+	c.Writer.Header().Set("Transfer-Encoding", "chunked")
+	c.Writer.WriteHeader(200)
+
+	flusher, ok := c.Writer.(http.Flusher)
+	if !ok {
+		c.JSON(500, gin.H{"error": "Failed assertion"})
+	}
+
+	watchCh := s.watchManager.Subscribe(key)
+	defer s.watchManager.Unsubscribe(key, watchCh)
+
+	ctx := c.Request.Context()
+
+	for {
+		select {
+		case event := <-watchCh:
+			if err := json.NewEncoder(c.Writer).Encode(event); err != nil {
+				log.Printf("Error encoding watch event: %v", err)
+				return
+			}
+			flusher.Flush()
+
+		case <-ctx.Done():
+			log.Printf("Client connection closed for watch on %q", key)
+			return
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const DefaultNamespace = "default"
+
+// an evicted pod that still looks healthy after this long is assumed to have survived its eviction
+const disruptedPodTimeout = 2 * time.Minute
+
+// DisruptionController keeps the status of every pod disruption budget current, recomputing it whenever a pod
+// changes and every ResyncPeriod so new budgets and expired disruptions are picked up
+type DisruptionController struct {
+	Client       *client.Client
+	ResyncPeriod time.Duration
+}
+
+// Run syncs the budgets until the pod watch is closed
+func (dc *DisruptionController) Run() error {
+	podCh, err := dc.Client.WatchPods(DefaultNamespace)
+	if err != nil {
+		return fmt.Errorf("error watching pods: %w", err)
+	}
+
+	dc.syncAll()
+	ticker := time.NewTicker(dc.ResyncPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case _, ok := <-podCh:
+			if !ok {
+				return fmt.Errorf("pod watch closed")
+			}
+			// a burst of pod events is handled by a single sync
+			drain(podCh)
+			dc.syncAll()
+
+		case <-ticker.C:
+			dc.syncAll()
+		}
+	}
+}
+
+func drain(ch <-chan models.WatchEvent) {
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (dc *DisruptionController) syncAll() {
+	// taken before anything is listed: evictions the API server records after this are charged again on write
+	observedTime := time.Now()
+
+	pods, err := dc.Client.ListPods(DefaultNamespace, "")
+	if err != nil {
+		log.Printf("Error listing pods for disruption budgets: %v", err)
+		return
+	}
+	pdbs, err := dc.Client.ListPodDisruptionBudgets(DefaultNamespace)
+	if err != nil {
+		log.Printf("Error listing pod disruption budgets: %v", err)
+		return
+	}
+
+	for i := range pdbs {
+		pdb := &pdbs[i]
+		status := computeStatus(pdb, pods, observedTime)
+		if pdb.Status.ObservedTime != nil && sameStatus(pdb.Status, status) {
+			continue
+		}
+		pdb.Status = status
+		if _, err := dc.Client.UpdatePodDisruptionBudgetStatus(pdb); err != nil {
+			log.Printf("Error updating status of pod disruption budget %s/%s: %v", pdb.Namespace, pdb.Name, err)
+			continue
+		}
+		log.Printf("Pod disruption budget %s/%s has %d/%d healthy pods, %d disruptions allowed",
+			pdb.Namespace, pdb.Name, status.CurrentHealthy, status.DesiredHealthy, status.DisruptionsAllowed)
+	}
+}
+
+func computeStatus(pdb *models.PodDisruptionBudget, pods []models.Pod, observedTime time.Time) models.PodDisruptionBudgetStatus {
+	status := models.PodDisruptionBudgetStatus{ObservedTime: &observedTime}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.IsTerminated() || !pdb.Selector.Matches(pod.Labels) {
+			continue
+		}
+		// terminating pods are still expected, they have not given up their place yet
+		status.ExpectedPods++
+		if !pod.IsHealthy() {
+			continue
+		}
+
+		// a pod the API server already evicted is not counted again until the eviction has taken effect
+		if evictedAt, ok := pdb.Status.DisruptedPods[pod.Name]; ok && observedTime.Sub(evictedAt) < disruptedPodTimeout {
+			if status.DisruptedPods == nil {
+				status.DisruptedPods = make(map[string]time.Time)
+			}
+			status.DisruptedPods[pod.Name] = evictedAt
+			continue
+		}
+		status.CurrentHealthy++
+	}
+
+	if pdb.MinAvailable != nil {
+		status.DesiredHealthy = *pdb.MinAvailable
+	} else if pdb.MaxUnavailable != nil {
+		status.DesiredHealthy = max(status.ExpectedPods-*pdb.MaxUnavailable, 0)
+	}
+	status.DisruptionsAllowed = max(status.CurrentHealthy-status.DesiredHealthy, 0)
+	return status
+}
+
+func sameStatus(a, b models.PodDisruptionBudgetStatus) bool {
+	return a.ExpectedPods == b.ExpectedPods &&
+		a.CurrentHealthy == b.CurrentHealthy &&
+		a.DesiredHealthy == b.DesiredHealthy &&
+		a.DisruptionsAllowed == b.DisruptionsAllowed &&
+		maps.Equal(a.DisruptedPods, b.DisruptedPods)
+}
@@ -0,0 +1,128 @@
+package descheduler
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const DefaultNamespace = "default"
+
+// Descheduler periodically evicts running pods that a fresh scheduling decision would place elsewhere. It never
+// picks a new node itself, the evicted pods are recreated by whoever owns them and placed by the scheduler
+type Descheduler struct {
+	Client *client.Client
+	Policy *Policy
+	DryRun bool
+}
+
+// nodeUsage is a ready node with the live pods bound to it and the sum of their requests
+type nodeUsage struct {
+	node      *models.Node
+	pods      []*models.Pod
+	requested models.ResourceList
+}
+
+// Run performs a pass every interval until the process exits
+func (d *Descheduler) Run(interval time.Duration) {
+	for {
+		if err := d.RunOnce(); err != nil {
+			log.Printf("Descheduling pass failed: %v", err)
+		}
+		time.Sleep(interval)
+	}
+}
+
+// RunOnce takes a fresh snapshot and runs every enabled strategy against it
+func (d *Descheduler) RunOnce() error {
+	readyNodes, err := d.Client.ListNodes(models.NodeReady)
+	if err != nil {
+		return fmt.Errorf("error fetching nodes: %w", err)
+	}
+	pods, err := d.Client.ListPods(DefaultNamespace, "")
+	if err != nil {
+		return fmt.Errorf("error fetching pods: %w", err)
+	}
+
+	usages := buildUsage(readyNodes, pods)
+	e := newEvictor(d.Client, d.Policy, d.DryRun)
+
+	// violations go first, they are the evictions with the clearest payoff
+	if d.Policy.RemovePodsViolatingNodeTaints {
+		removePodsViolatingNodeTaints(e, usages)
+	}
+	if d.Policy.RemovePodsViolatingNodeAffinity {
+		removePodsViolatingNodeAffinity(e, usages)
+	}
+	if d.Policy.RemoveDuplicates {
+		removeDuplicates(e, usages)
+	}
+	if d.Policy.LowNodeUtilization != nil {
+		lowNodeUtilization(e, usages, d.Policy.LowNodeUtilization)
+	}
+
+	log.Printf("Descheduling pass over %d nodes evicted %d pods", len(usages), e.total)
+	return nil
+}
+
+func buildUsage(nodes []models.Node, pods []models.Pod) []*nodeUsage {
+	usages := make([]*nodeUsage, 0, len(nodes))
+	byName := make(map[string]*nodeUsage, len(nodes))
+	for i := range nodes {
+		usage := &nodeUsage{node: &nodes[i]}
+		usages = append(usages, usage)
+		byName[nodes[i].Name] = usage
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.NodeName == "" || pod.DeletionTimestamp != nil || pod.IsTerminated() {
+			continue
+		}
+		if usage, ok := byName[pod.NodeName]; ok {
+			usage.addPod(pod)
+		}
+	}
+	return usages
+}
+
+func (u *nodeUsage) addPod(pod *models.Pod) {
+	u.pods = append(u.pods, pod)
+	requests := pod.SchedulingRequests()
+	u.requested.CPU += requests.CPU
+	u.requested.Memory += requests.Memory
+	u.requested.Pods += requests.Pods
+}
+
+// removePod drops an evicted pod so later strategies in the same pass see the node as it will be
+func (u *nodeUsage) removePod(pod *models.Pod) {
+	for i, p := range u.pods {
+		if p.Namespace == pod.Namespace && p.Name == pod.Name {
+			u.pods = append(u.pods[:i:i], u.pods[i+1:]...)
+			requests := pod.SchedulingRequests()
+			u.requested.CPU -= requests.CPU
+			u.requested.Memory -= requests.Memory
+			u.requested.Pods -= requests.Pods
+			return
+		}
+	}
+}
+
+// podFitsNode reports whether the scheduler's taint and affinity rules would allow the pod onto the node
+func podFitsNode(pod *models.Pod, node *models.Node) bool {
+	if !pod.MatchesNodeLabels(node.Labels) {
+		return false
+	}
+	for _, taint := range node.Taints {
+		if taint.Effect == models.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !pod.ToleratesTaint(taint) {
+			return false
+		}
+	}
+	return true
+}
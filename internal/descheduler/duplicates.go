@@ -0,0 +1,76 @@
+package descheduler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// removeDuplicates spreads out replicas of the same workload. Pods count as replicas when they share a namespace,
+// image and label set. A node holding more than its even share, counted over the nodes the pods could run on,
+// gives up the excess
+func removeDuplicates(e *evictor, usages []*nodeUsage) {
+	byKey := make(map[string]map[string][]*models.Pod)
+	for _, usage := range usages {
+		for _, pod := range usage.pods {
+			if !e.evictable(pod) {
+				continue
+			}
+			key := duplicateKey(pod)
+			if byKey[key] == nil {
+				byKey[key] = make(map[string][]*models.Pod)
+			}
+			byKey[key][usage.node.Name] = append(byKey[key][usage.node.Name], pod)
+		}
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		podsByNode := byKey[key]
+		total := 0
+		var sample *models.Pod
+		for _, pods := range podsByNode {
+			total += len(pods)
+			sample = pods[0]
+		}
+
+		fitting := 0
+		for _, usage := range usages {
+			if podFitsNode(sample, usage.node) {
+				fitting++
+			}
+		}
+		if fitting < 2 {
+			continue
+		}
+		share := (total + fitting - 1) / fitting
+
+		for _, usage := range usages {
+			pods := podsByNode[usage.node.Name]
+			// sorted by name so repeated passes keep the same replicas in place
+			sort.Slice(pods, func(i, j int) bool { return pods[i].Name < pods[j].Name })
+			for i := share; i < len(pods); i++ {
+				reason := fmt.Sprintf("node runs %d replicas of the same workload, above its share of %d", len(pods), share)
+				if e.evict(pods[i], "RemoveDuplicates", reason) {
+					usage.removePod(pods[i])
+				}
+			}
+		}
+	}
+}
+
+func duplicateKey(pod *models.Pod) string {
+	labels := make([]string, 0, len(pod.Labels))
+	for key, value := range pod.Labels {
+		labels = append(labels, key+"="+value)
+	}
+	sort.Strings(labels)
+	return pod.Namespace + "|" + pod.Image + "|" + strings.Join(labels, ",")
+}
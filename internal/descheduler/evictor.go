@@ -0,0 +1,101 @@
+package descheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const reasonDescheduled = "Descheduled"
+
+// evictor carries out a strategy's evictions while enforcing the policy's limits for a single pass
+type evictor struct {
+	client            *client.Client
+	dryRun            bool
+	maxPerNode        int
+	maxPerRun         int
+	thresholdPriority int32
+
+	total  int
+	byNode map[string]int
+}
+
+func newEvictor(cl *client.Client, policy *Policy, dryRun bool) *evictor {
+	return &evictor{
+		client:            cl,
+		dryRun:            dryRun,
+		maxPerNode:        policy.MaxEvictionsPerNode,
+		maxPerRun:         policy.MaxEvictionsPerRun,
+		thresholdPriority: policy.thresholdPriority(),
+		byNode:            make(map[string]int),
+	}
+}
+
+// evictable reports whether a pod may be moved at all. Pods that are not running yet or already going away are
+// left alone, as are those at or above the priority threshold. Mirror pods are never moved, their kubelet would
+// only publish them again
+func (e *evictor) evictable(pod *models.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.NodeName == "" || pod.IsMirror() {
+		return false
+	}
+	if pod.Phase != models.PodScheduled && pod.Phase != models.PodRunning {
+		return false
+	}
+	return pod.Priority < e.thresholdPriority
+}
+
+// limitReached reports whether the node, or the pass as a whole, has used up its evictions
+func (e *evictor) limitReached(nodeName string) bool {
+	if e.maxPerRun > 0 && e.total >= e.maxPerRun {
+		return true
+	}
+	return e.maxPerNode > 0 && e.byNode[nodeName] >= e.maxPerNode
+}
+
+// evict removes the pod from its node through the eviction subresource, so disruption budgets are respected, and
+// reports whether it was evicted
+func (e *evictor) evict(pod *models.Pod, strategy, reason string) bool {
+	if e.limitReached(pod.NodeName) {
+		return false
+	}
+
+	if e.dryRun {
+		log.Printf("[dry run] Would evict pod %s/%s from node %s (%s): %s", pod.Namespace, pod.Name, pod.NodeName, strategy, reason)
+	} else {
+		if err := e.client.EvictPod(pod.Namespace, pod.Name); err != nil {
+			if errors.Is(err, client.ErrEvictionRefused) {
+				log.Printf("Not evicting pod %s/%s from node %s: %v", pod.Namespace, pod.Name, pod.NodeName, err)
+				return false
+			}
+			log.Printf("Error evicting pod %s/%s from node %s: %v", pod.Namespace, pod.Name, pod.NodeName, err)
+			return false
+		}
+		log.Printf("Evicted pod %s/%s from node %s (%s): %s", pod.Namespace, pod.Name, pod.NodeName, strategy, reason)
+		e.recordEvent(pod, strategy, reason)
+	}
+	e.total++
+	e.byNode[pod.NodeName]++
+	return true
+}
+
+func (e *evictor) recordEvent(pod *models.Pod, strategy, reason string) {
+	event := &models.Event{
+		Name:      fmt.Sprintf("%s.%s", pod.Name, reasonDescheduled),
+		Namespace: pod.Namespace,
+		InvolvedObject: models.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+		Reason:  reasonDescheduled,
+		Message: fmt.Sprintf("Evicted from node %s by %s: %s", pod.NodeName, strategy, reason),
+		Type:    models.EventNormal,
+		Source:  "descheduler",
+	}
+	if err := e.client.RecordEvent(event); err != nil {
+		log.Printf("Error recording %s event for pod %s/%s: %v", reasonDescheduled, pod.Namespace, pod.Name, err)
+	}
+}
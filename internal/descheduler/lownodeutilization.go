@@ -0,0 +1,116 @@
+package descheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// lowNodeUtilization moves pods off overutilized nodes while the underutilized nodes have room for them below the
+// target. Usage is measured by requests, the same figures the scheduler fits pods against
+func lowNodeUtilization(e *evictor, usages []*nodeUsage, args *LowNodeUtilizationArgs) {
+	var underutilized, overutilized []*nodeUsage
+	for _, usage := range usages {
+		switch {
+		case allBelow(usage, args.Thresholds):
+			underutilized = append(underutilized, usage)
+		case anyAbove(usage, args.TargetThresholds):
+			overutilized = append(overutilized, usage)
+		}
+	}
+	if len(underutilized) == 0 || len(overutilized) == 0 {
+		return
+	}
+	log.Printf("LowNodeUtilization found %d underutilized and %d overutilized nodes", len(underutilized), len(overutilized))
+
+	// what the underutilized nodes can still take before they reach the target themselves
+	var headroom models.ResourceList
+	for _, usage := range underutilized {
+		target := thresholdQuantities(usage.node.Capacity, args.TargetThresholds)
+		headroom.CPU += max(target.CPU-usage.requested.CPU, 0)
+		headroom.Memory += max(target.Memory-usage.requested.Memory, 0)
+		headroom.Pods += max(target.Pods-usage.requested.Pods, 0)
+	}
+
+	sort.Slice(overutilized, func(i, j int) bool {
+		return utilization(overutilized[i]) > utilization(overutilized[j])
+	})
+
+	for _, usage := range overutilized {
+		// lower priority pods go first, and among equals the larger ones free more in fewer evictions
+		candidates := make([]*models.Pod, 0, len(usage.pods))
+		for _, pod := range usage.pods {
+			if e.evictable(pod) {
+				candidates = append(candidates, pod)
+			}
+		}
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].Priority != candidates[j].Priority {
+				return candidates[i].Priority < candidates[j].Priority
+			}
+			return candidates[i].Resources.Requests.CPU > candidates[j].Resources.Requests.CPU
+		})
+
+		for _, pod := range candidates {
+			if !anyAbove(usage, args.TargetThresholds) || e.limitReached(usage.node.Name) {
+				break
+			}
+			requests := pod.SchedulingRequests()
+			if requests.CPU > headroom.CPU || requests.Memory > headroom.Memory || requests.Pods > headroom.Pods {
+				continue
+			}
+			reason := fmt.Sprintf("node is above the target utilization (%.0f%%)", utilization(usage))
+			if !e.evict(pod, "LowNodeUtilization", reason) {
+				continue
+			}
+			usage.removePod(pod)
+			headroom.CPU -= requests.CPU
+			headroom.Memory -= requests.Memory
+			headroom.Pods -= requests.Pods
+		}
+	}
+}
+
+// thresholdQuantities converts percentages into absolute amounts of the node's capacity
+func thresholdQuantities(capacity models.ResourceList, thresholds ResourceThresholds) models.ResourceList {
+	return models.ResourceList{
+		CPU:    capacity.CPU * thresholds.CPU / 100,
+		Memory: capacity.Memory * thresholds.Memory / 100,
+		Pods:   capacity.Pods * thresholds.Pods / 100,
+	}
+}
+
+// resources with no capacity reported are unbounded, they never make a node over- or underutilized
+func allBelow(usage *nodeUsage, thresholds ResourceThresholds) bool {
+	limit := thresholdQuantities(usage.node.Capacity, thresholds)
+	capacity := usage.node.Capacity
+	return (capacity.CPU == 0 || usage.requested.CPU < limit.CPU) &&
+		(capacity.Memory == 0 || usage.requested.Memory < limit.Memory) &&
+		(capacity.Pods == 0 || usage.requested.Pods < limit.Pods)
+}
+
+func anyAbove(usage *nodeUsage, thresholds ResourceThresholds) bool {
+	limit := thresholdQuantities(usage.node.Capacity, thresholds)
+	capacity := usage.node.Capacity
+	return (capacity.CPU > 0 && usage.requested.CPU > limit.CPU) ||
+		(capacity.Memory > 0 && usage.requested.Memory > limit.Memory) ||
+		(capacity.Pods > 0 && usage.requested.Pods > limit.Pods)
+}
+
+// utilization is the node's highest usage percentage across its bounded resources
+func utilization(usage *nodeUsage) float64 {
+	var highest float64
+	capacity := usage.node.Capacity
+	for _, pair := range [][2]int64{
+		{usage.requested.CPU, capacity.CPU},
+		{usage.requested.Memory, capacity.Memory},
+		{usage.requested.Pods, capacity.Pods},
+	} {
+		if pair[1] > 0 {
+			highest = max(highest, float64(pair[0])*100/float64(pair[1]))
+		}
+	}
+	return highest
+}
@@ -0,0 +1,99 @@
+package descheduler
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joshL1215/k8s-lite/internal/api/manifest"
+)
+
+// pods at or above this priority are never evicted unless the policy says otherwise
+const DefaultThresholdPriority int32 = 2000000000
+
+// Policy selects the strategies a descheduling pass runs and limits how much each pass may disrupt
+type Policy struct {
+	LowNodeUtilization              *LowNodeUtilizationArgs `json:"lowNodeUtilization,omitempty"`
+	RemoveDuplicates                bool                    `json:"removeDuplicates,omitempty"`
+	RemovePodsViolatingNodeAffinity bool                    `json:"removePodsViolatingNodeAffinity,omitempty"`
+	RemovePodsViolatingNodeTaints   bool                    `json:"removePodsViolatingNodeTaints,omitempty"`
+
+	// zero means no limit
+	MaxEvictionsPerNode int `json:"maxEvictionsPerNode,omitempty"`
+	MaxEvictionsPerRun  int `json:"maxEvictionsPerRun,omitempty"`
+
+	ThresholdPriority *int32 `json:"thresholdPriority,omitempty"`
+}
+
+// ResourceThresholds are percentages of a node's capacity
+type ResourceThresholds struct {
+	CPU    int64 `json:"cpu,omitempty"`
+	Memory int64 `json:"memory,omitempty"`
+	Pods   int64 `json:"pods,omitempty"`
+}
+
+// A node below Thresholds on every resource is underutilized, and one above TargetThresholds on any resource is
+// overutilized. Pods are moved off the overutilized nodes until they drop under the target or the underutilized
+// nodes have no room left below it
+type LowNodeUtilizationArgs struct {
+	Thresholds       ResourceThresholds `json:"thresholds"`
+	TargetThresholds ResourceThresholds `json:"targetThresholds"`
+}
+
+func DefaultPolicy() *Policy {
+	return &Policy{
+		LowNodeUtilization: &LowNodeUtilizationArgs{
+			Thresholds:       ResourceThresholds{CPU: 20, Memory: 20, Pods: 20},
+			TargetThresholds: ResourceThresholds{CPU: 50, Memory: 50, Pods: 50},
+		},
+		RemoveDuplicates:                true,
+		RemovePodsViolatingNodeAffinity: true,
+		RemovePodsViolatingNodeTaints:   true,
+		MaxEvictionsPerNode:             5,
+	}
+}
+
+// LoadPolicy reads a JSON or YAML policy file
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading descheduler policy: %w", err)
+	}
+
+	var policy Policy
+	if err := manifest.Decode(data, &policy); err != nil {
+		return nil, fmt.Errorf("error parsing descheduler policy: %w", err)
+	}
+	if args := policy.LowNodeUtilization; args != nil {
+		if err := validateThresholds(args); err != nil {
+			return nil, err
+		}
+	}
+	return &policy, nil
+}
+
+func validateThresholds(args *LowNodeUtilizationArgs) error {
+	pairs := []struct {
+		name              string
+		threshold, target int64
+	}{
+		{"cpu", args.Thresholds.CPU, args.TargetThresholds.CPU},
+		{"memory", args.Thresholds.Memory, args.TargetThresholds.Memory},
+		{"pods", args.Thresholds.Pods, args.TargetThresholds.Pods},
+	}
+	for _, pair := range pairs {
+		if pair.threshold < 0 || pair.threshold > 100 || pair.target < 0 || pair.target > 100 {
+			return fmt.Errorf("lowNodeUtilization %s thresholds must be between 0 and 100", pair.name)
+		}
+		if pair.threshold > pair.target {
+			return fmt.Errorf("lowNodeUtilization %s threshold %d is above its target %d", pair.name, pair.threshold, pair.target)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) thresholdPriority() int32 {
+	if p.ThresholdPriority != nil {
+		return *p.ThresholdPriority
+	}
+	return DefaultThresholdPriority
+}
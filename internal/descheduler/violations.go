@@ -0,0 +1,66 @@
+package descheduler
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// removePodsViolatingNodeTaints evicts pods from nodes that have since gained a NoSchedule or NoExecute taint the
+// pod does not tolerate
+func removePodsViolatingNodeTaints(e *evictor, usages []*nodeUsage) {
+	for _, usage := range usages {
+		for _, pod := range append([]*models.Pod(nil), usage.pods...) {
+			if !e.evictable(pod) {
+				continue
+			}
+			taint, violated := untoleratedTaint(pod, usage.node)
+			if !violated {
+				continue
+			}
+			reason := fmt.Sprintf("node has taint %s=%s:%s that the pod does not tolerate", taint.Key, taint.Value, taint.Effect)
+			if e.evict(pod, "RemovePodsViolatingNodeTaints", reason) {
+				usage.removePod(pod)
+			}
+		}
+	}
+}
+
+func untoleratedTaint(pod *models.Pod, node *models.Node) (models.Taint, bool) {
+	for _, taint := range node.Taints {
+		if taint.Effect == models.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !pod.ToleratesTaint(taint) {
+			return taint, true
+		}
+	}
+	return models.Taint{}, false
+}
+
+// removePodsViolatingNodeAffinity evicts pods whose node no longer satisfies their nodeSelector or required node
+// affinity, but only when some other ready node does, otherwise the pod would just sit Pending
+func removePodsViolatingNodeAffinity(e *evictor, usages []*nodeUsage) {
+	for _, usage := range usages {
+		for _, pod := range append([]*models.Pod(nil), usage.pods...) {
+			if !e.evictable(pod) || pod.MatchesNodeLabels(usage.node.Labels) {
+				continue
+			}
+			if !anotherNodeFits(pod, usage.node, usages) {
+				continue
+			}
+			if e.evict(pod, "RemovePodsViolatingNodeAffinity", "node no longer matches the pod's node selector or affinity") {
+				usage.removePod(pod)
+			}
+		}
+	}
+}
+
+func anotherNodeFits(pod *models.Pod, current *models.Node, usages []*nodeUsage) bool {
+	for _, usage := range usages {
+		if usage.node.Name != current.Name && podFitsNode(pod, usage.node) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,268 @@
+package kubelet
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// DefaultCgroupRoot is the cgroup v2 directory the kubelet creates pod cgroups under unless configured otherwise
+const DefaultCgroupRoot = "/sys/fs/cgroup/k8s-lite"
+
+// CPU limits are enforced as a quota of run time per period of this many microseconds
+const cpuPeriodMicroseconds = 100000
+
+// the kernel refuses quotas below a millisecond
+const minCPUQuotaMicroseconds = 1000
+
+// a removed cgroup may still hold exiting processes for a moment
+const (
+	cgroupRemoveRetries  = 20
+	cgroupRemoveInterval = 50 * time.Millisecond
+)
+
+// the controllers the kubelet enforces limits with
+var cgroupControllers = []string{"cpu", "memory"}
+
+// cgroupManager keeps a cgroup v2 hierarchy of root/pod_<namespace>_<name>/<container>. Limits are set on the pod
+// cgroup from the pod's resources, and every process of a container, exec'd commands included, is started in the
+// container's cgroup
+type cgroupManager struct {
+	root string
+	// controllers that could be enabled for the pod cgroups, limits for any other are not enforced
+	controllers map[string]bool
+}
+
+// newCgroupManager sets up root for pod cgroups, enabling the controllers it can on the way down from the top of
+// the cgroup v2 mount. It fails when root is not on cgroup v2 or cannot be created
+func newCgroupManager(root string) (*cgroupManager, error) {
+	ok, err := isCgroup2(nearestExisting(root))
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s is not on a cgroup v2 filesystem", root)
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("error creating cgroup %s: %w", root, err)
+	}
+
+	// a directory is part of the hierarchy if it has the cgroup interface files
+	var levels []string
+	for dir := root; ; dir = filepath.Dir(dir) {
+		levels = append([]string{dir}, levels...)
+		parent := filepath.Dir(dir)
+		if parent == dir || !fileExists(filepath.Join(parent, "cgroup.controllers")) {
+			break
+		}
+	}
+	for _, dir := range levels {
+		enableControllers(dir)
+	}
+
+	m := &cgroupManager{root: root, controllers: make(map[string]bool)}
+	for _, controller := range readControllers(filepath.Join(root, "cgroup.subtree_control")) {
+		m.controllers[controller] = true
+	}
+	for _, controller := range cgroupControllers {
+		if !m.controllers[controller] {
+			log.Printf("The %s cgroup controller is not available under %s, its limits will not be enforced", controller, root)
+		}
+	}
+	return m, nil
+}
+
+func (m *cgroupManager) podPath(pod *models.Pod) string {
+	return filepath.Join(m.root, "pod_"+pod.Namespace+"_"+pod.Name)
+}
+
+// createPod creates the pod's cgroup and applies its limits. CPU requests become the pod's weight relative to
+// other pods, CPU and memory limits a hard quota and a memory ceiling
+func (m *cgroupManager) createPod(pod *models.Pod) (string, error) {
+	path := m.podPath(pod)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", fmt.Errorf("error creating cgroup %s: %w", path, err)
+	}
+	enableControllers(path)
+
+	values := make(map[string]string)
+	if m.controllers["cpu"] {
+		values["cpu.weight"] = strconv.FormatInt(cpuWeight(pod.Resources.Requests), 10)
+		values["cpu.max"] = cpuMax(pod.Resources.Limits)
+	}
+	if m.controllers["memory"] {
+		values["memory.max"] = memoryMax(pod.Resources.Limits)
+		// a pod over its limit is killed rather than left to swap
+		if fileExists(filepath.Join(path, "memory.swap.max")) {
+			values["memory.swap.max"] = "0"
+		}
+	}
+	for file, value := range values {
+		if err := os.WriteFile(filepath.Join(path, file), []byte(value), 0o644); err != nil {
+			return path, fmt.Errorf("error setting %s of cgroup %s: %w", file, path, err)
+		}
+	}
+	return path, nil
+}
+
+func (m *cgroupManager) createContainer(podPath, name string) (string, error) {
+	path := filepath.Join(podPath, name)
+	if err := os.MkdirAll(path, 0o755); err != nil {
+		return "", fmt.Errorf("error creating cgroup %s: %w", path, err)
+	}
+	return path, nil
+}
+
+// remove kills whatever is left in a cgroup, which catches processes that left the container's process group,
+// and removes it along with its children
+func (m *cgroupManager) remove(path string) error {
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			if err := m.remove(filepath.Join(path, entry.Name())); err != nil {
+				return err
+			}
+		}
+	}
+
+	os.WriteFile(filepath.Join(path, "cgroup.kill"), []byte("1"), 0o644)
+	for i := 0; ; i++ {
+		err := os.Remove(path)
+		if err == nil || errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		if i == cgroupRemoveRetries {
+			return fmt.Errorf("error removing cgroup %s: %w", path, err)
+		}
+		time.Sleep(cgroupRemoveInterval)
+	}
+}
+
+// cgroupStats is what a cgroup reports about the processes in it, including those in child cgroups
+type cgroupStats struct {
+	cpuUsageMicroseconds uint64
+	memoryUsageBytes     int64
+	oomKills             uint64
+}
+
+func readCgroupStats(path string) (cgroupStats, error) {
+	var stats cgroupStats
+	cpu, err := readKeyedFile(filepath.Join(path, "cpu.stat"))
+	if err != nil {
+		return stats, err
+	}
+	stats.cpuUsageMicroseconds = cpu["usage_usec"]
+
+	// memory files are missing when the controller is not enabled
+	if data, err := os.ReadFile(filepath.Join(path, "memory.current")); err == nil {
+		stats.memoryUsageBytes, _ = strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	}
+	if events, err := readKeyedFile(filepath.Join(path, "memory.events")); err == nil {
+		stats.oomKills = events["oom_kill"]
+	}
+	return stats, nil
+}
+
+// oomKills counts the processes of a cgroup killed for running out of memory so far
+func oomKills(path string) uint64 {
+	events, err := readKeyedFile(filepath.Join(path, "memory.events"))
+	if err != nil {
+		return 0
+	}
+	return events["oom_kill"]
+}
+
+// cpuWeight maps requested millicores onto the 1-10000 weight range the same way as upstream Kubernetes, via the
+// cgroup v1 shares they would have been given
+func cpuWeight(requests models.ResourceList) int64 {
+	const minShares, maxShares = 2, 262144
+	shares := min(max(requests.CPU*1024/1000, minShares), maxShares)
+	return 1 + ((shares-minShares)*9999)/(maxShares-minShares)
+}
+
+func cpuMax(limits models.ResourceList) string {
+	if limits.CPU <= 0 {
+		return fmt.Sprintf("max %d", cpuPeriodMicroseconds)
+	}
+	quota := max(limits.CPU*cpuPeriodMicroseconds/1000, minCPUQuotaMicroseconds)
+	return fmt.Sprintf("%d %d", quota, cpuPeriodMicroseconds)
+}
+
+func memoryMax(limits models.ResourceList) string {
+	if limits.Memory <= 0 {
+		return "max"
+	}
+	return strconv.FormatInt(limits.Memory, 10)
+}
+
+// enableControllers makes the controllers the kubelet uses available to the children of dir, as far as dir has
+// them itself. Failures are left for newCgroupManager to report as missing controllers
+func enableControllers(dir string) {
+	available := readControllers(filepath.Join(dir, "cgroup.controllers"))
+	for _, controller := range cgroupControllers {
+		for _, have := range available {
+			if have == controller {
+				os.WriteFile(filepath.Join(dir, "cgroup.subtree_control"), []byte("+"+controller), 0o644)
+			}
+		}
+	}
+}
+
+func readControllers(path string) []string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+// readKeyedFile parses the "key value" lines of files like cpu.stat and memory.events
+func readKeyedFile(path string) (map[string]uint64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[fields[0]] = value
+		}
+	}
+	return values, scanner.Err()
+}
+
+func nearestExisting(path string) string {
+	for {
+		if fileExists(path) {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,35 @@
+//go:build linux
+
+package kubelet
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+func isCgroup2(path string) (bool, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return false, fmt.Errorf("error inspecting %s: %w", path, err)
+	}
+	return fs.Type == unix.CGROUP2_SUPER_MAGIC, nil
+}
+
+// joinCgroup makes cmd start inside the cgroup at path, so that not even its first instruction runs outside it.
+// The returned directory has to stay open until the command has started
+func joinCgroup(cmd *exec.Cmd, path string) (*os.File, error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening cgroup %s: %w", path, err)
+	}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.UseCgroupFD = true
+	cmd.SysProcAttr.CgroupFD = int(dir.Fd())
+	return dir, nil
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package kubelet
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errNoCgroups = errors.New("cgroups are only supported on Linux")
+
+func isCgroup2(path string) (bool, error) {
+	return false, errNoCgroups
+}
+
+func joinCgroup(cmd *exec.Cmd, path string) (*os.File, error) {
+	return nil, errNoCgroups
+}
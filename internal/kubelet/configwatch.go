@@ -0,0 +1,107 @@
+package kubelet
+
+import (
+	"log"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// projected volumes are also refreshed on this interval, in case a change came while a watch was down
+const projectedVolumeResyncInterval = time.Minute
+
+// RunConfigWatcher keeps the files of config map and secret volumes in line with their objects. Environment
+// variables taken from them are left alone, they only change when a container is started again. The periodic
+// resync also catches downward API volumes up
+func (k *Kubelet) RunConfigWatcher() {
+	var configMaps, secrets <-chan models.WatchEvent
+	ticker := time.NewTicker(projectedVolumeResyncInterval)
+	defer ticker.Stop()
+
+	for {
+		// a watch that ended is opened again on the next resync
+		var err error
+		if configMaps == nil {
+			if configMaps, err = k.Client.WatchConfigMaps(DefaultNamespace); err != nil {
+				log.Printf("Error watching config maps: %v", err)
+			}
+		}
+		if secrets == nil {
+			if secrets, err = k.Client.WatchSecrets(DefaultNamespace); err != nil {
+				log.Printf("Error watching secrets: %v", err)
+			}
+		}
+
+	events:
+		for {
+			select {
+			case event, ok := <-configMaps:
+				if !ok {
+					configMaps = nil
+					continue
+				}
+				// a deleted object leaves its files as they were
+				if event.EventType != models.DeletionEvent {
+					k.refreshProjectedVolumes(event.ConfigMap.Namespace, event.ConfigMap.Name, "")
+				}
+
+			case event, ok := <-secrets:
+				if !ok {
+					secrets = nil
+					continue
+				}
+				if event.EventType != models.DeletionEvent {
+					k.refreshProjectedVolumes(event.Secret.Namespace, "", event.Secret.Name)
+				}
+
+			case <-ticker.C:
+				k.refreshProjectedVolumes("", "", "")
+				break events
+			}
+		}
+	}
+}
+
+// refreshProjectedVolumes writes the files of the set up volumes that project the named config map or secret again.
+// With no namespace given every projected volume is refreshed, downward API volumes included
+func (k *Kubelet) refreshProjectedVolumes(namespace, configMap, secret string) {
+	k.workersMu.Lock()
+	var workers []*podWorker
+	for _, worker := range k.workers {
+		if namespace == "" || worker.currentPod().Namespace == namespace {
+			workers = append(workers, worker)
+		}
+	}
+	k.workersMu.Unlock()
+
+	for _, worker := range workers {
+		worker.refreshVolumes(func(volume models.Volume) bool {
+			switch {
+			case volume.ConfigMap != nil:
+				return namespace == "" || volume.ConfigMap.Name == configMap
+			case volume.Secret != nil:
+				return namespace == "" || volume.Secret.SecretName == secret
+			case volume.DownwardAPI != nil:
+				return namespace == ""
+			}
+			return false
+		})
+	}
+}
+
+// refreshVolumes writes the files of the set up volumes that match again
+func (pw *podWorker) refreshVolumes(match func(models.Volume) bool) {
+	pw.volumesMu.Lock()
+	defer pw.volumesMu.Unlock()
+
+	pod := pw.currentPod()
+	for _, volume := range pod.Volumes {
+		if _, ok := pw.volumes[volume.Name]; !ok || !match(volume) {
+			continue
+		}
+		// setting the volume up again fetches what it projects and swaps in its current files
+		if _, err := pw.setupVolume(volume); err != nil {
+			log.Printf("Error refreshing volume %s of pod %s/%s: %v", volume.Name, pod.Namespace, pod.Name, err)
+		}
+	}
+}
@@ -0,0 +1,509 @@
+package kubelet
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// a container that could not be started is retried after this long
+const containerStartRetryDelay = 10 * time.Second
+
+// Crash loop back-off between restarts of a container that keeps exiting
+const (
+	crashLoopInitialBackOff = 10 * time.Second
+	crashLoopMaxBackOff     = 5 * time.Minute
+	crashLoopResetPeriod    = 10 * time.Minute
+)
+
+// Waiting and termination reasons reported in container statuses
+const (
+	reasonContainerCreating = "ContainerCreating"
+	reasonPodInitializing   = "PodInitializing"
+	reasonConfigError       = "CreateContainerConfigError"
+	reasonRunError          = "RunContainerError"
+	reasonCrashLoopBackOff  = "CrashLoopBackOff"
+	reasonErrImagePull      = "ErrImagePull"
+	reasonImagePullBackOff  = "ImagePullBackOff"
+	reasonErrImageNeverPull = "ErrImageNeverPull"
+	reasonInvalidImageName  = "InvalidImageName"
+	reasonCompleted         = "Completed"
+	reasonError             = "Error"
+	reasonOOMKilled         = "OOMKilled"
+)
+
+var errNoCommand = errors.New("container has no command to run")
+
+// containerWorker runs one container of a pod and the probes watching it
+type containerWorker struct {
+	pw   *podWorker
+	spec models.Container
+	// one of the pod's init containers, which includes its sidecars
+	init bool
+	// empty when the container runs without a cgroup of its own
+	cgroupPath string
+
+	// guarded by pw.mu
+	status models.ContainerStatus
+	proc   *process
+	// the environment of the current run, resolved when it starts
+	env []string
+	// the image of the current run, nil unless the kubelet resolves images
+	image *cachedImage
+	// set once the restart policy rules out another run
+	finished bool
+	// set once the run loop has been started, a container waiting on init containers may never be
+	launched bool
+
+	// closed the first time the container counts as started, and once it has finished
+	startedCh   chan struct{}
+	startedOnce sync.Once
+	finishedCh  chan struct{}
+
+	restartCh chan string
+	stopCh    chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+}
+
+func newContainerWorker(pw *podWorker, spec models.Container, init bool) *containerWorker {
+	reason := reasonContainerCreating
+	if len(pw.pod.InitContainers) > 0 {
+		reason = reasonPodInitializing
+	}
+	return &containerWorker{
+		pw:   pw,
+		spec: spec,
+		init: init,
+		status: models.ContainerStatus{
+			Name:  spec.Name,
+			Image: cmp.Or(spec.Image, pw.pod.Image),
+			State: models.ContainerState{Waiting: &models.ContainerStateWaiting{Reason: reason}},
+		},
+		startedCh:  make(chan struct{}),
+		finishedCh: make(chan struct{}),
+		restartCh:  make(chan string, 1),
+		stopCh:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+}
+
+func (cw *containerWorker) isSidecar() bool {
+	return cw.init && cw.spec.IsSidecar()
+}
+
+// restartPolicy is the pod's restart policy as it applies to this container. Sidecars are always restarted, and
+// an init container is only restarted after a failure, as one that succeeded has done its job
+func (cw *containerWorker) restartPolicy() models.RestartPolicy {
+	policy := cw.pw.currentPod().RestartPolicy
+	switch {
+	case cw.isSidecar():
+		return models.RestartPolicyAlways
+	case cw.init && policy != models.RestartPolicyNever:
+		return models.RestartPolicyOnFailure
+	}
+	return policy
+}
+
+func (cw *containerWorker) containerDir() string {
+	return filepath.Join(cw.pw.podDir, "containers", cw.spec.Name)
+}
+
+func (cw *containerWorker) workingDir() string {
+	if cw.spec.WorkingDir != "" {
+		return cw.spec.WorkingDir
+	}
+	return cw.containerDir()
+}
+
+// environment is what every process of the current run sees, including probes and commands run inside it
+func (cw *containerWorker) environment() []string {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.env
+}
+
+// command is the container's own command, or else its image's entrypoint, followed by its own args or, when it sets
+// neither, its image's cmd
+func (cw *containerWorker) command() []string {
+	command, args := cw.spec.Command, cw.spec.Args
+	if img := cw.currentImage(); img != nil && len(command) == 0 {
+		command = img.Config.Entrypoint
+		if len(args) == 0 {
+			args = img.Config.Cmd
+		}
+	}
+	return append(append([]string(nil), command...), args...)
+}
+
+// run starts the container and restarts it as the pod's restart policy allows until the pod is stopped. Repeated
+// restarts are spaced out by an exponential back-off, and a failed liveness or startup probe counts as a failure
+func (cw *containerWorker) run() {
+	defer close(cw.done)
+	defer cw.setImage(nil)
+	pod := cw.pw.currentPod()
+	var backoff, pullBackoff time.Duration
+
+	// a pull in progress is given up once the container is stopped
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-cw.stopCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		proc, err := cw.startProcess(ctx)
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			reason, message := reasonRunError, err.Error()
+			retryDelay := containerStartRetryDelay
+			switch {
+			case errors.Is(err, errNoCommand):
+				reason = reasonConfigError
+			case errors.Is(err, errCreateConfig):
+				reason = reasonConfigError
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, "Failed", err.Error())
+			case errors.Is(err, errMountFailed):
+				// like upstream, a container waiting on its volumes is still being created
+				reason = reasonContainerCreating
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, "FailedMount", err.Error())
+			case errors.Is(err, errInvalidImageName):
+				reason = reasonInvalidImageName
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, "InspectFailed", err.Error())
+			case errors.Is(err, errImageNeverPull):
+				reason = reasonErrImageNeverPull
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, reasonErrImageNeverPull, err.Error())
+			case errors.Is(err, errImagePull):
+				// like restarts, pulls that keep failing are retried less and less often
+				reason = reasonErrImagePull
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, "Failed", err.Error())
+				if pullBackoff > 0 {
+					reason, message = reasonImagePullBackOff, fmt.Sprintf("Back-off pulling image %q", cw.imageName())
+					cw.pw.kubelet.recordEvent(pod, models.EventNormal, "BackOff", message)
+				}
+				pullBackoff = nextBackOff(pullBackoff)
+				retryDelay = pullBackoff
+			}
+			log.Printf("Error starting container %s of pod %s/%s: %v", cw.spec.Name, pod.Namespace, pod.Name, err)
+			cw.setWaiting(reason, message)
+			select {
+			case <-cw.stopCh:
+				return
+			case <-cw.restartCh:
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		pullBackoff = 0
+		log.Printf("Started container %s of pod %s/%s", cw.spec.Name, pod.Namespace, pod.Name)
+		// the container only counts as running once its post-start hook has passed
+		postStart := cw.startPostStart()
+		probesStop := make(chan struct{})
+
+		var message string
+	running:
+		for {
+			select {
+			case failure := <-postStart:
+				postStart = nil
+				if failure == "" {
+					cw.setRunning(proc)
+					cw.startProbes(proc, probesStop)
+					continue
+				}
+				message = "failed post-start hook"
+				log.Printf("Killing container %s of pod %s/%s: %s", cw.spec.Name, pod.Namespace, pod.Name, message)
+				cw.kill(proc)
+				break running
+
+			case <-proc.done:
+				log.Printf("Container %s of pod %s/%s exited with code %d", cw.spec.Name, pod.Namespace, pod.Name, proc.exitCode)
+				break running
+
+			case message = <-cw.restartCh:
+				log.Printf("Killing container %s of pod %s/%s: %s", cw.spec.Name, pod.Namespace, pod.Name, message)
+				cw.pw.kubelet.recordEvent(pod, models.EventNormal, "Killing", fmt.Sprintf("Container %s %s", cw.spec.Name, message))
+				cw.kill(proc)
+				break running
+
+			case <-cw.stopCh:
+				close(probesStop)
+				cw.kill(proc)
+				log.Printf("Stopped container %s of pod %s/%s", cw.spec.Name, pod.Namespace, pod.Name)
+				cw.setTerminated(proc, "")
+				return
+			}
+		}
+		close(probesStop)
+		cw.setTerminated(proc, message)
+
+		// a container killed by a probe has failed even if it exited cleanly on SIGTERM
+		failed := proc.exitCode != 0 || message != ""
+		if policy := cw.restartPolicy(); !shouldRestart(policy, failed) {
+			log.Printf("Container %s of pod %s/%s will not be restarted under restart policy %s", cw.spec.Name, pod.Namespace, pod.Name, policy)
+			cw.setFinished()
+			<-cw.stopCh
+			return
+		}
+
+		// a container that stayed up long enough has recovered, so its next crash starts the back-off over
+		if proc.finishedAt.Sub(proc.startedAt) >= crashLoopResetPeriod {
+			backoff = 0
+		}
+		if backoff > 0 {
+			log.Printf("Back-off %v restarting container %s of pod %s/%s", backoff, cw.spec.Name, pod.Namespace, pod.Name)
+			cw.setBackOff(backoff)
+			cw.pw.kubelet.recordEvent(pod, models.EventWarning, "BackOff", fmt.Sprintf("Back-off restarting failed container %s", cw.spec.Name))
+			select {
+			case <-cw.stopCh:
+				return
+			case <-time.After(backoff):
+			}
+		}
+		backoff = nextBackOff(backoff)
+		cw.countRestart()
+	}
+}
+
+// shouldRestart applies the pod's restart policy to a container that has exited. Pods that predate restart
+// policies behave as Always
+func shouldRestart(policy models.RestartPolicy, failed bool) bool {
+	switch policy {
+	case models.RestartPolicyNever:
+		return false
+	case models.RestartPolicyOnFailure:
+		return failed
+	default:
+		return true
+	}
+}
+
+// the first restart is immediate, later ones wait twice as long as the last up to crashLoopMaxBackOff
+func nextBackOff(backoff time.Duration) time.Duration {
+	if backoff == 0 {
+		return crashLoopInitialBackOff
+	}
+	return min(2*backoff, crashLoopMaxBackOff)
+}
+
+func (cw *containerWorker) startProcess(ctx context.Context) (*process, error) {
+	img, err := cw.pullImage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cw.setImage(img)
+	if len(cw.command()) == 0 {
+		return nil, errNoCommand
+	}
+	if err := cw.mountVolumes(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMountFailed, err)
+	}
+	env, err := cw.resolveEnv()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCreateConfig, err)
+	}
+	instance := cw.restartCount()
+	removeOldLogs(cw.logDir(), instance)
+	output, err := openContainerLog(cw.logPath(instance), cw.pw.kubelet.ContainerLogMaxSize, cw.pw.kubelet.ContainerLogMaxFiles)
+	if err != nil {
+		return nil, err
+	}
+	cw.pw.mu.Lock()
+	cw.env = env
+	cw.pw.mu.Unlock()
+	argv := cw.expandedCommand(env)
+	argv[0] = cw.lookPath(argv[0], env)
+	return startProcess(argv, cw.workingDir(), env, output, processOptions{stdin: cw.spec.Stdin, tty: cw.spec.TTY, cgroup: cw.cgroupPath})
+}
+
+// each run of the container logs to a file of its own, named after the restart count it ran under
+func (cw *containerWorker) logDir() string {
+	return filepath.Join(cw.pw.podDir, "logs", cw.spec.Name)
+}
+
+func (cw *containerWorker) logPath(instance int32) string {
+	return filepath.Join(cw.logDir(), fmt.Sprintf("%d.log", instance))
+}
+
+func (cw *containerWorker) restartCount() int32 {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.status.RestartCount
+}
+
+// running reports whether the given run of the container is still going
+func (cw *containerWorker) running(instance int32) bool {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.status.RestartCount == instance && cw.status.State.Running != nil
+}
+
+// stop ends the container for good and waits for it. A container that was never launched has nothing to wait for
+func (cw *containerWorker) stop() {
+	cw.pw.mu.Lock()
+	launched := cw.launched
+	cw.pw.mu.Unlock()
+	cw.stopOnce.Do(func() { close(cw.stopCh) })
+	if launched {
+		<-cw.done
+	}
+}
+
+// requestRestart asks the run loop to kill and restart the container, unless a restart is already pending
+func (cw *containerWorker) requestRestart(message string) {
+	select {
+	case cw.restartCh <- message:
+	default:
+	}
+}
+
+func (cw *containerWorker) setWaiting(reason, message string) {
+	cw.pw.mu.Lock()
+	cw.status.State = models.ContainerState{Waiting: &models.ContainerStateWaiting{Reason: reason, Message: message}}
+	cw.status.Ready = false
+	cw.status.Started = false
+	cw.pw.mu.Unlock()
+	cw.pw.requestStatusSync()
+}
+
+// setRunning marks the container started and ready straight away unless a probe has to pass first
+func (cw *containerWorker) setRunning(proc *process) {
+	cw.pw.mu.Lock()
+	cw.proc = proc
+	cw.status.State = models.ContainerState{Running: &models.ContainerStateRunning{StartedAt: proc.startedAt}}
+	cw.status.Started = cw.spec.StartupProbe == nil
+	cw.status.Ready = cw.status.Started && cw.spec.ReadinessProbe == nil
+	if cw.status.Started {
+		cw.startedOnce.Do(func() { close(cw.startedCh) })
+	}
+	cw.pw.mu.Unlock()
+	cw.pw.requestStatusSync()
+}
+
+func (cw *containerWorker) setTerminated(proc *process, message string) {
+	reason := reasonCompleted
+	if proc.oomKilled {
+		reason = reasonOOMKilled
+	} else if proc.exitCode != 0 {
+		reason = reasonError
+	}
+	cw.pw.mu.Lock()
+	cw.proc = nil
+	cw.status.State = models.ContainerState{Terminated: &models.ContainerStateTerminated{
+		ExitCode:   proc.exitCode,
+		Signal:     proc.signal,
+		Reason:     reason,
+		Message:    message,
+		StartedAt:  proc.startedAt,
+		FinishedAt: proc.finishedAt,
+	}}
+	cw.status.Ready = false
+	cw.status.Started = false
+	cw.pw.mu.Unlock()
+	cw.pw.requestStatusSync()
+}
+
+// setBackOff keeps the last termination visible while the container waits out its back-off
+func (cw *containerWorker) setBackOff(backoff time.Duration) {
+	pod := cw.pw.currentPod()
+	cw.pw.mu.Lock()
+	if cw.status.State.Terminated != nil {
+		cw.status.LastTerminationState = cw.status.State
+	}
+	cw.status.State = models.ContainerState{Waiting: &models.ContainerStateWaiting{
+		Reason:  reasonCrashLoopBackOff,
+		Message: fmt.Sprintf("back-off %v restarting failed container=%s pod=%s_%s", backoff, cw.spec.Name, pod.Name, pod.Namespace),
+	}}
+	cw.pw.mu.Unlock()
+	cw.pw.requestStatusSync()
+}
+
+// countRestart is called right before the container is started again
+func (cw *containerWorker) countRestart() {
+	cw.pw.mu.Lock()
+	if cw.status.State.Terminated != nil {
+		cw.status.LastTerminationState = cw.status.State
+	}
+	cw.status.RestartCount++
+	cw.pw.mu.Unlock()
+}
+
+func (cw *containerWorker) setFinished() {
+	cw.pw.mu.Lock()
+	cw.finished = true
+	cw.pw.mu.Unlock()
+	close(cw.finishedCh)
+	cw.pw.requestStatusSync()
+	if !cw.init {
+		cw.pw.containerFinished()
+	}
+}
+
+// succeeded reports whether the container's last run exited cleanly
+func (cw *containerWorker) succeeded() bool {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	terminated := cw.status.State.Terminated
+	return terminated != nil && terminated.ExitCode == 0
+}
+
+// hasStarted reports whether the container has counted as started at least once
+func (cw *containerWorker) hasStarted() bool {
+	select {
+	case <-cw.startedCh:
+		return true
+	default:
+		return false
+	}
+}
+
+func (cw *containerWorker) isStarted() bool {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.status.Started
+}
+
+// probe results only apply to the run of the container they were taken against
+
+// setStarted records a passed startup probe, which also makes the container ready if it has no readiness probe
+func (cw *containerWorker) setStarted(proc *process) {
+	cw.pw.mu.Lock()
+	if cw.proc != proc {
+		cw.pw.mu.Unlock()
+		return
+	}
+	cw.status.Started = true
+	if cw.spec.ReadinessProbe == nil {
+		cw.status.Ready = true
+	}
+	cw.startedOnce.Do(func() { close(cw.startedCh) })
+	cw.pw.mu.Unlock()
+	cw.pw.requestStatusSync()
+}
+
+func (cw *containerWorker) setReady(proc *process, ready bool) {
+	cw.pw.mu.Lock()
+	if cw.proc != proc {
+		cw.pw.mu.Unlock()
+		return
+	}
+	changed := cw.status.Ready != ready
+	cw.status.Ready = ready
+	cw.pw.mu.Unlock()
+	if changed {
+		cw.pw.requestStatusSync()
+	}
+}
@@ -0,0 +1,134 @@
+package kubelet
+
+import (
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// nodeIPFor picks the IP the node is reached at from its registered address, resolving a host name. An address that
+// cannot be resolved leaves the IP empty
+func nodeIPFor(nodeAddress string) string {
+	u, err := url.Parse(nodeAddress)
+	if err != nil || u.Hostname() == "" {
+		return ""
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil {
+		return ip.String()
+	}
+	ips, err := net.LookupIP(u.Hostname())
+	if err != nil || len(ips) == 0 {
+		return ""
+	}
+	for _, ip := range ips {
+		if ip.To4() != nil {
+			return ip.String()
+		}
+	}
+	return ips[0].String()
+}
+
+// allocatable is what the node offers pods, with unbounded memory read as all of the node's memory
+func (k *Kubelet) allocatable() models.ResourceList {
+	allocatable := k.Capacity
+	if allocatable.Memory == 0 {
+		if _, total, err := memoryAvailable(); err == nil {
+			allocatable.Memory = total
+		}
+	}
+	return allocatable
+}
+
+// downwardPod is the pod as the downward API exposes it. Its addresses are filled in before the first status sync
+// has written them
+func (pw *podWorker) downwardPod() *models.Pod {
+	pod := *pw.currentPod()
+	if pod.HostIP == "" {
+		pod.HostIP = pw.kubelet.NodeIP
+	}
+	if pod.PodIP == "" {
+		pod.PodIP = pw.kubelet.NodeIP
+	}
+	return &pod
+}
+
+// downwardValue resolves a field or resource reference against the pod
+func (pw *podWorker) downwardValue(fieldRef *models.ObjectFieldSelector, resourceFieldRef *models.ResourceFieldSelector) (string, error) {
+	pod := pw.downwardPod()
+	if fieldRef != nil {
+		return models.ExtractFieldPath(pod, fieldRef.FieldPath)
+	}
+	if resourceFieldRef != nil {
+		return models.ExtractResourceValue(pod, resourceFieldRef, pw.kubelet.allocatable())
+	}
+	return "", fmt.Errorf("no field or resource reference set")
+}
+
+func (pw *podWorker) setupDownwardAPIVolume(name string, source *models.DownwardAPIVolumeSource) (string, error) {
+	mode := os.FileMode(models.DefaultProjectedFileMode)
+	if source.DefaultMode != nil {
+		mode = os.FileMode(*source.DefaultMode)
+	}
+	files := make(map[string]projectedFile, len(source.Items))
+	for _, item := range source.Items {
+		value, err := pw.downwardValue(item.FieldRef, item.ResourceFieldRef)
+		if err != nil {
+			return "", fmt.Errorf("item %s: %w", item.Path, err)
+		}
+		file := projectedFile{data: []byte(value), mode: mode}
+		if item.Mode != nil {
+			file.mode = os.FileMode(*item.Mode)
+		}
+		files[filepath.Clean(item.Path)] = file
+	}
+	dir := pw.volumeDir("downward-api", name)
+	return dir, writeProjection(dir, files)
+}
+
+// metadataChanged reports whether the labels or annotations the downward API volumes show differ between two
+// copies of the pod
+func metadataChanged(old, new *models.Pod) bool {
+	return !maps.Equal(old.Labels, new.Labels) || !maps.Equal(old.Annotations, new.Annotations)
+}
+
+// expandVars replaces $(NAME) in s with the variable's value. $$ is an escaped $, and a reference to a variable
+// lookup does not know is left as it is, as upstream
+func expandVars(s string, lookup func(name string) (string, bool)) string {
+	if !strings.Contains(s, "$") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '$' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		switch s[i+1] {
+		case '$':
+			b.WriteByte('$')
+			i++
+		case '(':
+			end := strings.IndexByte(s[i+2:], ')')
+			if end < 0 {
+				b.WriteString(s[i:])
+				return b.String()
+			}
+			reference := s[i : i+3+end]
+			if value, ok := lookup(s[i+2 : i+2+end]); ok {
+				b.WriteString(value)
+			} else {
+				b.WriteString(reference)
+			}
+			i += 2 + end
+		default:
+			b.WriteByte('$')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,176 @@
+package kubelet
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+// errCreateConfig marks a container that cannot start because the config maps or secrets its environment refers
+// to are missing
+var errCreateConfig = errors.New("unable to resolve container environment")
+
+// keys of a config map or secret that are not valid variable names are left out of envFrom
+var envVarName = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// resolveEnv builds the environment of the next run of the container. The image's variables come first, then those
+// from envFrom so env can override them, and config maps and secrets are fetched once per run
+func (cw *containerWorker) resolveEnv() ([]string, error) {
+	pod := cw.pw.currentPod()
+	configMaps := make(map[string]*models.ConfigMap)
+	secrets := make(map[string]*models.Secret)
+
+	getConfigMap := func(name string) (*models.ConfigMap, error) {
+		if cm, ok := configMaps[name]; ok {
+			return cm, nil
+		}
+		cm, err := cw.pw.kubelet.Client.GetConfigMap(pod.Namespace, name)
+		if err != nil && !errors.Is(err, store.ErrConfigMapNotExist) {
+			return nil, fmt.Errorf("error fetching config map %s: %w", name, err)
+		}
+		configMaps[name] = cm
+		return cm, nil
+	}
+	getSecret := func(name string) (*models.Secret, error) {
+		if secret, ok := secrets[name]; ok {
+			return secret, nil
+		}
+		secret, err := cw.pw.kubelet.Client.GetSecret(pod.Namespace, name)
+		if err != nil && !errors.Is(err, store.ErrSecretNotExist) {
+			return nil, fmt.Errorf("error fetching secret %s: %w", name, err)
+		}
+		secrets[name] = secret
+		return secret, nil
+	}
+
+	defined := make(map[string]string)
+	var env []string
+	set := func(name, value string) {
+		defined[name] = value
+		env = append(env, name+"="+value)
+	}
+	set("PATH", os.Getenv("PATH"))
+	set("HOSTNAME", pod.Name)
+	if img := cw.currentImage(); img != nil {
+		for _, entry := range img.Config.Env {
+			name, value, _ := strings.Cut(entry, "=")
+			set(name, value)
+		}
+	}
+	for _, source := range cw.spec.EnvFrom {
+		var data map[string]string
+		switch {
+		case source.ConfigMapRef != nil:
+			cm, err := getConfigMap(source.ConfigMapRef.Name)
+			if err != nil {
+				return nil, err
+			}
+			if cm == nil {
+				if source.ConfigMapRef.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("config map %s not found", source.ConfigMapRef.Name)
+			}
+			data = cm.Data
+
+		case source.SecretRef != nil:
+			secret, err := getSecret(source.SecretRef.Name)
+			if err != nil {
+				return nil, err
+			}
+			if secret == nil {
+				if source.SecretRef.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("secret %s not found", source.SecretRef.Name)
+			}
+			data = make(map[string]string, len(secret.Data))
+			for key, value := range secret.Data {
+				data[key] = string(value)
+			}
+		}
+		for key, value := range data {
+			name := source.Prefix + key
+			if !envVarName.MatchString(name) {
+				log.Printf("Skipping key %s of container %s of pod %s/%s, %s is not a valid variable name", key, cw.spec.Name, pod.Namespace, pod.Name, name)
+				continue
+			}
+			set(name, value)
+		}
+	}
+
+	for _, envVar := range cw.spec.Env {
+		if envVar.ValueFrom == nil {
+			set(envVar.Name, expandVars(envVar.Value, lookup(defined)))
+			continue
+		}
+		var value string
+		var found bool
+		switch ref := envVar.ValueFrom; {
+		case ref.ConfigMapKeyRef != nil:
+			cm, err := getConfigMap(ref.ConfigMapKeyRef.Name)
+			if err != nil {
+				return nil, err
+			}
+			if cm != nil {
+				value, found = cm.Data[ref.ConfigMapKeyRef.Key]
+			}
+			if !found && !ref.ConfigMapKeyRef.Optional {
+				return nil, fmt.Errorf("variable %s: key %s of config map %s not found", envVar.Name, ref.ConfigMapKeyRef.Key, ref.ConfigMapKeyRef.Name)
+			}
+
+		case ref.SecretKeyRef != nil:
+			secret, err := getSecret(ref.SecretKeyRef.Name)
+			if err != nil {
+				return nil, err
+			}
+			if secret != nil {
+				var data []byte
+				data, found = secret.Data[ref.SecretKeyRef.Key]
+				value = string(data)
+			}
+			if !found && !ref.SecretKeyRef.Optional {
+				return nil, fmt.Errorf("variable %s: key %s of secret %s not found", envVar.Name, ref.SecretKeyRef.Key, ref.SecretKeyRef.Name)
+			}
+
+		default:
+			var err error
+			if value, err = cw.pw.downwardValue(ref.FieldRef, ref.ResourceFieldRef); err != nil {
+				return nil, fmt.Errorf("variable %s: %w", envVar.Name, err)
+			}
+			found = true
+		}
+		// an optional reference that is missing leaves the variable unset
+		if found {
+			set(envVar.Name, value)
+		}
+	}
+	return env, nil
+}
+
+func lookup(defined map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		value, ok := defined[name]
+		return value, ok
+	}
+}
+
+// expandedCommand expands the container's command and args against the environment of the run
+func (cw *containerWorker) expandedCommand(env []string) []string {
+	defined := make(map[string]string, len(env))
+	for _, entry := range env {
+		name, value, _ := strings.Cut(entry, "=")
+		defined[name] = value
+	}
+	argv := cw.command()
+	for i, arg := range argv {
+		argv[i] = expandVars(arg, lookup(defined))
+	}
+	return argv
+}
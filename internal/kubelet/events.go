@@ -0,0 +1,28 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// each pod keeps one event per reason, so repeats such as failing probes bump a count instead of piling up events
+func (k *Kubelet) recordEvent(pod *models.Pod, severity models.EventSeverity, reason, message string) {
+	event := &models.Event{
+		Name:      fmt.Sprintf("%s.%s", pod.Name, reason),
+		Namespace: pod.Namespace,
+		InvolvedObject: models.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+		Reason:  reason,
+		Message: message,
+		Type:    severity,
+		Source:  "kubelet/" + k.NodeName,
+	}
+	if err := k.Client.RecordEvent(event); err != nil {
+		log.Printf("Error recording %s event for pod %s/%s: %v", reason, pod.Namespace, pod.Name, err)
+	}
+}
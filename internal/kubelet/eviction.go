@@ -0,0 +1,456 @@
+package kubelet
+
+import (
+	"bufio"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// Eviction signals, each the amount of a node resource still available
+type EvictionSignal string
+
+const (
+	SignalMemoryAvailable EvictionSignal = "memory.available"
+	SignalNodeFsAvailable EvictionSignal = "nodefs.available"
+)
+
+// Defaults for node-pressure eviction
+const (
+	DefaultEvictionHard                     = "memory.available<100Mi,nodefs.available<10%"
+	DefaultEvictionMonitoringPeriod         = 10 * time.Second
+	DefaultEvictionPressureTransitionPeriod = 5 * time.Minute
+	DefaultEvictionMaxPodGracePeriod        = 30 * time.Second
+)
+
+// pods failed by the kubelet for node pressure carry this reason
+const reasonEvicted = "Evicted"
+
+// EvictionThreshold is met once the signal drops below Quantity bytes or, if Percentage is set, below that share
+// of the resource's capacity. A hard threshold evicts straight away and kills pods without a grace period, a soft
+// one only once it has been met for GracePeriod
+type EvictionThreshold struct {
+	Signal      EvictionSignal
+	Quantity    int64
+	Percentage  float64
+	GracePeriod time.Duration
+	Soft        bool
+}
+
+func (t EvictionThreshold) String() string {
+	if t.Percentage > 0 {
+		return fmt.Sprintf("%s<%g%%", t.Signal, t.Percentage)
+	}
+	return fmt.Sprintf("%s<%d", t.Signal, t.Quantity)
+}
+
+// value is the threshold in bytes for a resource of the given capacity
+func (t EvictionThreshold) value(capacity int64) int64 {
+	if t.Percentage > 0 {
+		return int64(float64(capacity) * t.Percentage / 100)
+	}
+	return t.Quantity
+}
+
+// ParseEvictionThresholds reads thresholds of the form signal<quantity, separated by commas, where the quantity is a
+// number of bytes with an optional Ki, Mi, Gi or Ti suffix or a percentage. Soft thresholds take their grace
+// period from gracePeriods, given as signal=duration pairs
+func ParseEvictionThresholds(spec string, soft bool, gracePeriods string) ([]EvictionThreshold, error) {
+	graces := make(map[EvictionSignal]time.Duration)
+	for _, pair := range splitList(gracePeriods) {
+		signal, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("grace period %q is not of the form signal=duration", pair)
+		}
+		grace, err := time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			return nil, fmt.Errorf("grace period %q has an invalid duration", pair)
+		}
+		graces[EvictionSignal(signal)] = grace
+	}
+
+	var thresholds []EvictionThreshold
+	for _, item := range splitList(spec) {
+		signal, value, ok := strings.Cut(item, "<")
+		if !ok {
+			return nil, fmt.Errorf("threshold %q is not of the form signal<quantity", item)
+		}
+		threshold := EvictionThreshold{Signal: EvictionSignal(signal), Soft: soft}
+		switch threshold.Signal {
+		case SignalMemoryAvailable, SignalNodeFsAvailable:
+		default:
+			return nil, fmt.Errorf("threshold %q has unknown signal %s", item, signal)
+		}
+
+		if percentage, ok := strings.CutSuffix(value, "%"); ok {
+			parsed, err := strconv.ParseFloat(percentage, 64)
+			if err != nil || parsed <= 0 || parsed > 100 {
+				return nil, fmt.Errorf("threshold %q has an invalid percentage", item)
+			}
+			threshold.Percentage = parsed
+		} else {
+			quantity, err := parseBytes(value)
+			if err != nil {
+				return nil, fmt.Errorf("threshold %q: %w", item, err)
+			}
+			threshold.Quantity = quantity
+		}
+
+		if soft {
+			grace, ok := graces[threshold.Signal]
+			if !ok {
+				return nil, fmt.Errorf("soft threshold %q has no grace period", item)
+			}
+			threshold.GracePeriod = grace
+		}
+		thresholds = append(thresholds, threshold)
+	}
+	return thresholds, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseBytes(value string) (int64, error) {
+	multiplier := int64(1)
+	for i, suffix := range []string{"Ki", "Mi", "Gi", "Ti"} {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			value = number
+			multiplier = 1 << (10 * (i + 1))
+			break
+		}
+	}
+	quantity, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || quantity < 0 {
+		return 0, fmt.Errorf("invalid quantity %q", value)
+	}
+	return quantity * multiplier, nil
+}
+
+// signalObservation is how much of a resource is available out of its capacity
+type signalObservation struct {
+	available int64
+	capacity  int64
+}
+
+// evictionManager watches the node's resources and evicts pods while a threshold is met
+type evictionManager struct {
+	kubelet *Kubelet
+
+	// when each soft threshold was first seen met in its current stretch
+	firstObserved map[EvictionThreshold]time.Time
+	// when each node condition last had a threshold met
+	lastPressure map[models.NodeConditionType]time.Time
+}
+
+// the node condition a met threshold of each signal puts the node under
+var signalConditions = map[EvictionSignal]models.NodeConditionType{
+	SignalMemoryAvailable: models.NodeMemoryPressure,
+	SignalNodeFsAvailable: models.NodeDiskPressure,
+}
+
+// pressureConditions are what the kubelet reports for each condition, with and without pressure
+var pressureConditions = []struct {
+	conditionType models.NodeConditionType
+	taintKey      string
+	reason        string
+	message       string
+	reliefReason  string
+	reliefMessage string
+}{
+	{models.NodeMemoryPressure, models.TaintNodeMemoryPressure, "KubeletHasInsufficientMemory", "kubelet has insufficient memory available", "KubeletHasSufficientMemory", "kubelet has sufficient memory available"},
+	{models.NodeDiskPressure, models.TaintNodeDiskPressure, "KubeletHasDiskPressure", "kubelet has disk pressure", "KubeletHasNoDiskPressure", "kubelet has no disk pressure"},
+}
+
+// RunEvictionManager checks the eviction thresholds every EvictionMonitoringPeriod until the process exits
+func (k *Kubelet) RunEvictionManager() {
+	if len(k.EvictionThresholds) == 0 {
+		return
+	}
+	m := &evictionManager{
+		kubelet:       k,
+		firstObserved: make(map[EvictionThreshold]time.Time),
+		lastPressure:  make(map[models.NodeConditionType]time.Time),
+	}
+	log.Printf("Monitoring eviction thresholds %v every %v", k.EvictionThresholds, k.EvictionMonitoringPeriod)
+
+	ticker := time.NewTicker(k.EvictionMonitoringPeriod)
+	defer ticker.Stop()
+	for range ticker.C {
+		m.synchronize()
+	}
+}
+
+// synchronize updates the node's pressure conditions and evicts at most one pod, so the next round sees what the
+// eviction freed before deciding on another
+func (m *evictionManager) synchronize() {
+	k := m.kubelet
+	observations := m.observe()
+	now := time.Now()
+
+	var evictFor *EvictionThreshold
+	var evictObservation signalObservation
+	for _, threshold := range k.EvictionThresholds {
+		observation, ok := observations[threshold.Signal]
+		if !ok || observation.available >= threshold.value(observation.capacity) {
+			delete(m.firstObserved, threshold)
+			continue
+		}
+
+		// a threshold counts towards pressure as soon as it is met, but only evicts once its grace period is over
+		m.lastPressure[signalConditions[threshold.Signal]] = now
+		if threshold.Soft {
+			first, ok := m.firstObserved[threshold]
+			if !ok {
+				m.firstObserved[threshold] = now
+				first = now
+			}
+			if now.Sub(first) < threshold.GracePeriod {
+				continue
+			}
+		}
+		if evictFor == nil || (evictFor.Soft && !threshold.Soft) {
+			evictFor, evictObservation = &threshold, observation
+		}
+	}
+
+	m.updateNodeConditions(now)
+	if evictFor != nil {
+		// unused images go before any pod does
+		if evictFor.Signal == SignalNodeFsAvailable && m.reclaimImages(*evictFor, evictObservation) {
+			return
+		}
+		m.evictOne(*evictFor, evictObservation)
+	}
+}
+
+// reclaimImages removes unused images to relieve disk pressure and reports whether that freed enough that no pod
+// has to be evicted, which the next round confirms
+func (m *evictionManager) reclaimImages(threshold EvictionThreshold, observation signalObservation) bool {
+	images := m.kubelet.images
+	if images == nil {
+		return false
+	}
+	needed := threshold.value(observation.capacity) - observation.available
+	freed := images.freeSpace(needed, 0)
+	if freed > 0 {
+		log.Printf("Removed %d bytes of unused images to reclaim %s", freed, threshold.Signal)
+	}
+	return freed >= needed
+}
+
+func (m *evictionManager) observe() map[EvictionSignal]signalObservation {
+	observations := make(map[EvictionSignal]signalObservation)
+	if available, capacity, err := memoryAvailable(); err == nil {
+		observations[SignalMemoryAvailable] = signalObservation{available: available, capacity: capacity}
+	}
+	if available, capacity, err := filesystemAvailable(m.kubelet.RootDir); err == nil {
+		observations[SignalNodeFsAvailable] = signalObservation{available: available, capacity: capacity}
+	}
+	return observations
+}
+
+// memoryAvailable reads the node's available and total memory from /proc/meminfo
+func memoryAvailable() (int64, int64, error) {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 {
+			kilobytes, _ := strconv.ParseInt(fields[1], 10, 64)
+			values[strings.TrimSuffix(fields[0], ":")] = kilobytes * 1024
+		}
+	}
+	available, ok := values["MemAvailable"]
+	if !ok {
+		return 0, 0, fmt.Errorf("MemAvailable missing from /proc/meminfo")
+	}
+	return available, values["MemTotal"], nil
+}
+
+// updateNodeConditions reports pressure until a resource has gone a whole transition period without a threshold
+// met, so the node does not flap in and out of pressure around a threshold. Nodes under pressure are also tainted
+// so that no further pods are scheduled onto them
+func (m *evictionManager) updateNodeConditions(now time.Time) {
+	k := m.kubelet
+	node, err := k.Client.GetNode(k.NodeName)
+	if err != nil {
+		log.Printf("Error fetching node %s to update its conditions: %v", k.NodeName, err)
+		return
+	}
+
+	changed := false
+	for _, pc := range pressureConditions {
+		last, ok := m.lastPressure[pc.conditionType]
+		pressure := ok && now.Sub(last) < k.EvictionPressureTransitionPeriod
+
+		condition := models.NodeCondition{Type: pc.conditionType, Status: models.ConditionFalse, Reason: pc.reliefReason, Message: pc.reliefMessage}
+		if pressure {
+			condition = models.NodeCondition{Type: pc.conditionType, Status: models.ConditionTrue, Reason: pc.reason, Message: pc.message}
+		}
+		if node.SetCondition(condition) {
+			changed = true
+			if pressure {
+				log.Printf("Node %s is under %s", k.NodeName, pc.conditionType)
+			} else if ok {
+				log.Printf("Node %s is no longer under %s", k.NodeName, pc.conditionType)
+			}
+		}
+
+		taint := models.Taint{Key: pc.taintKey, Effect: models.TaintEffectNoSchedule}
+		index := slices.IndexFunc(node.Taints, func(t models.Taint) bool { return t.Key == taint.Key })
+		switch {
+		case pressure && index < 0:
+			node.Taints = append(node.Taints, taint)
+			changed = true
+		case !pressure && index >= 0:
+			node.Taints = slices.Delete(node.Taints, index, index+1)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if _, err := k.Client.UpdateNode(node); err != nil {
+		log.Printf("Error updating conditions of node %s: %v", k.NodeName, err)
+	}
+}
+
+// evictOne fails the first pod in eviction order to reclaim the threshold's resource
+func (m *evictionManager) evictOne(threshold EvictionThreshold, observation signalObservation) {
+	k := m.kubelet
+	candidates := m.rankPods(threshold.Signal)
+	if len(candidates) == 0 {
+		log.Printf("Eviction threshold %v is met but there are no pods left to evict", threshold)
+		return
+	}
+
+	resource := "memory"
+	if threshold.Signal == SignalNodeFsAvailable {
+		resource = "ephemeral-storage"
+	}
+	message := fmt.Sprintf("The node was low on resource: %s. Threshold quantity: %d, available: %d.", resource, threshold.value(observation.capacity), observation.available)
+	grace := time.Duration(0)
+	if threshold.Soft {
+		grace = k.EvictionMaxPodGracePeriod
+	}
+	k.evictPod(candidates[0].worker, grace, message)
+}
+
+// evictionCandidate is a running pod together with how far its use of the resource goes beyond what it requested
+type evictionCandidate struct {
+	worker        *podWorker
+	pod           *models.Pod
+	overRequested int64
+}
+
+// rankPods orders the node's pods for eviction: BestEffort before Burstable before Guaranteed, lower priority
+// first within a class and then the pods using the most beyond their requests
+func (m *evictionManager) rankPods(signal EvictionSignal) []evictionCandidate {
+	k := m.kubelet
+	k.workersMu.Lock()
+	workers := make([]*podWorker, 0, len(k.workers))
+	for _, pw := range k.workers {
+		workers = append(workers, pw)
+	}
+	k.workersMu.Unlock()
+
+	var candidates []evictionCandidate
+	for _, pw := range workers {
+		// a static pod would only be started again from its manifest
+		if pw.isTerminating() || pw.static {
+			continue
+		}
+		pod := pw.currentPod()
+		candidate := evictionCandidate{worker: pw, pod: pod}
+		switch signal {
+		case SignalMemoryAvailable:
+			if stats, err := pw.podStats(); err == nil {
+				candidate.overRequested = stats.MemoryUsageBytes - pod.Resources.Requests.Memory
+			}
+		case SignalNodeFsAvailable:
+			candidate.overRequested = directorySize(pw.podDir)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	qosRank := map[models.PodQOSClass]int{models.PodQOSBestEffort: 0, models.PodQOSBurstable: 1, models.PodQOSGuaranteed: 2}
+	slices.SortStableFunc(candidates, func(a, b evictionCandidate) int {
+		if qa, qb := qosRank[a.pod.QOSClass()], qosRank[b.pod.QOSClass()]; qa != qb {
+			return qa - qb
+		}
+		if a.pod.Priority != b.pod.Priority {
+			if a.pod.Priority < b.pod.Priority {
+				return -1
+			}
+			return 1
+		}
+		switch {
+		case a.overRequested > b.overRequested:
+			return -1
+		case a.overRequested < b.overRequested:
+			return 1
+		}
+		return 0
+	})
+	return candidates
+}
+
+// directorySize is the space taken by the files under dir, which for a pod is its logs and its containers' files
+func directorySize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.Type().IsRegular() {
+			if info, err := entry.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// evictPod stops the pod's containers within the given grace period and fails it with reason Evicted. It returns
+// once the containers are gone
+func (k *Kubelet) evictPod(pw *podWorker, grace time.Duration, message string) {
+	if !pw.beginTermination() {
+		return
+	}
+	pod := pw.currentPod()
+	log.Printf("Evicting pod %s/%s: %s", pod.Namespace, pod.Name, message)
+	k.recordEvent(pod, models.EventWarning, reasonEvicted, message)
+
+	pw.fail(reasonEvicted, message, grace)
+	pw.terminate()
+	pw.syncStatus(false)
+
+	k.workersMu.Lock()
+	if k.workers[podKey(pod)] == pw {
+		delete(k.workers, podKey(pod))
+	}
+	k.workersMu.Unlock()
+}
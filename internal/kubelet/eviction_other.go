@@ -0,0 +1,9 @@
+//go:build !unix
+
+package kubelet
+
+import "errors"
+
+func filesystemAvailable(path string) (int64, int64, error) {
+	return 0, 0, errors.New("filesystem usage is not available on this platform")
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package kubelet
+
+import "golang.org/x/sys/unix"
+
+// filesystemAvailable reports the space left to unprivileged users and the size of the filesystem holding path
+func filesystemAvailable(path string) (int64, int64, error) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(path, &fs); err != nil {
+		return 0, 0, err
+	}
+	return int64(fs.Bavail) * int64(fs.Bsize), int64(fs.Blocks) * int64(fs.Bsize), nil
+}
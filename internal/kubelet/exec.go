@@ -0,0 +1,347 @@
+package kubelet
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+)
+
+// an exec'd command's output is waited on for at most this long after it exits, in case something it spawned
+// still holds it open
+const execOutputWaitDelay = time.Second
+
+// exit code reported when an exec'd command could not be started at all
+const execStartFailedExitCode = 126
+
+// typed at a terminal to signal end-of-file
+const eotCharacter = 0x04
+
+// streamOptions are the streams a client asked for on exec or attach. With a tty, stderr is merged into stdout
+type streamOptions struct {
+	stdin  bool
+	stdout bool
+	stderr bool
+	tty    bool
+}
+
+func parseStreamOptions(query func(string) string) (streamOptions, error) {
+	var opts streamOptions
+	for name, field := range map[string]*bool{"stdin": &opts.stdin, "stdout": &opts.stdout, "stderr": &opts.stderr, "tty": &opts.tty} {
+		value := query(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid %s %q", name, value)
+		}
+		*field = parsed
+	}
+	if !opts.stdin && !opts.stdout && !opts.stderr {
+		return opts, fmt.Errorf("at least one of stdin, stdout or stderr must be requested")
+	}
+	if opts.tty {
+		opts.stderr = false
+	}
+	return opts, nil
+}
+
+// currentProcess is the container's running process, nil between runs
+func (cw *containerWorker) currentProcess() *process {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.proc
+}
+
+// execHandler runs a command inside a running container, in its working directory and with its environment. The
+// connection is upgraded to the stream protocol, and the command's exit status is sent on the error channel before
+// it is closed. The command is killed if the client goes away first
+func (k *Kubelet) execHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("podname")
+
+	cw, err := k.findContainer(namespace, podName, c.Param("container"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Container not found", "detail": err.Error()})
+		return
+	}
+	command := c.QueryArray("command")
+	if len(command) == 0 {
+		c.JSON(400, gin.H{"error": "Invalid exec options", "detail": "command is required"})
+		return
+	}
+	opts, err := parseStreamOptions(c.Query)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid exec options", "detail": err.Error()})
+		return
+	}
+	if cw.currentProcess() == nil {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s in pod %s/%s is not running", cw.spec.Name, namespace, podName)})
+		return
+	}
+	if !stream.IsUpgradeRequest(c.Request) {
+		c.JSON(400, gin.H{"error": "Exec requires upgrading the connection to " + stream.Protocol})
+		return
+	}
+
+	conn, err := stream.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		log.Printf("Error upgrading exec connection for container %s in pod %s/%s: %v", cw.spec.Name, namespace, podName, err)
+		return
+	}
+	defer conn.Close()
+
+	env := cw.environment()
+	command = append([]string{cw.lookPath(command[0], env)}, command[1:]...)
+	status := runExecSession(conn, command, cw.workingDir(), env, cw.cgroupPath, opts)
+	sendStatus(conn, status)
+}
+
+func runExecSession(conn *stream.Conn, command []string, dir string, env []string, cgroup string, opts streamOptions) stream.Status {
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.WaitDelay = execOutputWaitDelay
+
+	var stdin io.WriteCloser
+	var tty, ttySlave *os.File
+	ttyDrained := make(chan struct{})
+	if opts.tty {
+		master, slave, err := openPTY()
+		if err != nil {
+			return stream.Status{ExitCode: execStartFailedExitCode, Message: err.Error()}
+		}
+		tty, ttySlave = master, slave
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+		setControllingTerminal(cmd)
+		if opts.stdin {
+			stdin = master
+		}
+	} else {
+		close(ttyDrained)
+		if opts.stdout {
+			cmd.Stdout = conn.Writer(stream.ChannelStdout)
+		}
+		if opts.stderr {
+			cmd.Stderr = conn.Writer(stream.ChannelStderr)
+		}
+		setProcessGroup(cmd)
+		if opts.stdin {
+			pipe, err := cmd.StdinPipe()
+			if err != nil {
+				return stream.Status{ExitCode: execStartFailedExitCode, Message: err.Error()}
+			}
+			stdin = pipe
+		}
+	}
+
+	// exec'd commands count against the container's limits like its own processes
+	if cgroup != "" {
+		cgroupDir, err := joinCgroup(cmd, cgroup)
+		if err != nil {
+			if tty != nil {
+				tty.Close()
+				ttySlave.Close()
+			}
+			return stream.Status{ExitCode: execStartFailedExitCode, Message: err.Error()}
+		}
+		defer cgroupDir.Close()
+	}
+
+	if err := cmd.Start(); err != nil {
+		if tty != nil {
+			tty.Close()
+			ttySlave.Close()
+		}
+		return stream.Status{ExitCode: execStartFailedExitCode, Message: err.Error()}
+	}
+
+	if tty != nil {
+		ttySlave.Close()
+		go func() {
+			if opts.stdout {
+				io.Copy(conn.Writer(stream.ChannelStdout), tty)
+			} else {
+				io.Copy(io.Discard, tty)
+			}
+			close(ttyDrained)
+		}()
+	}
+
+	exited := make(chan struct{})
+	go serveInput(conn, stdin, tty, true, func() {
+		select {
+		case <-exited:
+		default:
+			killProcessGroup(cmd)
+		}
+	})
+
+	cmd.Wait()
+	close(exited)
+	if tty != nil {
+		select {
+		case <-ttyDrained:
+		case <-time.After(execOutputWaitDelay):
+		}
+		tty.Close()
+	}
+
+	exitCode, signal := exitStatus(cmd.ProcessState)
+	status := stream.Status{ExitCode: exitCode}
+	if signal != "" {
+		status.Message = "command terminated by signal " + signal
+	} else if exitCode != 0 {
+		status.Message = fmt.Sprintf("command terminated with exit code %d", exitCode)
+	}
+	return status
+}
+
+// attachHandler connects to the main process of a running container. Output is streamed from the moment of
+// attaching, stdin and terminal resizes are only accepted when the container keeps stdin open or runs in a
+// terminal. Detaching leaves the process running, its exit ends the session with its exit status
+func (k *Kubelet) attachHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("podname")
+
+	cw, err := k.findContainer(namespace, podName, c.Param("container"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Container not found", "detail": err.Error()})
+		return
+	}
+	opts, err := parseStreamOptions(c.Query)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid attach options", "detail": err.Error()})
+		return
+	}
+	if opts.stdin && !cw.spec.Stdin {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s in pod %s/%s does not keep stdin open", cw.spec.Name, namespace, podName)})
+		return
+	}
+	if opts.tty && !cw.spec.TTY {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s in pod %s/%s does not run in a terminal", cw.spec.Name, namespace, podName)})
+		return
+	}
+	proc := cw.currentProcess()
+	if proc == nil {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s in pod %s/%s is not running", cw.spec.Name, namespace, podName)})
+		return
+	}
+	if !stream.IsUpgradeRequest(c.Request) {
+		c.JSON(400, gin.H{"error": "Attach requires upgrading the connection to " + stream.Protocol})
+		return
+	}
+
+	conn, err := stream.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		log.Printf("Error upgrading attach connection for container %s in pod %s/%s: %v", cw.spec.Name, namespace, podName, err)
+		return
+	}
+	defer conn.Close()
+
+	output, detach := proc.output.attach()
+	defer detach()
+
+	var stdin io.WriteCloser
+	if opts.stdin {
+		stdin = proc.stdin
+	}
+	var tty *os.File
+	if opts.tty {
+		tty = proc.tty
+	}
+	// several clients may be attached at once, so one of them closing its stdin must not close the process's
+	disconnected := make(chan struct{})
+	go serveInput(conn, stdin, tty, false, func() { close(disconnected) })
+
+	for {
+		select {
+		case chunk := <-output:
+			if err := writeChunk(conn, chunk, opts); err != nil {
+				return
+			}
+
+		case <-proc.done:
+			// output written just before the exit may still be queued
+			for len(output) > 0 {
+				writeChunk(conn, <-output, opts)
+			}
+			sendStatus(conn, stream.Status{ExitCode: proc.exitCode})
+			return
+
+		case <-disconnected:
+			return
+		}
+	}
+}
+
+func writeChunk(conn *stream.Conn, chunk logChunk, opts streamOptions) error {
+	if chunk.stream == "stderr" {
+		if !opts.stderr {
+			return nil
+		}
+		return conn.WriteFrame(stream.ChannelStderr, chunk.data)
+	}
+	if !opts.stdout {
+		return nil
+	}
+	return conn.WriteFrame(stream.ChannelStdout, chunk.data)
+}
+
+// serveInput reads frames from the client until it goes away, passing stdin on and applying terminal resizes. An
+// empty stdin frame closes stdin if closeStdin is set and is ignored otherwise. A terminal cannot be closed without
+// losing its output, so it is sent end-of-file instead
+func serveInput(conn *stream.Conn, stdin io.WriteCloser, tty *os.File, closeStdin bool, disconnected func()) {
+	defer disconnected()
+	for {
+		channel, payload, err := conn.ReadFrame()
+		if err != nil {
+			return
+		}
+		switch channel {
+		case stream.ChannelStdin:
+			if stdin == nil {
+				continue
+			}
+			if len(payload) == 0 {
+				if !closeStdin {
+					continue
+				}
+				if stdin == io.WriteCloser(tty) {
+					stdin.Write([]byte{eotCharacter})
+					continue
+				}
+				stdin.Close()
+				stdin = nil
+				continue
+			}
+			stdin.Write(payload)
+
+		case stream.ChannelResize:
+			if tty == nil {
+				continue
+			}
+			var size stream.TerminalSize
+			if err := json.Unmarshal(payload, &size); err != nil {
+				continue
+			}
+			setTerminalSize(tty, size)
+		}
+	}
+}
+
+func sendStatus(conn *stream.Conn, status stream.Status) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	conn.WriteFrame(stream.ChannelError, data)
+}
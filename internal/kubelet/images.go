@@ -0,0 +1,608 @@
+package kubelet
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// Defaults for image garbage collection
+const (
+	DefaultImageGCHighThresholdPercent = 85
+	DefaultImageGCLowThresholdPercent  = 80
+	DefaultImageMinimumGCAge           = 2 * time.Minute
+)
+
+// how often the disk usage of images is checked
+const imageGCPeriod = 5 * time.Minute
+
+// the node lists at most this many of its images, the largest
+const nodeStatusMaxImages = 50
+
+// each image is kept in a directory named after its ID, with its metadata next to its root filesystem
+const imageMetadataFile = "image.json"
+
+// errors of a container whose image cannot be made available, each with a waiting reason of its own
+var (
+	errImagePull        = errors.New("failed to pull image")
+	errImageNeverPull   = errors.New("image pulls are not allowed")
+	errInvalidImageName = errors.New("invalid image name")
+)
+
+// cachedImage is an image unpacked on the node. Its files are in a root filesystem shared by every container
+// running the image, which nothing should write to
+type cachedImage struct {
+	// the digest of the image's manifest
+	ID string `json:"id"`
+	// the references the image was pulled by. A tag that moves to another image takes its name along
+	Names []string `json:"names"`
+	// repository@ID for every repository the image was pulled from, kept when its tags move on
+	RepoDigests []string    `json:"repoDigests"`
+	Size        int64       `json:"size"`
+	Config      imageConfig `json:"config"`
+
+	dir string
+	// guarded by imageManager.mu. An image with users is never garbage collected
+	lastUsed time.Time
+	users    int
+}
+
+func (img *cachedImage) rootfs() string {
+	return filepath.Join(img.dir, "rootfs")
+}
+
+// imageManager pulls the images of containers and keeps them unpacked under the kubelet's root directory until
+// they go unused and space runs short
+type imageManager struct {
+	kubelet *Kubelet
+	dir     string
+	sources []imageSource
+
+	// images are pulled one at a time
+	pullSlot chan struct{}
+	reportCh chan struct{}
+
+	mu     sync.Mutex
+	images map[string]*cachedImage
+}
+
+// EnableImages resolves the images of containers against an OCI image layout directory, a registry, or both, the
+// layout first. References naming a registry of their own, like localhost:5000/app, are pulled from it instead of
+// the default registry. Without calling it images are only names that show up in container statuses
+func (k *Kubelet) EnableImages(layoutDir, registryURL string) error {
+	m := &imageManager{
+		kubelet:  k,
+		dir:      filepath.Join(k.RootDir, "images"),
+		pullSlot: make(chan struct{}, 1),
+		reportCh: make(chan struct{}, 1),
+		images:   make(map[string]*cachedImage),
+	}
+	if layoutDir != "" {
+		if _, err := os.Stat(filepath.Join(layoutDir, "oci-layout")); err != nil {
+			return fmt.Errorf("%s is not an OCI image layout: %w", layoutDir, err)
+		}
+		m.sources = append(m.sources, &ociLayoutSource{dir: layoutDir})
+	}
+	m.sources = append(m.sources, &registrySource{defaultURL: registryURL, client: &http.Client{}})
+	if err := m.load(); err != nil {
+		return err
+	}
+	k.images = m
+	log.Printf("Pulling images from %v into %s", m.sources, m.dir)
+	return nil
+}
+
+// load picks up the images unpacked by an earlier run, which count as used just now, and clears away what pulls
+// and removals that were cut short left behind
+func (m *imageManager) load() error {
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(m.dir)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, entry := range entries {
+		dir := filepath.Join(m.dir, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") {
+			removeImageDir(dir)
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, imageMetadataFile))
+		if err != nil {
+			log.Printf("Ignoring image directory %s: %v", dir, err)
+			continue
+		}
+		img := &cachedImage{dir: dir, lastUsed: now}
+		if err := json.Unmarshal(data, img); err != nil {
+			log.Printf("Ignoring image directory %s: %v", dir, err)
+			continue
+		}
+		m.images[img.ID] = img
+	}
+	return nil
+}
+
+// acquire returns the image a reference names if the node has it, counting the caller as one of its users
+func (m *imageManager) acquire(ref models.ImageReference) *cachedImage {
+	name := ref.String()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, img := range m.images {
+		if slices.Contains(img.Names, name) {
+			img.users++
+			img.lastUsed = time.Now()
+			return img
+		}
+	}
+	return nil
+}
+
+// release gives up a use of an image returned by acquire or pull
+func (m *imageManager) release(img *cachedImage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	img.users--
+	img.lastUsed = time.Now()
+}
+
+// pull fetches the image a reference names and unpacks it unless the node already has the image it resolves to.
+// The caller counts as one of the image's users
+func (m *imageManager) pull(ctx context.Context, ref models.ImageReference) (*cachedImage, error) {
+	select {
+	case m.pullSlot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	defer func() { <-m.pullSlot }()
+
+	source, manifest, id, err := m.resolve(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	img, ok := m.images[id]
+	if ok {
+		m.use(img, ref)
+	}
+	m.mu.Unlock()
+	if ok {
+		return img, nil
+	}
+
+	if img, err = m.unpack(ctx, source, ref, id, manifest); err != nil {
+		return nil, err
+	}
+	log.Printf("Unpacked image %s (%s) from %v, %d bytes", ref, id, source, img.Size)
+	m.mu.Lock()
+	m.images[id] = img
+	m.use(img, ref)
+	m.mu.Unlock()
+	return img, nil
+}
+
+// use counts a new user of an image pulled by ref. Called with mu held
+func (m *imageManager) use(img *cachedImage, ref models.ImageReference) {
+	if m.addName(img, ref) {
+		m.requestReport()
+	}
+	img.users++
+	img.lastUsed = time.Now()
+}
+
+// resolve finds the manifest of the image in the first source that has it, picking the node's platform out of an
+// index, and returns it with its digest
+func (m *imageManager) resolve(ctx context.Context, ref models.ImageReference) (imageSource, *ociManifest, string, error) {
+	for _, source := range m.sources {
+		data, mediaType, err := source.fetchManifest(ctx, ref, "")
+		if errors.Is(err, errImageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("%v: %w", source, err)
+		}
+		digest := digestOf(data)
+		if ref.Digest != "" && digest != ref.Digest {
+			return nil, nil, "", fmt.Errorf("%v: manifest has digest %s, expected %s", source, digest, ref.Digest)
+		}
+
+		if isIndex(data, mediaType) {
+			var index ociIndex
+			if err := json.Unmarshal(data, &index); err != nil {
+				return nil, nil, "", fmt.Errorf("%v: error decoding image index: %w", source, err)
+			}
+			i := slices.IndexFunc(index.Manifests, func(desc ociDescriptor) bool {
+				return desc.Platform == nil || (desc.Platform.OS == runtime.GOOS && desc.Platform.Architecture == runtime.GOARCH)
+			})
+			if i < 0 {
+				return nil, nil, "", fmt.Errorf("%v: image %s has no manifest for %s/%s", source, ref, runtime.GOOS, runtime.GOARCH)
+			}
+			digest = index.Manifests[i].Digest
+			if data, _, err = source.fetchManifest(ctx, ref, digest); err != nil {
+				return nil, nil, "", fmt.Errorf("%v: %w", source, err)
+			}
+			if actual := digestOf(data); actual != digest {
+				return nil, nil, "", fmt.Errorf("%v: manifest has digest %s, expected %s", source, actual, digest)
+			}
+		}
+
+		var manifest ociManifest
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, nil, "", fmt.Errorf("%v: error decoding image manifest: %w", source, err)
+		}
+		return source, &manifest, digest, nil
+	}
+	return nil, nil, "", fmt.Errorf("image %s was not found in any image source", ref)
+}
+
+// isIndex tells an index from a manifest by its media type, or by its shape when the source did not say
+func isIndex(data []byte, mediaType string) bool {
+	var probe struct {
+		MediaType string          `json:"mediaType"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+	json.Unmarshal(data, &probe)
+	switch cmp.Or(probe.MediaType, mediaType) {
+	case mediaTypeOCIIndex, mediaTypeDockerList:
+		return true
+	case mediaTypeOCIManifest, mediaTypeDockerManifest:
+		return false
+	}
+	return probe.Manifests != nil
+}
+
+// unpack builds the image's root filesystem in a scratch directory that is only moved into place once every layer
+// is in and checked, so a pull cut short leaves nothing behind
+func (m *imageManager) unpack(ctx context.Context, source imageSource, ref models.ImageReference, id string, manifest *ociManifest) (*cachedImage, error) {
+	blob, err := source.fetchBlob(ctx, ref, manifest.Config.Digest)
+	if err != nil {
+		return nil, fmt.Errorf("%v: image config: %w", source, err)
+	}
+	data, err := io.ReadAll(io.LimitReader(blob, maxManifestSize))
+	blob.Close()
+	if err != nil {
+		return nil, fmt.Errorf("%v: image config: %w", source, err)
+	}
+	if actual := digestOf(data); actual != manifest.Config.Digest {
+		return nil, fmt.Errorf("%v: image config has digest %s, expected %s", source, actual, manifest.Config.Digest)
+	}
+	var config ociImageConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%v: error decoding image config: %w", source, err)
+	}
+
+	scratch, err := os.MkdirTemp(m.dir, ".pull-")
+	if err != nil {
+		return nil, err
+	}
+	defer removeImageDir(scratch)
+	w, err := newRootfsWriter(filepath.Join(scratch, "rootfs"))
+	if err != nil {
+		return nil, err
+	}
+	for _, layer := range manifest.Layers {
+		blob, err := source.fetchBlob(ctx, ref, layer.Digest)
+		if err != nil {
+			w.finish()
+			return nil, fmt.Errorf("%v: layer %s: %w", source, layer.Digest, err)
+		}
+		err = w.applyLayer(blob, layer.MediaType, layer.Digest)
+		blob.Close()
+		if err != nil {
+			w.finish()
+			return nil, fmt.Errorf("%v: layer %s: %w", source, layer.Digest, err)
+		}
+	}
+	if err := w.finish(); err != nil {
+		return nil, err
+	}
+
+	_, hex, _ := strings.Cut(id, ":")
+	img := &cachedImage{ID: id, Size: w.size, Config: config.Config, dir: filepath.Join(m.dir, hex)}
+	if err := writeImageMetadata(scratch, img); err != nil {
+		return nil, err
+	}
+	removeImageDir(img.dir)
+	if err := os.Rename(scratch, img.dir); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// addName records that img was pulled by ref, taking the name off any image it named before, and reports whether
+// that changed anything. Called with mu held
+func (m *imageManager) addName(img *cachedImage, ref models.ImageReference) bool {
+	name := ref.String()
+	for _, other := range m.images {
+		if other != img {
+			if i := slices.Index(other.Names, name); i >= 0 {
+				other.Names = slices.Delete(other.Names, i, i+1)
+				m.saveMetadata(other)
+			}
+		}
+	}
+	repoDigest := ref.Name() + "@" + img.ID
+	if slices.Contains(img.Names, name) && slices.Contains(img.RepoDigests, repoDigest) {
+		return false
+	}
+	if !slices.Contains(img.Names, name) {
+		img.Names = append(img.Names, name)
+	}
+	if !slices.Contains(img.RepoDigests, repoDigest) {
+		img.RepoDigests = append(img.RepoDigests, repoDigest)
+	}
+	m.saveMetadata(img)
+	return true
+}
+
+func (m *imageManager) saveMetadata(img *cachedImage) {
+	if err := writeImageMetadata(img.dir, img); err != nil {
+		log.Printf("Error saving metadata of image %s: %v", img.ID, err)
+	}
+}
+
+func writeImageMetadata(dir string, img *cachedImage) error {
+	data, err := json.Marshal(img)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, imageMetadataFile), data, 0o644)
+}
+
+// removeImageDir removes an image's directory, making its directories writable first since an image may well
+// have read-only ones
+func removeImageDir(dir string) error {
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && entry.IsDir() {
+			os.Chmod(path, 0o755)
+		}
+		return nil
+	})
+	return os.RemoveAll(dir)
+}
+
+// RunImageManager garbage collects unused images every imageGCPeriod and keeps the images listed on the node's
+// status up to date. It returns straight away unless EnableImages was called
+func (k *Kubelet) RunImageManager() {
+	m := k.images
+	if m == nil {
+		return
+	}
+	log.Printf("Garbage collecting images once their filesystem is %d%% full, down to %d%%", k.ImageGCHighThresholdPercent, k.ImageGCLowThresholdPercent)
+
+	ticker := time.NewTicker(imageGCPeriod)
+	defer ticker.Stop()
+	for {
+		m.reportImages()
+		select {
+		case <-ticker.C:
+			m.garbageCollect()
+		case <-m.reportCh:
+		}
+	}
+}
+
+func (m *imageManager) requestReport() {
+	select {
+	case m.reportCh <- struct{}{}:
+	default:
+	}
+}
+
+// garbageCollect frees space once the filesystem holding the images is more than ImageGCHighThresholdPercent full,
+// until it is down to ImageGCLowThresholdPercent
+func (m *imageManager) garbageCollect() {
+	k := m.kubelet
+	available, capacity, err := filesystemAvailable(m.dir)
+	if err != nil || capacity == 0 {
+		log.Printf("Error checking the disk usage of images in %s: %v", m.dir, err)
+		return
+	}
+	usage := 100 - available*100/capacity
+	if usage < int64(k.ImageGCHighThresholdPercent) {
+		return
+	}
+	target := capacity*int64(100-k.ImageGCLowThresholdPercent)/100 - available
+	log.Printf("Image filesystem is %d%% full, over the high threshold of %d%%, freeing %d bytes", usage, k.ImageGCHighThresholdPercent, target)
+	if freed := m.freeSpace(target, k.ImageMinimumGCAge); freed < target {
+		log.Printf("Failed to garbage collect the required amount of images, attempted to free %d bytes but only %d bytes were eligible", target, freed)
+	}
+}
+
+// freeSpace removes images no container uses, least recently used first, until at least bytes are freed, and
+// returns how much was. Images used within minAge are kept
+func (m *imageManager) freeSpace(bytes int64, minAge time.Duration) int64 {
+	m.mu.Lock()
+	now := time.Now()
+	var candidates []*cachedImage
+	for _, img := range m.images {
+		if img.users == 0 && now.Sub(img.lastUsed) >= minAge {
+			candidates = append(candidates, img)
+		}
+	}
+	slices.SortFunc(candidates, func(a, b *cachedImage) int { return a.lastUsed.Compare(b.lastUsed) })
+
+	var freed int64
+	var removed []string
+	for _, img := range candidates {
+		if freed >= bytes {
+			break
+		}
+		// moved aside first so a pull of the same image can unpack it again straight away
+		doomed := filepath.Join(m.dir, "."+filepath.Base(img.dir)+"-removed")
+		if err := os.Rename(img.dir, doomed); err != nil {
+			log.Printf("Error removing image %s: %v", img.ID, err)
+			continue
+		}
+		log.Printf("Removing image %s %v, unused since %v", img.ID, img.Names, img.lastUsed.Format(time.RFC3339))
+		delete(m.images, img.ID)
+		removed = append(removed, doomed)
+		freed += img.Size
+	}
+	m.mu.Unlock()
+
+	for _, dir := range removed {
+		if err := removeImageDir(dir); err != nil {
+			log.Printf("Error removing %s: %v", dir, err)
+		}
+	}
+	if len(removed) > 0 {
+		m.requestReport()
+	}
+	return freed
+}
+
+// reportImages lists the node's images on its status, largest first, if the list changed
+func (m *imageManager) reportImages() {
+	m.mu.Lock()
+	images := make([]models.ContainerImage, 0, len(m.images))
+	for _, img := range m.images {
+		names := append(slices.Clone(img.Names), img.RepoDigests...)
+		images = append(images, models.ContainerImage{Names: names, SizeBytes: img.Size})
+	}
+	m.mu.Unlock()
+	slices.SortFunc(images, func(a, b models.ContainerImage) int {
+		return cmp.Or(cmp.Compare(b.SizeBytes, a.SizeBytes), slices.Compare(a.Names, b.Names))
+	})
+	if len(images) > nodeStatusMaxImages {
+		images = images[:nodeStatusMaxImages]
+	}
+
+	k := m.kubelet
+	node, err := k.Client.GetNode(k.NodeName)
+	if err != nil {
+		log.Printf("Error fetching node %s to update its images: %v", k.NodeName, err)
+		return
+	}
+	if slices.EqualFunc(node.Images, images, func(a, b models.ContainerImage) bool {
+		return a.SizeBytes == b.SizeBytes && slices.Equal(a.Names, b.Names)
+	}) {
+		return
+	}
+	node.Images = images
+	if _, err := k.Client.UpdateNode(node); err != nil {
+		log.Printf("Error updating images of node %s: %v", k.NodeName, err)
+	}
+}
+
+// imageName is the container's image, or the pod's for a container without one
+func (cw *containerWorker) imageName() string {
+	return cmp.Or(cw.spec.Image, cw.pw.currentPod().Image)
+}
+
+// pullImage makes the container's image available as its pull policy asks and returns it, or nil when the kubelet
+// does not resolve images or the container has none. The container counts as one of the image's users
+func (cw *containerWorker) pullImage(ctx context.Context) (*cachedImage, error) {
+	images := cw.pw.kubelet.images
+	name := cw.imageName()
+	if images == nil || name == "" {
+		return nil, nil
+	}
+	ref, err := models.ParseImageReference(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidImageName, err)
+	}
+	pod := cw.pw.currentPod()
+	policy := cmp.Or(cw.spec.ImagePullPolicy, models.DefaultPullPolicy(name))
+	if policy != models.PullAlways {
+		if img := images.acquire(ref); img != nil {
+			cw.pw.kubelet.recordEvent(pod, models.EventNormal, "Pulled", fmt.Sprintf("Container image %q already present on machine", name))
+			return img, nil
+		}
+		if policy == models.PullNever {
+			return nil, fmt.Errorf("%w: container image %q is not present with pull policy of Never", errImageNeverPull, name)
+		}
+	}
+
+	log.Printf("Pulling image %s for container %s of pod %s/%s", name, cw.spec.Name, pod.Namespace, pod.Name)
+	cw.pw.kubelet.recordEvent(pod, models.EventNormal, "Pulling", fmt.Sprintf("Pulling image %q", name))
+	start := time.Now()
+	img, err := images.pull(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", errImagePull, name, err)
+	}
+	cw.pw.kubelet.recordEvent(pod, models.EventNormal, "Pulled", fmt.Sprintf("Successfully pulled image %q in %v", name, time.Since(start).Round(time.Millisecond)))
+	return img, nil
+}
+
+// setImage switches the container to the image of its next run, giving up its use of the one before
+func (cw *containerWorker) setImage(img *cachedImage) {
+	cw.pw.mu.Lock()
+	previous := cw.image
+	cw.image = img
+	if img != nil {
+		cw.status.ImageID = img.ID
+	}
+	cw.pw.mu.Unlock()
+	if previous != nil {
+		cw.pw.kubelet.images.release(previous)
+	}
+}
+
+func (cw *containerWorker) currentImage() *cachedImage {
+	cw.pw.mu.Lock()
+	defer cw.pw.mu.Unlock()
+	return cw.image
+}
+
+// lookPath finds a command in the container's image the way a shell within it would: by PATH for a bare name, and
+// from the image's working directory for a relative path unless the container sets its own. Processes still see
+// the node's filesystem, so a command the image does not have is left to be found there
+func (cw *containerWorker) lookPath(name string, env []string) string {
+	img := cw.currentImage()
+	if img == nil || name == "" {
+		return name
+	}
+	var candidates []string
+	switch {
+	case path.IsAbs(name):
+		candidates = []string{name}
+	case strings.Contains(name, "/"):
+		if cw.spec.WorkingDir == "" {
+			candidates = []string{path.Join("/", img.Config.WorkingDir, name)}
+		}
+	default:
+		for _, dir := range filepath.SplitList(envValue(env, "PATH")) {
+			if path.IsAbs(dir) {
+				candidates = append(candidates, path.Join(dir, name))
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		resolved, err := resolveInRootfs(img.rootfs(), candidate)
+		if err != nil {
+			continue
+		}
+		if info, err := os.Stat(resolved); err == nil && info.Mode().IsRegular() && info.Mode()&0o111 != 0 {
+			return resolved
+		}
+	}
+	return name
+}
+
+// envValue is the value a process given env sees for a variable, the last one set
+func envValue(env []string, name string) string {
+	for _, entry := range slices.Backward(env) {
+		if key, value, _ := strings.Cut(entry, "="); key == name {
+			return value
+		}
+	}
+	return ""
+}
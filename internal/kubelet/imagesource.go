@@ -0,0 +1,233 @@
+package kubelet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// errImageNotFound is returned by an image source that does not have the image, so the next source is tried
+var errImageNotFound = errors.New("image not found")
+
+// Media types of manifests, indexes and layers, in their OCI and Docker flavours
+const (
+	mediaTypeOCIIndex        = "application/vnd.oci.image.index.v1+json"
+	mediaTypeOCIManifest     = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeDockerList      = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeDockerManifest  = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeOCILayer        = "application/vnd.oci.image.layer.v1.tar"
+	mediaTypeOCILayerGzip    = "application/vnd.oci.image.layer.v1.tar+gzip"
+	mediaTypeDockerLayerGzip = "application/vnd.docker.image.rootfs.diff.tar.gzip"
+)
+
+// a manifest is small, anything bigger is not one
+const maxManifestSize = 4 << 20
+
+// the annotation naming a manifest in an OCI layout's index
+const annotationRefName = "org.opencontainers.image.ref.name"
+
+type ociDescriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Platform    *ociPlatform      `json:"platform,omitempty"`
+}
+
+type ociPlatform struct {
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+}
+
+// ociIndex is an image index, or a Docker manifest list, pointing at a manifest per platform
+type ociIndex struct {
+	MediaType string          `json:"mediaType,omitempty"`
+	Manifests []ociDescriptor `json:"manifests"`
+}
+
+type ociManifest struct {
+	MediaType string          `json:"mediaType,omitempty"`
+	Config    ociDescriptor   `json:"config"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+// ociImageConfig is the part of an image's configuration blob the kubelet uses
+type ociImageConfig struct {
+	Architecture string      `json:"architecture"`
+	OS           string      `json:"os"`
+	Config       imageConfig `json:"config"`
+}
+
+// imageConfig is what an image says about running it
+type imageConfig struct {
+	Entrypoint []string `json:"Entrypoint,omitempty"`
+	Cmd        []string `json:"Cmd,omitempty"`
+	Env        []string `json:"Env,omitempty"`
+	WorkingDir string   `json:"WorkingDir,omitempty"`
+}
+
+// imageSource is somewhere images are pulled from
+type imageSource interface {
+	// fetchManifest returns the manifest or index the reference names, or the one of the given digest if it is set,
+	// along with its media type when the source knows it
+	fetchManifest(ctx context.Context, ref models.ImageReference, digest string) ([]byte, string, error)
+	fetchBlob(ctx context.Context, ref models.ImageReference, digest string) (io.ReadCloser, error)
+	String() string
+}
+
+// ociLayoutSource serves images from an OCI image layout directory, as written by skopeo copy oci:dir:name or
+// docker buildx --output type=oci. An image is found by the ref.name annotation in the layout's index, which is
+// matched against the reference as given, in full, and against its tag alone
+type ociLayoutSource struct {
+	dir string
+}
+
+func (s *ociLayoutSource) String() string {
+	return "OCI layout " + s.dir
+}
+
+func (s *ociLayoutSource) fetchManifest(ctx context.Context, ref models.ImageReference, digest string) ([]byte, string, error) {
+	mediaType := ""
+	if digest == "" {
+		data, err := os.ReadFile(filepath.Join(s.dir, "index.json"))
+		if err != nil {
+			return nil, "", err
+		}
+		var index ociIndex
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, "", fmt.Errorf("error decoding index.json: %w", err)
+		}
+		for _, desc := range index.Manifests {
+			if s.matches(desc, ref) {
+				digest, mediaType = desc.Digest, desc.MediaType
+				break
+			}
+		}
+		if digest == "" {
+			return nil, "", errImageNotFound
+		}
+	}
+	blob, err := s.fetchBlob(ctx, ref, digest)
+	if err != nil {
+		return nil, "", err
+	}
+	defer blob.Close()
+	data, err := io.ReadAll(io.LimitReader(blob, maxManifestSize))
+	return data, mediaType, err
+}
+
+func (s *ociLayoutSource) matches(desc ociDescriptor, ref models.ImageReference) bool {
+	if ref.Digest != "" {
+		return desc.Digest == ref.Digest
+	}
+	name, ok := desc.Annotations[annotationRefName]
+	if !ok {
+		return false
+	}
+	full := ref.Name() + ":" + ref.TagOrDefault()
+	return name == full || name == ref.TagOrDefault() || name+":"+models.DefaultImageTag == full
+}
+
+func (s *ociLayoutSource) fetchBlob(ctx context.Context, ref models.ImageReference, digest string) (io.ReadCloser, error) {
+	algorithm, hex, ok := strings.Cut(digest, ":")
+	if !ok || strings.ContainsAny(hex, `/\.`) {
+		return nil, fmt.Errorf("invalid digest %s", digest)
+	}
+	blob, err := os.Open(filepath.Join(s.dir, "blobs", algorithm, hex))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("blob %s: %w", digest, errImageNotFound)
+	}
+	return blob, err
+}
+
+// registrySource pulls images over the registry HTTP API, from the registry a reference names or else from a
+// default one. Registries are reached anonymously, over plain HTTP when they run on the node itself
+type registrySource struct {
+	defaultURL string
+	client     *http.Client
+}
+
+func (s *registrySource) String() string {
+	if s.defaultURL == "" {
+		return "registry"
+	}
+	return "registry " + s.defaultURL
+}
+
+// baseURL is where the registry holding the image is served, empty when the reference names none and there is no
+// default
+func (s *registrySource) baseURL(ref models.ImageReference) string {
+	if ref.Registry == "" {
+		return strings.TrimSuffix(s.defaultURL, "/")
+	}
+	host := ref.Registry
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); host == "localhost" || (ip != nil && ip.IsLoopback()) {
+		return "http://" + ref.Registry
+	}
+	return "https://" + ref.Registry
+}
+
+func (s *registrySource) get(ctx context.Context, ref models.ImageReference, path string, accept ...string) (*http.Response, error) {
+	base := s.baseURL(ref)
+	if base == "" {
+		return nil, errImageNotFound
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/v2/%s/%s", base, ref.Repository, path), nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, mediaType := range accept {
+		req.Header.Add("Accept", mediaType)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errImageNotFound
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("registry responded to GET %s with %s", req.URL, resp.Status)
+	}
+	return resp, nil
+}
+
+func (s *registrySource) fetchManifest(ctx context.Context, ref models.ImageReference, digest string) ([]byte, string, error) {
+	reference := digest
+	if reference == "" {
+		reference = ref.Digest
+	}
+	if reference == "" {
+		reference = ref.TagOrDefault()
+	}
+	resp, err := s.get(ctx, ref, "manifests/"+reference, mediaTypeOCIIndex, mediaTypeOCIManifest, mediaTypeDockerList, mediaTypeDockerManifest)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";")
+	return data, strings.TrimSpace(mediaType), err
+}
+
+func (s *registrySource) fetchBlob(ctx context.Context, ref models.ImageReference, digest string) (io.ReadCloser, error) {
+	resp, err := s.get(ctx, ref, "blobs/"+digest)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
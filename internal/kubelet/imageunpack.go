@@ -0,0 +1,264 @@
+package kubelet
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Whiteouts in a layer delete what lower layers put in the root filesystem: a .wh. prefix deletes the file of the
+// rest of the name, and the opaque marker empties the directory it is in
+const (
+	whiteoutPrefix = ".wh."
+	whiteoutOpaque = ".wh..wh..opq"
+)
+
+// symbolic links within a root filesystem are followed at most this many times
+const maxSymlinkHops = 40
+
+// digestReader hashes what is read through it so the content can be checked against its digest once read in full
+type digestReader struct {
+	r    io.Reader
+	hash hash.Hash
+}
+
+func newDigestReader(r io.Reader) *digestReader {
+	d := &digestReader{hash: sha256.New()}
+	d.r = io.TeeReader(r, d.hash)
+	return d
+}
+
+func (d *digestReader) Read(p []byte) (int, error) {
+	return d.r.Read(p)
+}
+
+// verify drains what is left and checks the content against the expected digest
+func (d *digestReader) verify(expected string) error {
+	if _, err := io.Copy(io.Discard, d.r); err != nil {
+		return err
+	}
+	if actual := fmt.Sprintf("sha256:%x", d.hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("content has digest %s, expected %s", actual, expected)
+	}
+	return nil
+}
+
+func digestOf(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+// rootfsWriter applies the layers of an image, bottom first, to a root filesystem. Everything is written through
+// an os.Root so that no entry, however its links are laid out, lands outside the root. Ownership is not kept, the
+// files belong to whoever runs the kubelet
+type rootfsWriter struct {
+	root *os.Root
+	// directory modes are applied once every layer is in, so a read-only directory can still be written to
+	dirModes map[string]fs.FileMode
+	size     int64
+}
+
+func newRootfsWriter(dir string) (*rootfsWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &rootfsWriter{root: root, dirModes: make(map[string]fs.FileMode)}, nil
+}
+
+// applyLayer unpacks a layer blob of the given media type, reading it in full so its digest can be checked
+func (w *rootfsWriter) applyLayer(blob io.Reader, mediaType, digest string) error {
+	content := newDigestReader(blob)
+	var layer io.Reader = content
+	switch mediaType {
+	case mediaTypeOCILayer:
+	case mediaTypeOCILayerGzip, mediaTypeDockerLayerGzip:
+		gz, err := gzip.NewReader(content)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		layer = gz
+	default:
+		return fmt.Errorf("unsupported layer media type %s", mediaType)
+	}
+
+	// entries of this layer survive an opaque marker that follows them in the same directory
+	written := make(map[string]bool)
+	tr := tar.NewReader(layer)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := path.Clean("/" + header.Name)[1:]
+		if name == "" {
+			continue
+		}
+		dir, base := path.Split(name)
+		dir = strings.TrimSuffix(dir, "/")
+		switch {
+		case base == whiteoutOpaque:
+			if err := w.clearDir(dir, written); err != nil {
+				return err
+			}
+			continue
+		case strings.HasPrefix(base, whiteoutPrefix):
+			if err := w.root.RemoveAll(path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix))); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.writeEntry(name, header, tr); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		written[name] = true
+	}
+	return content.verify(digest)
+}
+
+func (w *rootfsWriter) writeEntry(name string, header *tar.Header, content io.Reader) error {
+	if dir := path.Dir(name); dir != "." {
+		if err := w.root.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	mode := header.FileInfo().Mode().Perm()
+	existing, err := w.root.Lstat(name)
+	if err == nil && !(existing.IsDir() && header.Typeflag == tar.TypeDir) {
+		if err := w.root.RemoveAll(name); err != nil {
+			return err
+		}
+	}
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		if err := w.root.MkdirAll(name, 0o755); err != nil {
+			return err
+		}
+		w.dirModes[name] = mode
+
+	case tar.TypeReg:
+		file, err := w.root.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+		n, err := io.Copy(file, content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		w.size += n
+		return w.root.Chmod(name, mode)
+
+	case tar.TypeSymlink:
+		return w.root.Symlink(header.Linkname, name)
+
+	case tar.TypeLink:
+		target := path.Clean("/" + header.Linkname)[1:]
+		return w.root.Link(target, name)
+
+	default:
+		// devices and fifos need privileges a kubelet may not have, and no process here uses them
+	}
+	return nil
+}
+
+// clearDir removes what lower layers put in a directory, keeping what the current layer already wrote to it
+func (w *rootfsWriter) clearDir(dir string, written map[string]bool) error {
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := fs.ReadDir(w.root.FS(), dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name())
+		if !written[name] {
+			if err := w.root.RemoveAll(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// finish applies the directories' modes, deepest first so a parent that cannot be written to comes last
+func (w *rootfsWriter) finish() error {
+	defer w.root.Close()
+	dirs := make([]string, 0, len(w.dirModes))
+	for dir := range w.dirModes {
+		dirs = append(dirs, dir)
+	}
+	slices.SortFunc(dirs, func(a, b string) int { return strings.Count(b, "/") - strings.Count(a, "/") })
+	for _, dir := range dirs {
+		if err := w.root.Chmod(dir, w.dirModes[dir]); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveInRootfs finds name within a root filesystem the way a process whose root it is would, following symbolic
+// links, absolute ones included, without ever leaving it. It returns the path on the node
+func resolveInRootfs(rootfs, name string) (string, error) {
+	pending := strings.Split(path.Clean("/"+name), "/")
+	var resolved []string
+	hops := 0
+	for len(pending) > 0 {
+		component := pending[0]
+		pending = pending[1:]
+		switch component {
+		case "", ".":
+			continue
+		case "..":
+			if len(resolved) > 0 {
+				resolved = resolved[:len(resolved)-1]
+			}
+			continue
+		}
+
+		current := filepath.Join(rootfs, filepath.Join(resolved...), component)
+		info, err := os.Lstat(current)
+		if err != nil {
+			return "", err
+		}
+		if info.Mode()&fs.ModeSymlink == 0 {
+			resolved = append(resolved, component)
+			continue
+		}
+		if hops++; hops > maxSymlinkHops {
+			return "", fmt.Errorf("too many levels of symbolic links in %s", name)
+		}
+		target, err := os.Readlink(current)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(target) {
+			resolved = nil
+		}
+		pending = append(strings.Split(target, "/"), pending...)
+	}
+	return filepath.Join(rootfs, filepath.Join(resolved...)), nil
+}
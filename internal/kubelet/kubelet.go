@@ -1,8 +1,13 @@
 package kubelet
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
 
 	"github.com/joshL1215/k8s-lite/internal/api/client"
 	"github.com/joshL1215/k8s-lite/internal/api/models"
@@ -15,6 +20,45 @@ type Kubelet struct {
 	NodeName    string
 	NodeAddress string
 	Client      *client.Client
+	Capacity    models.ResourceList
+	Labels      map[string]string
+	Taints      []models.Taint
+
+	// reported as the host and pod IP of every pod on the node
+	NodeIP string
+
+	// pod directories, including each container's default working directory, live under RootDir
+	RootDir string
+
+	ContainerLogMaxSize  int64
+	ContainerLogMaxFiles int
+
+	// nil unless EnableCgroups succeeded
+	cgroups *cgroupManager
+
+	// node-pressure eviction, see RunEvictionManager
+	EvictionThresholds               []EvictionThreshold
+	EvictionMonitoringPeriod         time.Duration
+	EvictionPressureTransitionPeriod time.Duration
+	EvictionMaxPodGracePeriod        time.Duration
+
+	// image garbage collection, see RunImageManager
+	ImageGCHighThresholdPercent int
+	ImageGCLowThresholdPercent  int
+	ImageMinimumGCAge           time.Duration
+	// nil unless EnableImages was called
+	images *imageManager
+
+	// static pods, see RunStaticPods
+	StaticPodPath      string
+	FileCheckFrequency time.Duration
+	// the static pods last read from StaticPodPath by key, nil until they have been read
+	staticMu   sync.Mutex
+	staticPods map[string]*models.Pod
+	mirrorMu   sync.Mutex
+
+	workersMu sync.Mutex
+	workers   map[string]*podWorker
 }
 
 func NewKubelet(nodeName, nodeAddress, apiURL string) (*Kubelet, error) {
@@ -25,18 +69,49 @@ func NewKubelet(nodeName, nodeAddress, apiURL string) (*Kubelet, error) {
 	return &Kubelet{
 		NodeName:    nodeName,
 		NodeAddress: nodeAddress,
+		NodeIP:      nodeIPFor(nodeAddress),
 		Client:      cl,
+		RootDir:     filepath.Join(os.TempDir(), "k8s-lite-kubelet", nodeName),
+		workers:     make(map[string]*podWorker),
+
+		ContainerLogMaxSize:  DefaultContainerLogMaxSize,
+		ContainerLogMaxFiles: DefaultContainerLogMaxFiles,
+
+		EvictionMonitoringPeriod:         DefaultEvictionMonitoringPeriod,
+		EvictionPressureTransitionPeriod: DefaultEvictionPressureTransitionPeriod,
+		EvictionMaxPodGracePeriod:        DefaultEvictionMaxPodGracePeriod,
+
+		ImageGCHighThresholdPercent: DefaultImageGCHighThresholdPercent,
+		ImageGCLowThresholdPercent:  DefaultImageGCLowThresholdPercent,
+		ImageMinimumGCAge:           DefaultImageMinimumGCAge,
+
+		FileCheckFrequency: DefaultFileCheckFrequency,
 	}, nil
 }
 
+// EnableCgroups enforces pod resource limits with a cgroup v2 hierarchy under root. Without cgroup v2, or without
+// permission to write to it, pods keep running unconstrained
+func (k *Kubelet) EnableCgroups(root string) {
+	cgroups, err := newCgroupManager(root)
+	if err != nil {
+		log.Printf("Cgroups are unavailable, pod resource limits will not be enforced: %v", err)
+		return
+	}
+	k.cgroups = cgroups
+	log.Printf("Enforcing pod resource limits with cgroups under %s", root)
+}
+
 func (k *Kubelet) RegisterNode() error {
 	node := &models.Node{
-		Name:    k.NodeName,
-		Address: k.NodeAddress,
-		Status:  models.NodeReady,
+		Name:     k.NodeName,
+		Address:  k.NodeAddress,
+		Status:   models.NodeReady,
+		Capacity: k.Capacity,
+		Labels:   k.Labels,
+		Taints:   k.Taints,
 	}
 	registeredNode, err := k.Client.CreateNode(node)
-	if err == store.ErrNodeExists {
+	if errors.Is(err, store.ErrNodeExists) {
 		log.Printf("Node %s already exists, attempting to update...: %v", k.NodeName, err)
 
 		registeredNode, err = k.Client.UpdateNode(node)
@@ -46,10 +121,16 @@ func (k *Kubelet) RegisterNode() error {
 		log.Printf("Node %s updated successfully", k.NodeName)
 		return nil
 	}
+	if err != nil {
+		return fmt.Errorf("failed to register node %s: %w", k.NodeName, err)
+	}
 	log.Printf("Node %s successfully registered", registeredNode.Name)
 	return nil
 }
 
+// SyncPods reconciles the pods bound to this node with what the kubelet is running. Pods are started by a pod
+// worker of their own and stopped once they are marked for deletion. Static pods are left to their manifests, only
+// their mirror pods are looked after here
 func (k *Kubelet) SyncPods() {
 	allPods, err := k.Client.ListPods(DefaultNamespace, "")
 	if err != nil {
@@ -57,40 +138,98 @@ func (k *Kubelet) SyncPods() {
 		return
 	}
 
-	for _, pod := range allPods {
-
+	seen := make(map[string]bool)
+	for i := range allPods {
+		pod := &allPods[i]
 		if pod.NodeName != k.NodeName {
 			continue
 		}
-		updatingPod := pod
+		key := podKey(pod)
+		seen[key] = true
+
+		// a static pod runs from its manifest whatever becomes of its mirror
+		if pod.IsMirror() {
+			k.syncMirrorPod(pod)
+			continue
+		}
+		if k.isStaticPod(key) {
+			continue
+		}
 
 		switch pod.Phase {
 		case models.PodTerminating:
+			if pod.DeletionTimestamp != nil {
+				k.terminatePod(pod)
+			}
 
-			if updatingPod.DeletionTimestamp != nil {
+		case models.PodScheduled, models.PodRunning:
+			k.ensurePodWorker(pod)
 
-				log.Printf("Pod %s/%s is terminating. Deleting pod...", updatingPod.Namespace, updatingPod.Name)
-				updatingPod.Phase = models.PodDeleted
+		default:
+			log.Printf("Pod %s/%s is in phase %s. No action taken.", pod.Namespace, pod.Name, pod.Phase)
+		}
+	}
 
-				if _, err := k.Client.UpdatePod(&updatingPod); err != nil {
-					log.Printf("Error updating pod %s/%s to Deleted: %v", updatingPod.Namespace, updatingPod.Name, err)
-				} else {
-					log.Printf("Successfully updated pod %s/%s to Deleted", updatingPod.Namespace, updatingPod.Name)
-				}
-			}
+	// a pod that disappeared from the API server altogether still has to be stopped
+	k.workersMu.Lock()
+	defer k.workersMu.Unlock()
+	for key, worker := range k.workers {
+		if !seen[key] && !worker.static && worker.beginTermination() {
+			log.Printf("Pod %s is no longer known to the API server, stopping it", key)
+			delete(k.workers, key)
+			go worker.terminate()
+		}
+	}
+}
 
-		case models.PodScheduled:
-			log.Printf("Pod %s/%s is scheduled on this node. Starting pod...", updatingPod.Namespace, updatingPod.Name)
-			updatingPod.Phase = models.PodRunning
+func podKey(pod *models.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
 
-			if _, err := k.Client.UpdatePod(&updatingPod); err != nil {
-				log.Printf("Error updating pod %s/%s to Running: %v", updatingPod.Namespace, updatingPod.Name, err)
-			} else {
-				log.Printf("Successfully updated pod %s/%s to Running", updatingPod.Namespace, updatingPod.Name)
-			}
+func (k *Kubelet) ensurePodWorker(pod *models.Pod) {
+	k.workersMu.Lock()
+	defer k.workersMu.Unlock()
 
-		default:
-			log.Printf("Pod %s/%s is in phase %s. No action taken.", updatingPod.Namespace, updatingPod.Name, updatingPod.Phase)
+	key := podKey(pod)
+	if worker, ok := k.workers[key]; ok {
+		worker.update(pod)
+		return
+	}
+	log.Printf("Pod %s/%s is scheduled on this node. Starting pod...", pod.Namespace, pod.Name)
+	worker := newPodWorker(k, pod)
+	k.workers[key] = worker
+	worker.start()
+}
+
+// terminatePod stops the pod's containers in the background and then marks it Deleted. A pod this kubelet never
+// started is marked Deleted right away
+func (k *Kubelet) terminatePod(pod *models.Pod) {
+	k.workersMu.Lock()
+	worker, ok := k.workers[podKey(pod)]
+	k.workersMu.Unlock()
+
+	if !ok {
+		log.Printf("Pod %s/%s is terminating. Deleting pod...", pod.Namespace, pod.Name)
+		pod.Phase = models.PodDeleted
+		if _, err := k.Client.UpdatePod(pod); err != nil {
+			log.Printf("Error updating pod %s/%s to Deleted: %v", pod.Namespace, pod.Name, err)
+		} else {
+			log.Printf("Successfully updated pod %s/%s to Deleted", pod.Namespace, pod.Name)
 		}
+		return
 	}
+	if !worker.beginTermination() {
+		return
+	}
+
+	log.Printf("Pod %s/%s is terminating. Stopping its containers...", pod.Namespace, pod.Name)
+	worker.update(pod)
+	go func() {
+		worker.terminate()
+		worker.syncStatus(true)
+
+		k.workersMu.Lock()
+		delete(k.workers, podKey(pod))
+		k.workersMu.Unlock()
+	}()
 }
@@ -0,0 +1,57 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// Hook kind enum
+type hookKind string
+
+const (
+	postStartHook hookKind = "PostStart"
+	preStopHook   hookKind = "PreStop"
+)
+
+// runHook runs one of the container's lifecycle hooks within timeout. A failure is recorded as an event and
+// returned as a message, an empty message means the hook passed
+func (cw *containerWorker) runHook(kind hookKind, hook *models.LifecycleHandler, timeout time.Duration) string {
+	ok, output := cw.runHandler(models.ProbeHandler{Exec: hook.Exec, HTTPGet: hook.HTTPGet}, timeout)
+	if ok {
+		return ""
+	}
+	pod := cw.pw.currentPod()
+	message := fmt.Sprintf("%sHook of container %s failed: %s", kind, cw.spec.Name, output)
+	log.Printf("%s hook of container %s in pod %s/%s failed: %s", kind, cw.spec.Name, pod.Namespace, pod.Name, output)
+	cw.pw.kubelet.recordEvent(pod, models.EventWarning, fmt.Sprintf("Failed%sHook", kind), message)
+	return message
+}
+
+// startPostStart runs the post-start hook in the background and delivers its outcome, an empty message straight
+// away when the container has no hook. The hook may take as long as the pod's grace period
+func (cw *containerWorker) startPostStart() <-chan string {
+	result := make(chan string, 1)
+	if cw.spec.Lifecycle == nil || cw.spec.Lifecycle.PostStart == nil {
+		result <- ""
+		return result
+	}
+	go func() {
+		result <- cw.runHook(postStartHook, cw.spec.Lifecycle.PostStart, cw.pw.gracePeriod())
+	}()
+	return result
+}
+
+// kill stops a run of the container, running its pre-stop hook first. The hook and SIGTERM share what is left of
+// the grace period, but a process whose hook used it all still gets a moment to handle SIGTERM
+func (cw *containerWorker) kill(proc *process) {
+	deadline := cw.pw.stopDeadline()
+	grace := time.Until(deadline)
+	if cw.spec.Lifecycle != nil && cw.spec.Lifecycle.PreStop != nil && !proc.exited() && grace > 0 {
+		cw.runHook(preStopHook, cw.spec.Lifecycle.PreStop, grace)
+		grace = max(time.Until(deadline), minimumGracePeriodAfterPreStop)
+	}
+	proc.stop(max(grace, 0))
+}
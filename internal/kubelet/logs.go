@@ -0,0 +1,434 @@
+package kubelet
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Defaults for container log rotation
+const (
+	DefaultContainerLogMaxSize  = 10 * 1024 * 1024
+	DefaultContainerLogMaxFiles = 5
+)
+
+// a line longer than this is written out in pieces rather than buffered until its newline
+const maxLogLineLength = 16 * 1024
+
+// how often a followed log is checked for new output
+const logFollowInterval = 250 * time.Millisecond
+
+// containerLog holds the output of one run of a container. Every line is written as
+// "<RFC3339Nano time> <stream> <line>" so it can later be filtered by time, and the file is rotated to
+// <path>.1, <path>.2, ... once it grows past maxSize, keeping maxFiles files in all
+type containerLog struct {
+	mu       sync.Mutex
+	path     string
+	file     *os.File
+	size     int64
+	maxSize  int64
+	maxFiles int
+	streams  []*logStream
+
+	// attached readers get the raw output as it is written
+	attachMu    sync.Mutex
+	attachments map[chan<- logChunk]struct{}
+}
+
+// logChunk is raw output of one stream, as handed to attached readers
+type logChunk struct {
+	stream string
+	data   []byte
+}
+
+// attached readers that fall this far behind lose output rather than hold up the container
+const attachBufferSize = 256
+
+func openContainerLog(path string, maxSize int64, maxFiles int) (*containerLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("error creating log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("error opening log file: %w", err)
+	}
+	return &containerLog{
+		path:     path,
+		file:     file,
+		size:     info.Size(),
+		maxSize:  maxSize,
+		maxFiles: max(maxFiles, 1),
+	}, nil
+}
+
+// stream returns a writer that tags each line written to it with the stream name
+func (l *containerLog) stream(name string) io.Writer {
+	s := &logStream{log: l, name: name}
+	l.streams = append(l.streams, s)
+	return s
+}
+
+// attach returns a channel of the output written from now on and a function that detaches it again. The channel
+// is never closed, readers stop once the process is done
+func (l *containerLog) attach() (<-chan logChunk, func()) {
+	ch := make(chan logChunk, attachBufferSize)
+	l.attachMu.Lock()
+	if l.attachments == nil {
+		l.attachments = make(map[chan<- logChunk]struct{})
+	}
+	l.attachments[ch] = struct{}{}
+	l.attachMu.Unlock()
+
+	detach := func() {
+		l.attachMu.Lock()
+		delete(l.attachments, ch)
+		l.attachMu.Unlock()
+	}
+	return ch, detach
+}
+
+func (l *containerLog) broadcast(stream string, p []byte) {
+	l.attachMu.Lock()
+	defer l.attachMu.Unlock()
+	if len(l.attachments) == 0 {
+		return
+	}
+	chunk := logChunk{stream: stream, data: append([]byte(nil), p...)}
+	for ch := range l.attachments {
+		select {
+		case ch <- chunk:
+		default:
+		}
+	}
+}
+
+func (l *containerLog) writeLine(stream string, line []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entry := fmt.Sprintf("%s %s %s\n", time.Now().UTC().Format(time.RFC3339Nano), stream, line)
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(entry)) > l.maxSize {
+		l.rotate()
+	}
+	n, _ := l.file.WriteString(entry)
+	l.size += int64(n)
+}
+
+// rotate shifts every file up by one, dropping the oldest, and starts a new one. Output keeps going to the old
+// file if a new one cannot be created
+func (l *containerLog) rotate() {
+	for i := l.maxFiles - 1; i >= 1; i-- {
+		from := rotatedLogPath(l.path, i-1)
+		if i == l.maxFiles-1 {
+			os.Remove(rotatedLogPath(l.path, i))
+		}
+		os.Rename(from, rotatedLogPath(l.path, i))
+	}
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return
+	}
+	l.file.Close()
+	l.file = file
+	l.size = 0
+}
+
+// Close writes out any unterminated lines. It is called once the process has exited and nothing writes anymore
+func (l *containerLog) Close() error {
+	for _, s := range l.streams {
+		if len(s.buf) > 0 {
+			l.writeLine(s.name, s.buf)
+			s.buf = nil
+		}
+	}
+	return l.file.Close()
+}
+
+func rotatedLogPath(path string, n int) string {
+	if n == 0 {
+		return path
+	}
+	return path + "." + strconv.Itoa(n)
+}
+
+type logStream struct {
+	log  *containerLog
+	name string
+	buf  []byte
+}
+
+func (s *logStream) Write(p []byte) (int, error) {
+	s.log.broadcast(s.name, p)
+	s.buf = append(s.buf, p...)
+	for {
+		i := bytes.IndexByte(s.buf, '\n')
+		if i < 0 {
+			break
+		}
+		s.log.writeLine(s.name, s.buf[:i])
+		s.buf = s.buf[i+1:]
+	}
+	for len(s.buf) > maxLogLineLength {
+		s.log.writeLine(s.name, s.buf[:maxLogLineLength])
+		s.buf = s.buf[maxLogLineLength:]
+	}
+	return len(p), nil
+}
+
+// logOptions select what part of a container log is served
+type logOptions struct {
+	follow     bool
+	tailLines  int64 // negative for every line
+	since      time.Time
+	timestamps bool
+}
+
+// logWriter formats stored log lines for a reader, dropping the stream tag and, unless asked for, the timestamp
+type logWriter struct {
+	w     io.Writer
+	flush func()
+	opts  logOptions
+}
+
+func (lw *logWriter) write(entry []byte) error {
+	timestamp, content, ok := parseLogEntry(entry)
+	if !ok {
+		return nil
+	}
+	if !lw.opts.since.IsZero() && timestamp.Before(lw.opts.since) {
+		return nil
+	}
+	if lw.opts.timestamps {
+		_, err := fmt.Fprintf(lw.w, "%s %s\n", timestamp.Format(time.RFC3339Nano), content)
+		return err
+	}
+	_, err := fmt.Fprintf(lw.w, "%s\n", content)
+	return err
+}
+
+func parseLogEntry(entry []byte) (time.Time, []byte, bool) {
+	entry = bytes.TrimSuffix(entry, []byte("\n"))
+	fields := bytes.SplitN(entry, []byte(" "), 3)
+	if len(fields) < 3 {
+		return time.Time{}, nil, false
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, string(fields[0]))
+	if err != nil {
+		return time.Time{}, nil, false
+	}
+	return timestamp, fields[2], true
+}
+
+// serveLog writes the log at path, oldest rotated file first. When following, it keeps waiting for output, moving
+// on to the new file when the log rotates, until running reports false or ctx is done
+func serveLog(ctx context.Context, path string, maxFiles int, opts logOptions, w io.Writer, flush func(), running func() bool) error {
+	lw := &logWriter{w: w, flush: flush, opts: opts}
+
+	// the current file is opened first so a rotation while the older files are read loses nothing
+	follower, err := openLogFollower(path)
+	if err != nil {
+		return err
+	}
+	defer follower.close()
+
+	var files []string
+	for i := max(maxFiles, 1) - 1; i >= 1; i-- {
+		if _, err := os.Stat(rotatedLogPath(path, i)); err == nil {
+			files = append(files, rotatedLogPath(path, i))
+		}
+	}
+
+	var writeErr error
+	emit := func(entry []byte) {
+		if writeErr == nil {
+			writeErr = lw.write(entry)
+		}
+	}
+	if opts.tailLines >= 0 {
+		var tail [][]byte
+		keep := func(entry []byte) {
+			if _, _, ok := parseLogEntry(entry); !ok {
+				return
+			}
+			tail = append(tail, entry)
+			if int64(len(tail)) > opts.tailLines {
+				tail = tail[1:]
+			}
+		}
+		for _, file := range files {
+			if err := readLogFile(file, keep); err != nil {
+				return err
+			}
+		}
+		follower.read(keep)
+		for _, entry := range tail {
+			emit(entry)
+		}
+	} else {
+		for _, file := range files {
+			if err := readLogFile(file, emit); err != nil {
+				return err
+			}
+		}
+		follower.read(emit)
+	}
+	if writeErr != nil {
+		return writeErr
+	}
+	flush()
+	if !opts.follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(logFollowInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// checked before reading so output written just before the container exited is still served
+		stillRunning := running()
+		follower.read(emit)
+		if writeErr != nil {
+			return writeErr
+		}
+		flush()
+		if !stillRunning {
+			return nil
+		}
+	}
+}
+
+// logFollower reads the current file of a log, carrying a partially written line over to the next read and
+// switching to the new file when the log rotates
+type logFollower struct {
+	path    string
+	file    *os.File
+	reader  *bufio.Reader
+	pending []byte
+}
+
+func openLogFollower(path string) (*logFollower, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &logFollower{path: path, file: file, reader: bufio.NewReader(file)}, nil
+}
+
+func (f *logFollower) read(emit func([]byte)) {
+	f.pending = readAvailable(f.reader, f.pending, emit)
+	if !f.rotated() {
+		return
+	}
+	next, err := os.Open(f.path)
+	if err != nil {
+		return
+	}
+	f.file.Close()
+	f.file = next
+	f.reader.Reset(next)
+	f.pending = readAvailable(f.reader, nil, emit)
+}
+
+// rotated reports whether the path now names a different file than the one open
+func (f *logFollower) rotated() bool {
+	openInfo, err := f.file.Stat()
+	if err != nil {
+		return false
+	}
+	pathInfo, err := os.Stat(f.path)
+	if err != nil {
+		return false
+	}
+	return !os.SameFile(openInfo, pathInfo)
+}
+
+func (f *logFollower) close() {
+	f.file.Close()
+}
+
+func readLogFile(path string, emit func([]byte)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		// rotated away while being read
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer file.Close()
+	pending := readAvailable(bufio.NewReader(file), nil, emit)
+	if len(pending) > 0 {
+		emit(pending)
+	}
+	return nil
+}
+
+// readAvailable emits every complete line that can be read right now and returns what is left of a partial one
+func readAvailable(reader *bufio.Reader, pending []byte, emit func([]byte)) []byte {
+	for {
+		chunk, err := reader.ReadBytes('\n')
+		pending = append(pending, chunk...)
+		if err != nil {
+			return pending
+		}
+		emit(pending)
+		pending = nil
+	}
+}
+
+// parseLogQuery reads the log options shared by the kubelet and API server routes
+func parseLogQuery(query func(string) string) (logOptions, bool, error) {
+	opts := logOptions{tailLines: -1}
+	opts.follow = query("follow") == "true"
+	opts.timestamps = query("timestamps") == "true"
+	previous := query("previous") == "true"
+
+	if value := query("tailLines"); value != "" {
+		tailLines, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || tailLines < 0 {
+			return opts, false, fmt.Errorf("tailLines must be a non-negative integer")
+		}
+		opts.tailLines = tailLines
+	}
+	if value := query("sinceSeconds"); value != "" {
+		sinceSeconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || sinceSeconds < 1 {
+			return opts, false, fmt.Errorf("sinceSeconds must be a positive integer")
+		}
+		opts.since = time.Now().Add(-time.Duration(sinceSeconds) * time.Second)
+	}
+	return opts, previous, nil
+}
+
+// removeOldLogs keeps the logs of the current and previous run of a container and removes the rest
+func removeOldLogs(dir string, instance int32) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name, _, _ := strings.Cut(entry.Name(), ".")
+		n, err := strconv.ParseInt(name, 10, 32)
+		if err == nil && int32(n) < instance-1 {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+}
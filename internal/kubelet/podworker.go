@@ -0,0 +1,309 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// a container whose pre-stop hook used up the grace period still gets this long to exit after SIGTERM, as upstream
+const minimumGracePeriodAfterPreStop = 2 * time.Second
+
+// podWorker owns everything the kubelet runs for one pod: a containerWorker per container and the loop that
+// reports their status back to the API server
+type podWorker struct {
+	kubelet *Kubelet
+	podDir  string
+	// empty when the pod runs without a cgroup of its own
+	cgroupPath string
+	// run from a manifest file, see RunStaticPods
+	static bool
+
+	// volume paths by name, set up as containers first need them, see volumes.go
+	volumesMu   sync.Mutex
+	volumes     map[string]string
+	tmpfsMounts []string
+	// links placed outside the pod directory for absolute mount paths, to their volume
+	mountLinks map[string]string
+
+	mu             sync.Mutex
+	pod            *models.Pod
+	initContainers []*containerWorker
+	containers     []*containerWorker
+	// set once every init container has completed and every sidecar has started
+	initialized bool
+	terminating bool
+	// set when the kubelet fails the pod itself, as on eviction
+	failReason  string
+	failMessage string
+	// replaces the usual grace period when set
+	gracePeriodOverride *time.Duration
+	// when the pod's containers have to be gone by, set once it is being terminated
+	deadline time.Time
+
+	// status syncs are serialized so a slow periodic sync cannot overwrite the final one
+	syncMu   sync.Mutex
+	statusCh chan struct{}
+	done     chan struct{}
+}
+
+func newPodWorker(k *Kubelet, pod *models.Pod) *podWorker {
+	pw := &podWorker{
+		kubelet:    k,
+		podDir:     filepath.Join(k.RootDir, "pods", pod.Namespace+"_"+pod.Name),
+		pod:        pod,
+		static:     pod.IsStatic(),
+		volumes:    make(map[string]string),
+		mountLinks: make(map[string]string),
+		statusCh:   make(chan struct{}, 1),
+		done:       make(chan struct{}),
+	}
+	for _, spec := range pod.InitContainers {
+		pw.initContainers = append(pw.initContainers, newContainerWorker(pw, spec, true))
+	}
+	for _, spec := range pod.Containers {
+		pw.containers = append(pw.containers, newContainerWorker(pw, spec, false))
+	}
+	pw.initialized = len(pw.initContainers) == 0
+	return pw
+}
+
+// allContainers lists the init containers followed by the containers
+func (pw *podWorker) allContainers() []*containerWorker {
+	return append(slices.Clone(pw.initContainers), pw.containers...)
+}
+
+func (pw *podWorker) start() {
+	pw.createCgroups()
+	pod := pw.currentPod()
+	for _, cw := range pw.allContainers() {
+		if err := os.MkdirAll(cw.containerDir(), 0o755); err != nil {
+			log.Printf("Error creating directory for container %s of pod %s/%s: %v", cw.spec.Name, pod.Namespace, pod.Name, err)
+		}
+	}
+	go pw.runContainers()
+	go pw.statusLoop()
+	pw.requestStatusSync()
+}
+
+// runContainers goes through the init containers in order, waiting for each to complete, or for a sidecar to
+// start, before the next, and then starts the containers. An init container that fails for good fails the pod
+func (pw *podWorker) runContainers() {
+	for _, cw := range pw.initContainers {
+		if !pw.launch(cw) {
+			return
+		}
+		if cw.isSidecar() {
+			select {
+			case <-cw.startedCh:
+			case <-cw.done:
+				return
+			}
+			continue
+		}
+		select {
+		case <-cw.finishedCh:
+		case <-cw.done:
+			return
+		}
+		if !cw.succeeded() {
+			pod := pw.currentPod()
+			log.Printf("Init container %s of pod %s/%s failed, the pod will not start", cw.spec.Name, pod.Namespace, pod.Name)
+			pw.stopSidecars()
+			return
+		}
+	}
+
+	pw.mu.Lock()
+	pw.initialized = true
+	pw.mu.Unlock()
+	if len(pw.initContainers) > 0 {
+		pod := pw.currentPod()
+		log.Printf("Pod %s/%s is initialized, starting its containers", pod.Namespace, pod.Name)
+		pw.requestStatusSync()
+	}
+	for _, cw := range pw.containers {
+		if !pw.launch(cw) {
+			return
+		}
+	}
+}
+
+// launch starts the container's run loop unless the pod is already being terminated
+func (pw *podWorker) launch(cw *containerWorker) bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.terminating {
+		return false
+	}
+	cw.launched = true
+	go cw.run()
+	return true
+}
+
+// containerFinished stops the sidecars once every container has exited for good, since there is nothing left for
+// them to help with
+func (pw *podWorker) containerFinished() {
+	pw.mu.Lock()
+	for _, cw := range pw.containers {
+		if !cw.finished {
+			pw.mu.Unlock()
+			return
+		}
+	}
+	pw.mu.Unlock()
+	go pw.stopSidecars()
+}
+
+// stopSidecars stops the sidecars one at a time in the reverse of the order they were started
+func (pw *podWorker) stopSidecars() {
+	for _, cw := range slices.Backward(pw.initContainers) {
+		if cw.isSidecar() {
+			cw.stop()
+		}
+	}
+}
+
+// update records the latest copy of the pod. Its containers are fixed at creation, so only metadata changes apply,
+// and those are passed on to the downward API volumes
+func (pw *podWorker) update(pod *models.Pod) {
+	pw.mu.Lock()
+	changed := metadataChanged(pw.pod, pod)
+	pw.pod = pod
+	pw.mu.Unlock()
+	if changed {
+		go pw.refreshVolumes(func(volume models.Volume) bool { return volume.DownwardAPI != nil })
+	}
+}
+
+func (pw *podWorker) currentPod() *models.Pod {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.pod
+}
+
+// beginTermination reports whether the caller is the one who should go on to terminate the pod
+func (pw *podWorker) beginTermination() bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.terminating {
+		return false
+	}
+	pw.terminating = true
+	return true
+}
+
+func (pw *podWorker) isTerminating() bool {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.terminating
+}
+
+// fail marks the pod Failed with the given reason once it has been terminated, stopping its containers within
+// grace instead of the usual grace period
+func (pw *podWorker) fail(reason, message string, grace time.Duration) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	pw.failReason = reason
+	pw.failMessage = message
+	pw.gracePeriodOverride = &grace
+}
+
+func (pw *podWorker) failure() (string, string) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	return pw.failReason, pw.failMessage
+}
+
+// terminate stops every container, waits for them to exit and removes the pod's directory along with its volumes.
+// The sidecars are stopped last so they outlive the containers they support. The whole pod, pre-stop hooks and
+// sidecars included, shares one grace period
+func (pw *podWorker) terminate() {
+	grace := pw.gracePeriod()
+	pw.mu.Lock()
+	pw.deadline = time.Now().Add(grace)
+	pw.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, cw := range pw.allContainers() {
+		if cw.isSidecar() {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cw.stop()
+		}()
+	}
+	wg.Wait()
+	pw.stopSidecars()
+	close(pw.done)
+
+	pod := pw.currentPod()
+	if pw.cgroupPath != "" {
+		if err := pw.kubelet.cgroups.remove(pw.cgroupPath); err != nil {
+			log.Printf("Error removing cgroup of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+
+	pw.teardownVolumes()
+	if err := os.RemoveAll(pw.podDir); err != nil {
+		log.Printf("Error removing directory of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
+
+// createCgroups sets up the pod's cgroup and one for each container. A pod whose cgroup cannot be created still
+// runs, only without its limits enforced
+func (pw *podWorker) createCgroups() {
+	cgroups := pw.kubelet.cgroups
+	if cgroups == nil {
+		return
+	}
+	pod := pw.currentPod()
+	path, err := cgroups.createPod(pod)
+	if err != nil {
+		log.Printf("Error setting up cgroup of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		pw.kubelet.recordEvent(pod, models.EventWarning, "FailedCgroup", fmt.Sprintf("Resource limits are not enforced: %v", err))
+		if path == "" {
+			return
+		}
+	}
+	pw.cgroupPath = path
+	for _, cw := range pw.allContainers() {
+		cw.cgroupPath, err = cgroups.createContainer(path, cw.spec.Name)
+		if err != nil {
+			log.Printf("Error creating cgroup for container %s of pod %s/%s: %v", cw.spec.Name, pod.Namespace, pod.Name, err)
+		}
+	}
+}
+
+func (pw *podWorker) gracePeriod() time.Duration {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.gracePeriodOverride != nil {
+		return *pw.gracePeriodOverride
+	}
+	seconds := models.DefaultTerminationGracePeriodSeconds
+	if pw.pod.TerminationGracePeriodSeconds != nil {
+		seconds = *pw.pod.TerminationGracePeriodSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// stopDeadline is when a container being stopped now has to be gone by: the pod's deadline while it is terminated,
+// otherwise a full grace period from now
+func (pw *podWorker) stopDeadline() time.Time {
+	pw.mu.Lock()
+	deadline := pw.deadline
+	pw.mu.Unlock()
+	if deadline.IsZero() {
+		return time.Now().Add(pw.gracePeriod())
+	}
+	return deadline
+}
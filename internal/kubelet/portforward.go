@@ -0,0 +1,78 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+)
+
+// how long connecting to a forwarded pod port may take
+const portForwardDialTimeout = 5 * time.Second
+
+// parsePorts reads a comma separated list of ports, as given in the ports query parameter
+func parsePorts(value string) (map[uint16]bool, error) {
+	ports := make(map[uint16]bool)
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		port, err := strconv.ParseUint(field, 10, 16)
+		if err != nil || port == 0 {
+			return nil, fmt.Errorf("invalid port %q", field)
+		}
+		ports[uint16(port)] = true
+	}
+	if len(ports) == 0 {
+		return nil, fmt.Errorf("at least one port is required")
+	}
+	return ports, nil
+}
+
+// portForwardHandler tunnels connections to the pod's ports listed in the ports query parameter. Containers share
+// the node's network, so a pod port is whatever its processes listen on at the loopback address
+func (k *Kubelet) portForwardHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("podname")
+
+	if _, err := k.findPod(namespace, podName); err != nil {
+		c.JSON(404, gin.H{"error": "Pod not found", "detail": err.Error()})
+		return
+	}
+	ports, err := parsePorts(c.Query("ports"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid port-forward options", "detail": err.Error()})
+		return
+	}
+	if !stream.IsUpgradeRequest(c.Request) {
+		c.JSON(400, gin.H{"error": "Port-forward requires upgrading the connection to " + stream.Protocol})
+		return
+	}
+
+	conn, err := stream.Upgrade(c.Writer, c.Request)
+	if err != nil {
+		log.Printf("Error upgrading port-forward connection for pod %s/%s: %v", namespace, podName, err)
+		return
+	}
+	defer conn.Close()
+
+	dial := func(port uint16) (net.Conn, error) {
+		if !ports[port] {
+			return nil, fmt.Errorf("port %d was not requested", port)
+		}
+		if _, err := k.findPod(namespace, podName); err != nil {
+			return nil, err
+		}
+		return net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(int(port))), portForwardDialTimeout)
+	}
+	tunnels := stream.NewServerTunnels(conn, dial)
+	if err := tunnels.Serve(); err != nil {
+		log.Printf("Error forwarding ports of pod %s/%s: %v", namespace, podName, err)
+	}
+}
@@ -0,0 +1,220 @@
+package kubelet
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// Probe kind enum
+type probeKind string
+
+const (
+	livenessProbe  probeKind = "Liveness"
+	readinessProbe probeKind = "Readiness"
+	startupProbe   probeKind = "Startup"
+)
+
+// probe output beyond this is cut from events and messages
+const maxProbeOutput = 1024
+
+func (cw *containerWorker) startProbes(proc *process, stop <-chan struct{}) {
+	probes := map[probeKind]*models.Probe{
+		startupProbe:   cw.spec.StartupProbe,
+		livenessProbe:  cw.spec.LivenessProbe,
+		readinessProbe: cw.spec.ReadinessProbe,
+	}
+	for kind, probe := range probes {
+		if probe != nil {
+			go cw.runProbe(kind, probe, proc, stop)
+		}
+	}
+}
+
+// runProbe checks the container every period until stop is closed. Liveness and readiness checks wait for the
+// startup probe to pass. A startup probe is done once it passes, and a failed liveness or startup probe restarts
+// the container, which starts a fresh set of probes
+func (cw *containerWorker) runProbe(kind probeKind, probe *models.Probe, proc *process, stop <-chan struct{}) {
+	pod := cw.pw.currentPod()
+	if !sleepUnlessStopped(time.Duration(probe.InitialDelaySeconds)*time.Second, stop) {
+		return
+	}
+	ticker := time.NewTicker(max(time.Duration(probe.PeriodSeconds)*time.Second, time.Second))
+	defer ticker.Stop()
+
+	var successes, failures int32
+	for {
+		if kind == startupProbe || cw.isStarted() {
+			ok, output := cw.runHandler(probe.ProbeHandler, time.Duration(probe.TimeoutSeconds)*time.Second)
+			if ok {
+				successes++
+				failures = 0
+			} else {
+				successes = 0
+				failures++
+				log.Printf("%s probe of container %s in pod %s/%s failed: %s", kind, cw.spec.Name, pod.Namespace, pod.Name, output)
+				cw.pw.kubelet.recordEvent(pod, models.EventWarning, "Unhealthy",
+					fmt.Sprintf("%s probe of container %s failed: %s", kind, cw.spec.Name, output))
+			}
+
+			switch {
+			case ok && successes >= max(probe.SuccessThreshold, 1):
+				switch kind {
+				case startupProbe:
+					cw.setStarted(proc)
+					return
+				case readinessProbe:
+					cw.setReady(proc, true)
+				}
+
+			case !ok && failures >= max(probe.FailureThreshold, 1):
+				switch kind {
+				case readinessProbe:
+					cw.setReady(proc, false)
+				case livenessProbe, startupProbe:
+					cw.requestRestart(fmt.Sprintf("failed %s probe", strings.ToLower(string(kind))))
+					return
+				}
+			}
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runHandler performs a single check and reports whether it passed along with a short description of the outcome
+func (cw *containerWorker) runHandler(handler models.ProbeHandler, timeout time.Duration) (bool, string) {
+	timeout = max(timeout, time.Second)
+	switch {
+	case handler.Exec != nil:
+		return cw.runExec(handler.Exec, timeout)
+	case handler.HTTPGet != nil:
+		return runHTTPGet(handler.HTTPGet, timeout)
+	case handler.TCPSocket != nil:
+		return runTCPSocket(handler.TCPSocket, timeout)
+	}
+	return false, "no handler set"
+}
+
+// runExec runs the command as the container would be, in its working directory, environment and cgroup
+func (cw *containerWorker) runExec(action *models.ExecAction, timeout time.Duration) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	env := cw.environment()
+	cmd := exec.CommandContext(ctx, cw.lookPath(action.Command[0], env), action.Command[1:]...)
+	cmd.Dir = cw.workingDir()
+	cmd.Env = env
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error {
+		killProcessGroup(cmd)
+		return nil
+	}
+	cmd.WaitDelay = time.Second
+	if cw.cgroupPath != "" {
+		cgroupDir, err := joinCgroup(cmd, cw.cgroupPath)
+		if err != nil {
+			return false, err.Error()
+		}
+		defer cgroupDir.Close()
+	}
+
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return false, fmt.Sprintf("command timed out after %v", timeout)
+	}
+	if err != nil {
+		return false, truncateOutput(fmt.Sprintf("%v: %s", err, output))
+	}
+	return true, truncateOutput(string(output))
+}
+
+func runHTTPGet(action *models.HTTPGetAction, timeout time.Duration) (bool, string) {
+	scheme := "http"
+	if action.Scheme == models.URISchemeHTTPS {
+		scheme = "https"
+	}
+	host := action.Host
+	if host == "" {
+		host = "localhost"
+	}
+	path := action.Path
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	url := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, strconv.Itoa(int(action.Port))), path)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, header := range action.HTTPHeaders {
+		req.Header.Add(header.Name, header.Value)
+	}
+
+	// probes check that something answers, not who, so certificates are not verified
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, err.Error()
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return false, fmt.Sprintf("HTTP probe failed with status code %d", resp.StatusCode)
+	}
+	return true, fmt.Sprintf("HTTP probe succeeded with status code %d", resp.StatusCode)
+}
+
+func runTCPSocket(action *models.TCPSocketAction, timeout time.Duration) (bool, string) {
+	host := action.Host
+	if host == "" {
+		host = "localhost"
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(int(action.Port)))
+	conn, err := net.DialTimeout("tcp", address, timeout)
+	if err != nil {
+		return false, err.Error()
+	}
+	conn.Close()
+	return true, "connected to " + address
+}
+
+func truncateOutput(output string) string {
+	output = strings.TrimSpace(output)
+	if len(output) > maxProbeOutput {
+		return output[:maxProbeOutput] + "..."
+	}
+	return output
+}
+
+// sleepUnlessStopped waits for d and reports false if stop was closed first
+func sleepUnlessStopped(d time.Duration, stop <-chan struct{}) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-stop:
+		return false
+	case <-timer.C:
+		return true
+	}
+}
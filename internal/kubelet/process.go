@@ -0,0 +1,162 @@
+package kubelet
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// a terminal's output is drained for at most this long after its process exits, in case something it spawned
+// still holds the terminal open
+const ttyDrainTimeout = time.Second
+
+// process is one run of a container's command. Its exit fields are only valid once done is closed
+type process struct {
+	cmd       *exec.Cmd
+	startedAt time.Time
+	done      chan struct{}
+	output    *containerLog
+
+	// stdin is only set for containers that keep stdin open, tty only for those run in a terminal, in which case
+	// stdin writes to the terminal as well
+	stdin io.WriteCloser
+	tty   *os.File
+
+	exitCode   int32
+	signal     string
+	oomKilled  bool
+	finishedAt time.Time
+}
+
+// processOptions are the container's stdin and tty settings and the cgroup it runs in, if any
+type processOptions struct {
+	stdin  bool
+	tty    bool
+	cgroup string
+}
+
+// startProcess launches argv in its own process group so that stopping it also stops anything it spawned. Its
+// stdout and stderr go to output, which is closed once the process has exited
+func startProcess(argv []string, dir string, env []string, output *containerLog, opts processOptions) (*process, error) {
+	if len(argv) == 0 {
+		output.Close()
+		return nil, errors.New("no command to run")
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	p := &process{
+		cmd:    cmd,
+		done:   make(chan struct{}),
+		output: output,
+	}
+
+	var ttySlave *os.File
+	if opts.tty {
+		master, slave, err := openPTY()
+		if err != nil {
+			output.Close()
+			return nil, err
+		}
+		p.tty, ttySlave = master, slave
+		cmd.Stdin, cmd.Stdout, cmd.Stderr = slave, slave, slave
+		setControllingTerminal(cmd)
+		if opts.stdin {
+			p.stdin = master
+		}
+	} else {
+		cmd.Stdout = output.stream("stdout")
+		cmd.Stderr = output.stream("stderr")
+		setProcessGroup(cmd)
+		if opts.stdin {
+			stdin, err := cmd.StdinPipe()
+			if err != nil {
+				output.Close()
+				return nil, err
+			}
+			p.stdin = stdin
+		}
+	}
+
+	var oomKillsBefore uint64
+	if opts.cgroup != "" {
+		cgroupDir, err := joinCgroup(cmd, opts.cgroup)
+		if err != nil {
+			if p.tty != nil {
+				p.tty.Close()
+				ttySlave.Close()
+			}
+			output.Close()
+			return nil, err
+		}
+		defer cgroupDir.Close()
+		oomKillsBefore = oomKills(opts.cgroup)
+	}
+
+	if err := cmd.Start(); err != nil {
+		if p.tty != nil {
+			p.tty.Close()
+			ttySlave.Close()
+		}
+		output.Close()
+		return nil, fmt.Errorf("error starting %s: %w", argv[0], err)
+	}
+	p.startedAt = time.Now()
+
+	ttyDrained := make(chan struct{})
+	if p.tty != nil {
+		ttySlave.Close()
+		go func() {
+			io.Copy(output.stream("stdout"), p.tty)
+			close(ttyDrained)
+		}()
+	} else {
+		close(ttyDrained)
+	}
+
+	go func() {
+		cmd.Wait()
+		select {
+		case <-ttyDrained:
+		case <-time.After(ttyDrainTimeout):
+		}
+		if p.tty != nil {
+			p.tty.Close()
+		}
+		output.Close()
+		p.exitCode, p.signal = exitStatus(cmd.ProcessState)
+		p.oomKilled = opts.cgroup != "" && oomKills(opts.cgroup) > oomKillsBefore
+		p.finishedAt = time.Now()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+func (p *process) exited() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// stop asks the process group to terminate and kills it if it is still around after the grace period
+func (p *process) stop(grace time.Duration) {
+	if p.exited() {
+		return
+	}
+	terminateProcessGroup(p.cmd)
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-p.done:
+	case <-timer.C:
+		killProcessGroup(p.cmd)
+		<-p.done
+	}
+}
@@ -0,0 +1,23 @@
+//go:build !unix
+
+package kubelet
+
+import (
+	"os"
+	"os/exec"
+)
+
+// without process groups only the container's own process is stopped
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
+
+func exitStatus(state *os.ProcessState) (int32, string) {
+	return int32(state.ExitCode()), ""
+}
@@ -0,0 +1,30 @@
+//go:build unix
+
+package kubelet
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// the process group shares the leader's pid, signalling its negation reaches every member
+func terminateProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// exitStatus follows the shell convention of 128+n for a process killed by signal n
+func exitStatus(state *os.ProcessState) (int32, string) {
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return 128 + int32(status.Signal()), status.Signal().String()
+	}
+	return int32(state.ExitCode()), ""
+}
@@ -0,0 +1,70 @@
+//go:build linux
+
+package kubelet
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+	"golang.org/x/sys/unix"
+)
+
+// openPTY allocates a pseudo-terminal. The slave end is handed to the process, the master end stays with the
+// kubelet. Ioctls go through the raw connection so the master stays non-blocking and can be closed under a reader
+func openPTY() (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error opening terminal: %w", err)
+	}
+	rawConn, err := master.SyscallConn()
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("error opening terminal: %w", err)
+	}
+
+	var number uint32
+	var ioctlErr error
+	err = rawConn.Control(func(fd uintptr) {
+		if ioctlErr = unix.IoctlSetPointerInt(int(fd), unix.TIOCSPTLCK, 0); ioctlErr != nil {
+			return
+		}
+		number, ioctlErr = unix.IoctlGetUint32(int(fd), unix.TIOCGPTN)
+	})
+	if err == nil {
+		err = ioctlErr
+	}
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("error unlocking terminal: %w", err)
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", number), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, fmt.Errorf("error opening terminal: %w", err)
+	}
+	return master, slave, nil
+}
+
+// a process in a terminal leads a session of its own, which also makes it the leader of its process group
+func setControllingTerminal(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true}
+}
+
+func setTerminalSize(tty *os.File, size stream.TerminalSize) error {
+	rawConn, err := tty.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var ioctlErr error
+	err = rawConn.Control(func(fd uintptr) {
+		ioctlErr = unix.IoctlSetWinsize(int(fd), unix.TIOCSWINSZ, &unix.Winsize{Row: size.Height, Col: size.Width})
+	})
+	if err != nil {
+		return err
+	}
+	return ioctlErr
+}
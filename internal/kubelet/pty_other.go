@@ -0,0 +1,23 @@
+//go:build !linux
+
+package kubelet
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/joshL1215/k8s-lite/internal/api/stream"
+)
+
+var errNoTerminals = errors.New("terminals are not supported on this platform")
+
+func openPTY() (*os.File, *os.File, error) {
+	return nil, nil, errNoTerminals
+}
+
+func setControllingTerminal(cmd *exec.Cmd) {}
+
+func setTerminalSize(tty *os.File, size stream.TerminalSize) error {
+	return errNoTerminals
+}
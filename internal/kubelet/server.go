@@ -0,0 +1,89 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Serve exposes the kubelet's own API, which the API server proxies to for anything that needs a pod's node
+func (k *Kubelet) Serve(address string) error {
+	router := gin.Default()
+	router.GET("/containerLogs/:namespace/:podname/:container", k.containerLogsHandler)
+	router.POST("/exec/:namespace/:podname/:container", k.execHandler)
+	router.POST("/attach/:namespace/:podname/:container", k.attachHandler)
+	router.POST("/portForward/:namespace/:podname", k.portForwardHandler)
+	router.GET("/stats/:namespace/:podname", k.podStatsHandler)
+
+	log.Printf("Serving kubelet API on %s", address)
+	return router.Run(address)
+}
+
+func (k *Kubelet) findPod(namespace, podName string) (*podWorker, error) {
+	k.workersMu.Lock()
+	worker, ok := k.workers[namespace+"/"+podName]
+	k.workersMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("pod %s/%s is not running on node %s", namespace, podName, k.NodeName)
+	}
+	return worker, nil
+}
+
+func (k *Kubelet) findContainer(namespace, podName, containerName string) (*containerWorker, error) {
+	worker, err := k.findPod(namespace, podName)
+	if err != nil {
+		return nil, err
+	}
+	for _, cw := range worker.allContainers() {
+		if cw.spec.Name == containerName {
+			return cw, nil
+		}
+	}
+	return nil, fmt.Errorf("container %s is not valid for pod %s/%s", containerName, namespace, podName)
+}
+
+// containerLogsHandler serves a container's log. Query parameters follow, tailLines, sinceSeconds, timestamps and
+// previous select what is served, previous picking the run before the current one
+func (k *Kubelet) containerLogsHandler(c *gin.Context) {
+	namespace := c.Param("namespace")
+	podName := c.Param("podname")
+
+	cw, err := k.findContainer(namespace, podName, c.Param("container"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Container not found", "detail": err.Error()})
+		return
+	}
+	opts, previous, err := parseLogQuery(c.Query)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Invalid log options", "detail": err.Error()})
+		return
+	}
+
+	instance := cw.restartCount()
+	if previous {
+		if instance == 0 {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("Previous terminated container %s in pod %s/%s not found", cw.spec.Name, namespace, podName)})
+			return
+		}
+		instance--
+		// a finished run has nothing more to follow
+		opts.follow = false
+	}
+
+	path := cw.logPath(instance)
+	if _, err := os.Stat(path); err != nil {
+		c.JSON(400, gin.H{"error": fmt.Sprintf("Container %s in pod %s/%s has no logs yet", cw.spec.Name, namespace, podName), "detail": err.Error()})
+		return
+	}
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(http.StatusOK)
+	flush := func() { c.Writer.Flush() }
+	running := func() bool { return cw.running(instance) }
+
+	if err := serveLog(c.Request.Context(), path, k.ContainerLogMaxFiles, opts, c.Writer, flush, running); err != nil {
+		log.Printf("Error serving logs of container %s in pod %s/%s: %v", cw.spec.Name, namespace, podName, err)
+	}
+}
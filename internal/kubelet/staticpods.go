@@ -0,0 +1,308 @@
+package kubelet
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/manifest"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+// DefaultFileCheckFrequency is how often the static pod manifests are checked for changes unless configured otherwise
+const DefaultFileCheckFrequency = 20 * time.Second
+
+// RunStaticPods runs the pods described by the manifest files in StaticPodPath, checking them for changes every
+// FileCheckFrequency. Static pods do not depend on the API server, they are only published there as mirror pods
+// so they show up alongside every other pod
+func (k *Kubelet) RunStaticPods() {
+	if k.StaticPodPath == "" {
+		// with no manifests, any mirror pod left over from an earlier run is stale
+		k.staticMu.Lock()
+		k.staticPods = make(map[string]*models.Pod)
+		k.staticMu.Unlock()
+		return
+	}
+
+	log.Printf("Running static pods from %s, checking for changes every %v", k.StaticPodPath, k.FileCheckFrequency)
+	ticker := time.NewTicker(k.FileCheckFrequency)
+	defer ticker.Stop()
+	for {
+		k.syncStaticPods()
+		<-ticker.C
+	}
+}
+
+// syncStaticPods starts the pods of new manifests, restarts those whose manifest changed and stops those whose
+// manifest is gone, then brings the mirror pods in line. A directory that cannot be read leaves everything running
+func (k *Kubelet) syncStaticPods() {
+	desired, err := k.readStaticPods()
+	if err != nil {
+		log.Printf("Error reading static pod manifests from %s: %v", k.StaticPodPath, err)
+		return
+	}
+	k.staticMu.Lock()
+	previous := k.staticPods
+	k.staticPods = desired
+	k.staticMu.Unlock()
+
+	// removed static pods are kept with a nil pod so their mirrors are deleted
+	mirrors := make(map[string]*models.Pod, len(desired))
+	for key, pod := range previous {
+		current, ok := desired[key]
+		if ok && configHash(current) == configHash(pod) {
+			continue
+		}
+		if ok {
+			log.Printf("Manifest of static pod %s changed, restarting it", key)
+		} else {
+			log.Printf("Manifest of static pod %s was removed, stopping it", key)
+			mirrors[key] = nil
+		}
+		k.stopStaticPod(key)
+	}
+	for key, pod := range desired {
+		k.startStaticPod(pod)
+		mirrors[key] = pod
+	}
+	k.syncMirrorPods(mirrors)
+}
+
+// readStaticPods decodes every manifest in StaticPodPath. Hidden files, such as an editor's swap files, are
+// skipped, as are manifests that cannot be read or define a pod another manifest already does
+func (k *Kubelet) readStaticPods() (map[string]*models.Pod, error) {
+	entries, err := os.ReadDir(k.StaticPodPath)
+	if err != nil {
+		return nil, err
+	}
+	pods := make(map[string]*models.Pod)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(k.StaticPodPath, entry.Name())
+		pod, err := k.readStaticPod(path)
+		if err != nil {
+			log.Printf("Ignoring static pod manifest %s: %v", path, err)
+			continue
+		}
+		key := podKey(pod)
+		if _, exists := pods[key]; exists {
+			log.Printf("Ignoring static pod manifest %s: pod %s is defined by another manifest", path, key)
+			continue
+		}
+		pods[key] = pod
+	}
+	return pods, nil
+}
+
+// readStaticPod decodes a manifest into a pod bound to this node. The node name is appended to the pod's name so
+// the mirrors of the same manifest on different nodes do not collide
+func (k *Kubelet) readStaticPod(path string) (*models.Pod, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var pod models.Pod
+	if err := manifest.Decode(data, &pod); err != nil {
+		return nil, err
+	}
+	if pod.Name == "" {
+		return nil, errors.New("a pod name must be provided")
+	}
+	if pod.Namespace == "" {
+		pod.Namespace = DefaultNamespace
+	}
+	switch pod.RestartPolicy {
+	case "":
+		pod.RestartPolicy = models.RestartPolicyAlways
+	case models.RestartPolicyAlways, models.RestartPolicyOnFailure, models.RestartPolicyNever:
+	default:
+		return nil, fmt.Errorf("unknown restart policy %s", pod.RestartPolicy)
+	}
+	pod.Name = pod.Name + "-" + k.NodeName
+	pod.NodeName = k.NodeName
+	pod.Phase = models.PodScheduled
+	pod.DeletionTimestamp = nil
+	pod.Reason, pod.Message = "", ""
+	pod.Conditions = nil
+	pod.StartTime = nil
+	pod.HostIP, pod.PodIP = "", ""
+	pod.InitContainerStatuses = nil
+	pod.ContainerStatuses = nil
+
+	spec, err := json.Marshal(pod)
+	if err != nil {
+		return nil, err
+	}
+	pod.Annotations = maps.Clone(pod.Annotations)
+	if pod.Annotations == nil {
+		pod.Annotations = make(map[string]string)
+	}
+	delete(pod.Annotations, models.AnnotationConfigMirror)
+	pod.Annotations[models.AnnotationConfigSource] = models.ConfigSourceFile
+	pod.Annotations[models.AnnotationConfigHash] = fmt.Sprintf("%x", sha256.Sum256(spec))[:16]
+	return &pod, nil
+}
+
+func configHash(pod *models.Pod) string {
+	return pod.Annotations[models.AnnotationConfigHash]
+}
+
+func (k *Kubelet) startStaticPod(pod *models.Pod) {
+	k.workersMu.Lock()
+	defer k.workersMu.Unlock()
+
+	key := podKey(pod)
+	if worker, ok := k.workers[key]; ok {
+		if !worker.static {
+			log.Printf("Not starting static pod %s, a pod of the same name is already running", key)
+		}
+		return
+	}
+	log.Printf("Starting static pod %s", key)
+	worker := newPodWorker(k, pod)
+	k.workers[key] = worker
+	worker.start()
+}
+
+// stopStaticPod stops a static pod and returns once its containers are gone, so a new version can take its place
+func (k *Kubelet) stopStaticPod(key string) {
+	k.workersMu.Lock()
+	worker, ok := k.workers[key]
+	if !ok || !worker.static || !worker.beginTermination() {
+		k.workersMu.Unlock()
+		return
+	}
+	delete(k.workers, key)
+	k.workersMu.Unlock()
+
+	worker.terminate()
+}
+
+// isStaticPod reports whether the pod of the given key is run from a manifest
+func (k *Kubelet) isStaticPod(key string) bool {
+	k.staticMu.Lock()
+	defer k.staticMu.Unlock()
+	_, ok := k.staticPods[key]
+	return ok
+}
+
+// syncMirrorPods brings the mirror pod of every static pod in pods up to date, deleting those whose static pod is
+// nil. Failures are logged and retried on the next check, the static pods keep running regardless
+func (k *Kubelet) syncMirrorPods(pods map[string]*models.Pod) {
+	k.mirrorMu.Lock()
+	defer k.mirrorMu.Unlock()
+
+	for key, pod := range pods {
+		namespace, name, _ := strings.Cut(key, "/")
+		mirror, err := k.Client.GetPod(namespace, name)
+		if errors.Is(err, store.ErrPodNotExist) {
+			mirror = nil
+		} else if err != nil {
+			log.Printf("Error fetching mirror pod %s: %v", key, err)
+			continue
+		}
+		k.reconcileMirrorPod(pod, mirror)
+	}
+}
+
+// syncMirrorPod handles a mirror pod seen in the API server, replacing it if it was deleted there or is out of
+// date and deleting it if its manifest is gone
+func (k *Kubelet) syncMirrorPod(mirror *models.Pod) {
+	key := podKey(mirror)
+	k.staticMu.Lock()
+	read := k.staticPods != nil
+	pod := k.staticPods[key]
+	k.staticMu.Unlock()
+	// until the manifests have been read every mirror would look stale
+	if !read {
+		return
+	}
+	if pod == nil && mirror.Phase == models.PodDeleted {
+		return
+	}
+	if pod != nil && mirror.DeletionTimestamp == nil && mirror.Annotations[models.AnnotationConfigMirror] == configHash(pod) {
+		return
+	}
+	// the listed copy may already have been replaced, so the mirror is fetched again
+	k.syncMirrorPods(map[string]*models.Pod{key: pod})
+}
+
+// reconcileMirrorPod makes mirror, the API server's copy of a static pod or nil when there is none, match pod. A
+// nil pod means the static pod is gone and so should its mirror be
+func (k *Kubelet) reconcileMirrorPod(pod, mirror *models.Pod) {
+	switch {
+	case mirror == nil || mirror.Phase == models.PodDeleted:
+		if pod != nil {
+			k.createMirrorPod(pod)
+		}
+
+	case !mirror.IsMirror():
+		if pod != nil {
+			log.Printf("Not publishing a mirror of static pod %s/%s, a pod of the same name already exists", pod.Namespace, pod.Name)
+		}
+
+	case pod == nil:
+		k.deleteMirrorPod(mirror)
+
+	case mirror.DeletionTimestamp != nil || mirror.Annotations[models.AnnotationConfigMirror] != configHash(pod):
+		// a mirror deleted through the API server is replaced just like an outdated one, the static pod keeps running
+		if k.deleteMirrorPod(mirror) {
+			k.createMirrorPod(pod)
+		}
+	}
+}
+
+func (k *Kubelet) createMirrorPod(pod *models.Pod) {
+	mirror := *pod
+	mirror.Annotations = maps.Clone(pod.Annotations)
+	mirror.Annotations[models.AnnotationConfigMirror] = configHash(pod)
+	if _, err := k.Client.CreatePod(&mirror); err != nil {
+		log.Printf("Error creating mirror pod for static pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	log.Printf("Created mirror pod for static pod %s/%s", pod.Namespace, pod.Name)
+
+	// the new mirror has yet to be given the static pod's status
+	if worker, err := k.findPod(pod.Namespace, pod.Name); err == nil && worker.static {
+		worker.requestStatusSync()
+	}
+}
+
+// deleteMirrorPod removes a mirror pod from the API server and reports whether it is gone. Nothing runs for the
+// mirror itself, so it is marked Deleted straight away
+func (k *Kubelet) deleteMirrorPod(mirror *models.Pod) bool {
+	if mirror.DeletionTimestamp == nil {
+		err := k.Client.DeletePod(mirror.Namespace, mirror.Name)
+		if errors.Is(err, store.ErrPodNotExist) {
+			return true
+		}
+		if err != nil && !errors.Is(err, store.ErrPodIsDeleting) {
+			log.Printf("Error deleting mirror pod %s/%s: %v", mirror.Namespace, mirror.Name, err)
+			return false
+		}
+		deleting, err := k.Client.GetPod(mirror.Namespace, mirror.Name)
+		if err != nil {
+			log.Printf("Error fetching mirror pod %s/%s: %v", mirror.Namespace, mirror.Name, err)
+			return false
+		}
+		mirror = deleting
+	}
+
+	mirror.Phase = models.PodDeleted
+	if _, err := k.Client.UpdatePod(mirror); err != nil {
+		log.Printf("Error updating mirror pod %s/%s to Deleted: %v", mirror.Namespace, mirror.Name, err)
+		return false
+	}
+	log.Printf("Deleted mirror pod %s/%s", mirror.Namespace, mirror.Name)
+	return true
+}
@@ -0,0 +1,61 @@
+package kubelet
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// podStats reads the pod's usage from its cgroups. Containers without a cgroup of their own are left out
+func (pw *podWorker) podStats() (*models.PodStats, error) {
+	pod := pw.currentPod()
+	if pw.cgroupPath == "" {
+		return nil, fmt.Errorf("pod %s/%s does not run in a cgroup", pod.Namespace, pod.Name)
+	}
+	podUsage, err := readCgroupStats(pw.cgroupPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cgroup of pod %s/%s: %w", pod.Namespace, pod.Name, err)
+	}
+
+	stats := &models.PodStats{
+		Name:                 pod.Name,
+		Namespace:            pod.Namespace,
+		Time:                 time.Now(),
+		CPUUsageMicroseconds: podUsage.cpuUsageMicroseconds,
+		MemoryUsageBytes:     podUsage.memoryUsageBytes,
+		OOMKills:             podUsage.oomKills,
+	}
+	for _, cw := range pw.allContainers() {
+		if cw.cgroupPath == "" {
+			continue
+		}
+		usage, err := readCgroupStats(cw.cgroupPath)
+		if err != nil {
+			continue
+		}
+		stats.Containers = append(stats.Containers, models.ContainerStats{
+			Name:                 cw.spec.Name,
+			CPUUsageMicroseconds: usage.cpuUsageMicroseconds,
+			MemoryUsageBytes:     usage.memoryUsageBytes,
+			OOMKills:             usage.oomKills,
+		})
+	}
+	return stats, nil
+}
+
+// podStatsHandler serves a pod's resource usage, which is only measured when the pod runs in a cgroup
+func (k *Kubelet) podStatsHandler(c *gin.Context) {
+	pw, err := k.findPod(c.Param("namespace"), c.Param("podname"))
+	if err != nil {
+		c.JSON(404, gin.H{"error": "Pod not found", "detail": err.Error()})
+		return
+	}
+	stats, err := pw.podStats()
+	if err != nil {
+		c.JSON(400, gin.H{"error": "Pod stats unavailable", "detail": err.Error()})
+		return
+	}
+	c.JSON(200, stats)
+}
@@ -0,0 +1,167 @@
+package kubelet
+
+import (
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// requestStatusSync schedules a status sync. Requests made while one is already pending are folded into it
+func (pw *podWorker) requestStatusSync() {
+	select {
+	case pw.statusCh <- struct{}{}:
+	default:
+	}
+}
+
+func (pw *podWorker) statusLoop() {
+	for {
+		select {
+		case <-pw.statusCh:
+			pw.syncStatus(false)
+		case <-pw.done:
+			return
+		}
+	}
+}
+
+func (pw *podWorker) containerStatuses(workers []*containerWorker) []models.ContainerStatus {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	statuses := make([]models.ContainerStatus, 0, len(workers))
+	for _, cw := range workers {
+		statuses = append(statuses, cw.status)
+	}
+	return statuses
+}
+
+// pendingInitContainers names the init containers initialization is still waiting on: those yet to complete and
+// the sidecars yet to start
+func (pw *podWorker) pendingInitContainers() []string {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.initialized {
+		return nil
+	}
+	var pending []string
+	for _, cw := range pw.initContainers {
+		completed := cw.finished && cw.status.State.Terminated != nil && cw.status.State.Terminated.ExitCode == 0
+		if cw.isSidecar() && !cw.hasStarted() || !cw.isSidecar() && !completed {
+			pending = append(pending, cw.spec.Name)
+		}
+	}
+	return pending
+}
+
+// phase is Running until every container has exited for good, then Failed if any of them failed. Sidecars do not
+// count. Until the init containers are done the pod stays Scheduled, and it is Failed if one of them failed for
+// good. A pod the kubelet failed itself is Failed straight away
+func (pw *podWorker) phase() models.PodPhase {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if pw.failReason != "" {
+		return models.PodFailed
+	}
+	for _, cw := range pw.initContainers {
+		if terminated := cw.status.State.Terminated; cw.finished && terminated != nil && terminated.ExitCode != 0 {
+			return models.PodFailed
+		}
+	}
+	if !pw.initialized {
+		return models.PodScheduled
+	}
+	if len(pw.containers) == 0 {
+		return models.PodRunning
+	}
+	phase := models.PodSucceeded
+	for _, cw := range pw.containers {
+		if !cw.finished {
+			return models.PodRunning
+		}
+		if terminated := cw.status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			phase = models.PodFailed
+		}
+	}
+	return phase
+}
+
+// syncStatus writes the containers' statuses and the readiness conditions onto a fresh copy of the pod. The
+// final sync after termination also moves the pod to Deleted
+func (pw *podWorker) syncStatus(deleted bool) {
+	pw.syncMu.Lock()
+	defer pw.syncMu.Unlock()
+
+	current := pw.currentPod()
+	pod, err := pw.kubelet.Client.GetPod(current.Namespace, current.Name)
+	if err != nil {
+		log.Printf("Error fetching pod %s/%s to update its status: %v", current.Namespace, current.Name, err)
+		return
+	}
+	if pod.Phase == models.PodDeleted {
+		return
+	}
+
+	pod.InitContainerStatuses = pw.containerStatuses(pw.initContainers)
+	pod.ContainerStatuses = pw.containerStatuses(pw.containers)
+
+	initializedCondition := models.PodCondition{Type: models.PodInitialized, Status: models.ConditionTrue}
+	if pending := pw.pendingInitContainers(); len(pending) > 0 {
+		initializedCondition.Status = models.ConditionFalse
+		initializedCondition.Reason = "ContainersNotInitialized"
+		initializedCondition.Message = fmt.Sprintf("containers with incomplete status: [%s]", strings.Join(pending, " "))
+	}
+	pod.SetCondition(initializedCondition)
+
+	// sidecars have to be ready as well, the other init containers are done by the time readiness matters
+	var unready []string
+	for i, status := range append(slices.Clone(pod.InitContainerStatuses), pod.ContainerStatuses...) {
+		if i < len(pw.initContainers) && !pw.initContainers[i].isSidecar() {
+			continue
+		}
+		if !status.Ready {
+			unready = append(unready, status.Name)
+		}
+	}
+	readyCondition := models.PodCondition{Status: models.ConditionTrue}
+	if deleted || len(unready) > 0 {
+		readyCondition = models.PodCondition{
+			Status:  models.ConditionFalse,
+			Reason:  "ContainersNotReady",
+			Message: fmt.Sprintf("containers with unready status: [%s]", strings.Join(unready, " ")),
+		}
+	}
+	readyCondition.Type = models.ContainersReady
+	pod.SetCondition(readyCondition)
+	readyCondition.Type = models.PodReady
+	pod.SetCondition(readyCondition)
+
+	if pod.StartTime == nil {
+		now := time.Now()
+		pod.StartTime = &now
+	}
+	pod.HostIP = pw.kubelet.NodeIP
+	pod.PodIP = pw.kubelet.NodeIP
+	previousPhase := pod.Phase
+	switch {
+	case deleted:
+		pod.Phase = models.PodDeleted
+	case pod.DeletionTimestamp == nil && (pod.Phase == models.PodScheduled || pod.Phase == models.PodRunning):
+		pod.Phase = pw.phase()
+		if pod.Phase == models.PodFailed {
+			pod.Reason, pod.Message = pw.failure()
+		}
+	}
+
+	if _, err := pw.kubelet.Client.UpdatePod(pod); err != nil {
+		log.Printf("Error updating status of pod %s/%s: %v", pod.Namespace, pod.Name, err)
+		return
+	}
+	pw.update(pod)
+	if pod.Phase != previousPhase {
+		log.Printf("Successfully updated pod %s/%s to %s", pod.Namespace, pod.Name, pod.Phase)
+	}
+}
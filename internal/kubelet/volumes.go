@@ -0,0 +1,351 @@
+package kubelet
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+// errMountFailed marks a container that cannot start because one of its volumes could not be set up
+var errMountFailed = errors.New("unable to mount volumes")
+
+// a projected volume's files are reached through this link. Every update writes a new directory and swaps the
+// link, so a process never sees a mix of old and new files
+const projectionDataLink = "..data"
+
+// projectedFile is one file of a config map or secret volume
+type projectedFile struct {
+	data []byte
+	mode os.FileMode
+}
+
+func (pw *podWorker) volumeDir(kind, name string) string {
+	return filepath.Join(pw.podDir, "volumes", kind, name)
+}
+
+// volumePath sets up the named volume the first time a container needs it and returns the directory, or for a
+// host path the file, that mounts of it lead to
+func (pw *podWorker) volumePath(name string) (string, error) {
+	pw.volumesMu.Lock()
+	defer pw.volumesMu.Unlock()
+
+	if path, ok := pw.volumes[name]; ok {
+		return path, nil
+	}
+	for _, volume := range pw.currentPod().Volumes {
+		if volume.Name != name {
+			continue
+		}
+		path, err := pw.setupVolume(volume)
+		if err != nil {
+			return "", fmt.Errorf("volume %s: %w", name, err)
+		}
+		pw.volumes[name] = path
+		return path, nil
+	}
+	return "", fmt.Errorf("pod has no volume %s", name)
+}
+
+func (pw *podWorker) setupVolume(volume models.Volume) (string, error) {
+	pod := pw.currentPod()
+	switch {
+	case volume.EmptyDir != nil:
+		dir := pw.volumeDir("empty-dir", volume.Name)
+		if err := os.MkdirAll(dir, 0o777); err != nil {
+			return "", err
+		}
+		if volume.EmptyDir.Medium == models.StorageMediumMemory {
+			if err := mountTmpfs(dir, volume.EmptyDir.SizeLimit); err != nil {
+				return "", fmt.Errorf("error mounting tmpfs at %s: %w", dir, err)
+			}
+			pw.tmpfsMounts = append(pw.tmpfsMounts, dir)
+		}
+		return dir, nil
+
+	case volume.HostPath != nil:
+		return volume.HostPath.Path, checkHostPath(volume.HostPath)
+
+	case volume.ConfigMap != nil:
+		source := volume.ConfigMap
+		data := make(map[string][]byte)
+		cm, err := pw.kubelet.Client.GetConfigMap(pod.Namespace, source.Name)
+		switch {
+		case errors.Is(err, store.ErrConfigMapNotExist) && source.Optional:
+		case err != nil:
+			return "", fmt.Errorf("error fetching config map %s: %w", source.Name, err)
+		default:
+			for key, value := range cm.Data {
+				data[key] = []byte(value)
+			}
+			for key, value := range cm.BinaryData {
+				data[key] = value
+			}
+		}
+		files, err := projectKeys(data, source.Items, source.DefaultMode, source.Optional)
+		if err != nil {
+			return "", fmt.Errorf("config map %s: %w", source.Name, err)
+		}
+		dir := pw.volumeDir("configmap", volume.Name)
+		return dir, writeProjection(dir, files)
+
+	case volume.Secret != nil:
+		source := volume.Secret
+		var data map[string][]byte
+		secret, err := pw.kubelet.Client.GetSecret(pod.Namespace, source.SecretName)
+		switch {
+		case errors.Is(err, store.ErrSecretNotExist) && source.Optional:
+		case err != nil:
+			return "", fmt.Errorf("error fetching secret %s: %w", source.SecretName, err)
+		default:
+			data = secret.Data
+		}
+		files, err := projectKeys(data, source.Items, source.DefaultMode, source.Optional)
+		if err != nil {
+			return "", fmt.Errorf("secret %s: %w", source.SecretName, err)
+		}
+		dir := pw.volumeDir("secret", volume.Name)
+		return dir, writeProjection(dir, files)
+
+	case volume.DownwardAPI != nil:
+		return pw.setupDownwardAPIVolume(volume.Name, volume.DownwardAPI)
+	}
+	return "", errors.New("volume has no source")
+}
+
+// checkHostPath makes sure the host path is what its type asks for, creating it if the type allows
+func checkHostPath(source *models.HostPathVolumeSource) error {
+	info, err := os.Stat(source.Path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+
+	switch source.Type {
+	case models.HostPathDirectoryOrCreate:
+		if !exists {
+			return os.MkdirAll(source.Path, 0o755)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", source.Path)
+		}
+	case models.HostPathDirectory:
+		if !exists || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", source.Path)
+		}
+	case models.HostPathFileOrCreate:
+		if !exists {
+			file, err := os.OpenFile(source.Path, os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				return err
+			}
+			return file.Close()
+		}
+		if info.IsDir() {
+			return fmt.Errorf("%s is a directory", source.Path)
+		}
+	case models.HostPathFile:
+		if !exists || info.IsDir() {
+			return fmt.Errorf("%s is not a file", source.Path)
+		}
+	}
+	return nil
+}
+
+// projectKeys picks the files of a config map or secret volume: every key under its own name, or only the listed
+// items. A listed key that is missing is an error unless the volume is optional
+func projectKeys(data map[string][]byte, items []models.KeyToPath, defaultMode *int32, optional bool) (map[string]projectedFile, error) {
+	mode := os.FileMode(models.DefaultProjectedFileMode)
+	if defaultMode != nil {
+		mode = os.FileMode(*defaultMode)
+	}
+	files := make(map[string]projectedFile)
+	if len(items) == 0 {
+		for key, value := range data {
+			files[key] = projectedFile{data: value, mode: mode}
+		}
+		return files, nil
+	}
+	for _, item := range items {
+		value, ok := data[item.Key]
+		if !ok {
+			if optional {
+				continue
+			}
+			return nil, fmt.Errorf("key %s does not exist", item.Key)
+		}
+		file := projectedFile{data: value, mode: mode}
+		if item.Mode != nil {
+			file.mode = os.FileMode(*item.Mode)
+		}
+		files[filepath.Clean(item.Path)] = file
+	}
+	return files, nil
+}
+
+// writeProjection replaces the files of a projected volume at once. The files are written to a new directory that
+// the data link is then swapped to, and every top level name in the volume is a link through the data link
+func writeProjection(dir string, files map[string]projectedFile) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dataDir, err := os.MkdirTemp(dir, ".."+time.Now().Format("2006_01_02_15_04_05."))
+	if err != nil {
+		return err
+	}
+	if err := writeProjectedFiles(dataDir, files); err != nil {
+		os.RemoveAll(dataDir)
+		return err
+	}
+
+	dataLink := filepath.Join(dir, projectionDataLink)
+	previous, _ := os.Readlink(dataLink)
+	newLink := filepath.Join(dir, projectionDataLink+"_tmp")
+	os.Remove(newLink)
+	if err := os.Symlink(filepath.Base(dataDir), newLink); err != nil {
+		os.RemoveAll(dataDir)
+		return err
+	}
+	if err := os.Rename(newLink, dataLink); err != nil {
+		os.RemoveAll(dataDir)
+		return err
+	}
+
+	visible := make(map[string]bool)
+	for name := range files {
+		top, _, _ := strings.Cut(name, string(filepath.Separator))
+		visible[top] = true
+	}
+	for name := range visible {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); err == nil {
+			continue
+		}
+		if err := os.Symlink(filepath.Join(projectionDataLink, name), link); err != nil {
+			return err
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "..") && !visible[entry.Name()] {
+			os.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	if previous != "" {
+		os.RemoveAll(filepath.Join(dir, previous))
+	}
+	return nil
+}
+
+func writeProjectedFiles(dataDir string, files map[string]projectedFile) error {
+	if err := os.Chmod(dataDir, 0o755); err != nil {
+		return err
+	}
+	for name, file := range files {
+		path := filepath.Join(dataDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, file.data, file.mode); err != nil {
+			return err
+		}
+		// the umask may have taken bits off the requested mode
+		if err := os.Chmod(path, file.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mountVolumes exposes each volume the container mounts at its mount path. Processes share the node's filesystem,
+// so a mount is a symbolic link to the volume, placed in the container's working directory for a relative path
+func (cw *containerWorker) mountVolumes() error {
+	for _, mount := range cw.spec.VolumeMounts {
+		source, err := cw.pw.volumePath(mount.Name)
+		if err != nil {
+			return err
+		}
+		if mount.SubPath != "" {
+			source = filepath.Join(source, mount.SubPath)
+			if _, err := os.Stat(source); errors.Is(err, os.ErrNotExist) {
+				if err := os.MkdirAll(source, 0o755); err != nil {
+					return err
+				}
+			}
+		}
+		target := mount.MountPath
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(cw.workingDir(), target)
+		}
+		if err := cw.pw.linkMount(source, filepath.Clean(target)); err != nil {
+			return fmt.Errorf("volume %s: %w", mount.Name, err)
+		}
+	}
+	return nil
+}
+
+// linkMount links target to source. Anything already at target is left alone unless it is a link the kubelet made
+func (pw *podWorker) linkMount(source, target string) error {
+	if current, err := os.Readlink(target); err == nil {
+		if current == source {
+			return nil
+		}
+		if !withinDir(pw.kubelet.RootDir, current) && !withinDir(pw.kubelet.RootDir, target) {
+			return fmt.Errorf("mount path %s already exists", target)
+		}
+		if err := os.Remove(target); err != nil {
+			return err
+		}
+	} else if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("mount path %s already exists", target)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	if err := os.Symlink(source, target); err != nil {
+		return err
+	}
+	// links inside the pod directory go with it
+	if !withinDir(pw.podDir, target) {
+		pw.volumesMu.Lock()
+		pw.mountLinks[target] = source
+		pw.volumesMu.Unlock()
+	}
+	return nil
+}
+
+// teardownVolumes removes what the pod's volumes left outside its directory and unmounts its tmpfs volumes, so
+// the directory can be removed. Host paths are never touched
+func (pw *podWorker) teardownVolumes() {
+	pw.volumesMu.Lock()
+	defer pw.volumesMu.Unlock()
+
+	for target, source := range pw.mountLinks {
+		if current, err := os.Readlink(target); err == nil && current == source {
+			os.Remove(target)
+		}
+	}
+	pod := pw.currentPod()
+	for _, dir := range pw.tmpfsMounts {
+		if err := unmountTmpfs(dir); err != nil {
+			log.Printf("Error unmounting tmpfs volume at %s of pod %s/%s: %v", dir, pod.Namespace, pod.Name, err)
+		}
+	}
+	// so a late refresh of a projected volume does not write into a directory about to be removed
+	clear(pw.volumes)
+}
+
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,19 @@
+package kubelet
+
+import (
+	"strconv"
+
+	"golang.org/x/sys/unix"
+)
+
+func mountTmpfs(dir string, sizeLimit int64) error {
+	options := "mode=0777"
+	if sizeLimit > 0 {
+		options += ",size=" + strconv.FormatInt(sizeLimit, 10)
+	}
+	return unix.Mount("tmpfs", dir, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, options)
+}
+
+func unmountTmpfs(dir string) error {
+	return unix.Unmount(dir, 0)
+}
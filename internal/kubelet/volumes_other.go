@@ -0,0 +1,13 @@
+//go:build !linux
+
+package kubelet
+
+import "errors"
+
+func mountTmpfs(dir string, sizeLimit int64) error {
+	return errors.New("memory backed volumes are only supported on Linux")
+}
+
+func unmountTmpfs(dir string) error {
+	return nil
+}
@@ -0,0 +1,65 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// Configuration lists the profiles a single scheduler process serves. Each profile handles the pods whose
+// schedulerName matches its own and ignores the rest. Extenders are consulted by every profile
+type Configuration struct {
+	Profiles  []ProfileConfig  `json:"profiles"`
+	Extenders []ExtenderConfig `json:"extenders,omitempty"`
+}
+
+// ProfileConfig names the filter and score plugins a profile runs. Leaving either list empty selects the defaults
+type ProfileConfig struct {
+	SchedulerName string              `json:"schedulerName"`
+	Filters       []string            `json:"filters,omitempty"`
+	Scores        []ScorePluginConfig `json:"scores,omitempty"`
+}
+
+type ScorePluginConfig struct {
+	Name   string `json:"name"`
+	Weight int64  `json:"weight,omitempty"`
+}
+
+var defaultFilters = []string{"NodeResourcesFit", "NodeAffinity", "TaintToleration", "PodTopologySpread"}
+
+var defaultScores = []ScorePluginConfig{{Name: "PodTopologySpread", Weight: 1}, {Name: "TaintToleration", Weight: 1}}
+
+// plugin registry, every entry implements at least one of the plugin interfaces
+var registry = map[string]func() any{
+	"NodeResourcesFit":            func() any { return nodeResourcesFit{} },
+	"NodeAffinity":                func() any { return nodeAffinity{} },
+	"TaintToleration":             func() any { return taintToleration{} },
+	"NodeResourcesLeastAllocated": func() any { return nodeResourcesAllocated{} },
+	"NodeResourcesMostAllocated":  func() any { return nodeResourcesAllocated{mostAllocated: true} },
+	"PodTopologySpread":           func() any { return podTopologySpread{} },
+}
+
+func DefaultConfiguration() *Configuration {
+	return &Configuration{
+		Profiles: []ProfileConfig{{SchedulerName: models.DefaultSchedulerName}},
+	}
+}
+
+// LoadConfiguration reads a JSON configuration file
+func LoadConfiguration(path string) (*Configuration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading scheduler configuration: %w", err)
+	}
+
+	var cfg Configuration
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing scheduler configuration: %w", err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, fmt.Errorf("scheduler configuration %s has no profiles", path)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,67 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const (
+	reasonScheduled        = "Scheduled"
+	reasonFailedScheduling = "FailedScheduling"
+	reasonUnschedulable    = "Unschedulable"
+	reasonPartiallyBound   = "PodGroupPartiallyBound"
+)
+
+// each pod keeps one event per reason, so repeated attempts bump a count instead of piling up events
+func (s *Scheduler) recordEvent(profile *Profile, pod *models.Pod, severity models.EventSeverity, reason, message string, diagnosis *models.SchedulingDiagnosis) {
+	event := &models.Event{
+		Name:      fmt.Sprintf("%s.%s", pod.Name, reason),
+		Namespace: pod.Namespace,
+		InvolvedObject: models.ObjectReference{
+			Kind:      "Pod",
+			Namespace: pod.Namespace,
+			Name:      pod.Name,
+		},
+		Reason:              reason,
+		Message:             message,
+		Type:                severity,
+		Source:              profile.SchedulerName,
+		SchedulingDiagnosis: diagnosis,
+	}
+	if err := s.Client.RecordEvent(event); err != nil {
+		log.Printf("Error recording %s event for pod %s/%s: %v", reason, pod.Namespace, pod.Name, err)
+	}
+}
+
+func (s *Scheduler) recordScheduled(profile *Profile, podInfo *queuedPodInfo, nodeName string) {
+	pod := podInfo.pod
+	message := fmt.Sprintf("Successfully assigned %s/%s to %s", pod.Namespace, pod.Name, nodeName)
+	s.recordEvent(profile, pod, models.EventNormal, reasonScheduled, message, podInfo.diagnosis)
+}
+
+// recordUnschedulable publishes why the attempt failed as a FailedScheduling event and as a PodScheduled=False
+// condition on the pod
+func (s *Scheduler) recordUnschedulable(profile *Profile, podInfo *queuedPodInfo, fitErr *FitError) {
+	s.recordEvent(profile, podInfo.pod, models.EventWarning, reasonFailedScheduling, fitErr.Error(), fitErr.Diagnosis)
+
+	// preemption may have nominated a node since the pod was fetched, so the condition goes on a fresh copy
+	pod, err := s.Client.GetPod(podInfo.pod.Namespace, podInfo.pod.Name)
+	if err != nil {
+		log.Printf("Error fetching pod %s/%s to set its scheduling condition: %v", podInfo.pod.Namespace, podInfo.pod.Name, err)
+		return
+	}
+	changed := pod.SetCondition(models.PodCondition{
+		Type:    models.PodScheduledCondition,
+		Status:  models.ConditionFalse,
+		Reason:  reasonUnschedulable,
+		Message: fitErr.Error(),
+	})
+	if !changed {
+		return
+	}
+	if _, err := s.Client.UpdatePod(pod); err != nil {
+		log.Printf("Error setting scheduling condition on pod %s/%s: %v", pod.Namespace, pod.Name, err)
+	}
+}
@@ -0,0 +1,161 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const defaultExtenderTimeout = 5 * time.Second
+
+// ExtenderConfig points the scheduler at an HTTP service that takes part in filtering, scoring or binding.
+// Verbs left empty are not called
+type ExtenderConfig struct {
+	URLPrefix      string `json:"urlPrefix"`
+	FilterVerb     string `json:"filterVerb,omitempty"`
+	PrioritizeVerb string `json:"prioritizeVerb,omitempty"`
+	BindVerb       string `json:"bindVerb,omitempty"`
+	Weight         int64  `json:"weight,omitempty"`
+	HTTPTimeout    string `json:"httpTimeout,omitempty"` // e.g. "500ms", defaults to 5s
+
+	// an ignorable extender that cannot be reached is skipped instead of failing the scheduling attempt
+	Ignorable bool `json:"ignorable,omitempty"`
+}
+
+type HTTPExtender struct {
+	cfg        ExtenderConfig
+	httpClient *http.Client
+}
+
+func NewHTTPExtender(cfg ExtenderConfig) (*HTTPExtender, error) {
+	if cfg.URLPrefix == "" {
+		return nil, fmt.Errorf("an extender must have a urlPrefix")
+	}
+	timeout := defaultExtenderTimeout
+	if cfg.HTTPTimeout != "" {
+		parsed, err := time.ParseDuration(cfg.HTTPTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("extender %s: invalid httpTimeout: %w", cfg.URLPrefix, err)
+		}
+		timeout = parsed
+	}
+	if cfg.Weight == 0 {
+		cfg.Weight = 1
+	}
+	cfg.URLPrefix = strings.TrimSuffix(cfg.URLPrefix, "/")
+
+	return &HTTPExtender{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (e *HTTPExtender) Name() string { return e.cfg.URLPrefix }
+
+func (e *HTTPExtender) IsIgnorable() bool { return e.cfg.Ignorable }
+
+func (e *HTTPExtender) IsBinder() bool { return e.cfg.BindVerb != "" }
+
+// Filter returns the nodes the extender accepts along with the reasons it gave for the others
+func (e *HTTPExtender) Filter(pod *models.Pod, nodes []*NodeInfo) ([]*NodeInfo, map[string]string, error) {
+	if e.cfg.FilterVerb == "" {
+		return nodes, nil, nil
+	}
+
+	var result models.ExtenderFilterResult
+	if err := e.send(e.cfg.FilterVerb, extenderArgs(pod, nodes), &result); err != nil {
+		return nil, nil, err
+	}
+	if result.Error != "" {
+		return nil, nil, fmt.Errorf("extender %s: %s", e.Name(), result.Error)
+	}
+
+	accepted := make(map[string]bool, len(result.Nodes))
+	for _, node := range result.Nodes {
+		accepted[node.Name] = true
+	}
+	failed := make(map[string]string)
+	var feasible []*NodeInfo
+	for _, nodeInfo := range nodes {
+		if accepted[nodeInfo.Node.Name] {
+			feasible = append(feasible, nodeInfo)
+			continue
+		}
+		reason, ok := result.FailedNodes[nodeInfo.Node.Name]
+		if !ok {
+			reason = "rejected by extender"
+		}
+		failed[nodeInfo.Node.Name] = reason
+	}
+	return feasible, failed, nil
+}
+
+// Prioritize returns the extender's scores already scaled to MaxNodeScore and multiplied by its weight
+func (e *HTTPExtender) Prioritize(pod *models.Pod, nodes []*NodeInfo) (map[string]int64, error) {
+	if e.cfg.PrioritizeVerb == "" {
+		return nil, nil
+	}
+
+	var result []models.HostPriority
+	if err := e.send(e.cfg.PrioritizeVerb, extenderArgs(pod, nodes), &result); err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]int64, len(result))
+	for _, hp := range result {
+		score := min(max(hp.Score, 0), models.MaxExtenderPriority)
+		scores[hp.Host] = score * (MaxNodeScore / models.MaxExtenderPriority) * e.cfg.Weight
+	}
+	return scores, nil
+}
+
+func (e *HTTPExtender) Bind(pod *models.Pod, nodeName string) error {
+	args := models.ExtenderBindingArgs{
+		PodName:      pod.Name,
+		PodNamespace: pod.Namespace,
+		Node:         nodeName,
+	}
+	var result models.ExtenderBindingResult
+	if err := e.send(e.cfg.BindVerb, args, &result); err != nil {
+		return err
+	}
+	if result.Error != "" {
+		return fmt.Errorf("extender %s: %s", e.Name(), result.Error)
+	}
+	return nil
+}
+
+func (e *HTTPExtender) send(verb string, args any, result any) error {
+	body, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("error while marshalling extender args: %w", err)
+	}
+
+	urlStr := e.cfg.URLPrefix + "/" + verb
+	resp, err := e.httpClient.Post(urlStr, "application/json", bytes.NewBuffer(body))
+	if err != nil {
+		return fmt.Errorf("error while calling extender %s: %w", urlStr, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("extender %s failed, status code: %d", urlStr, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("error while decoding extender response: %w", err)
+	}
+	return nil
+}
+
+func extenderArgs(pod *models.Pod, nodes []*NodeInfo) models.ExtenderArgs {
+	args := models.ExtenderArgs{Pod: pod, Nodes: make([]models.Node, 0, len(nodes))}
+	for _, nodeInfo := range nodes {
+		args.Nodes = append(args.Nodes, *nodeInfo.Node)
+	}
+	return args
+}
@@ -0,0 +1,222 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/scheduler/stubextender"
+)
+
+// startStubExtender serves the stub extender and returns an extender for it with the given configuration
+func startStubExtender(t *testing.T, stub *stubextender.Extender, cfg ExtenderConfig) *HTTPExtender {
+	t.Helper()
+	server := httptest.NewServer(stub.Handler())
+	t.Cleanup(server.Close)
+
+	cfg.URLPrefix = server.URL
+	extender, err := NewHTTPExtender(cfg)
+	if err != nil {
+		t.Fatalf("NewHTTPExtender: %v", err)
+	}
+	return extender
+}
+
+func newTestProfile(t *testing.T, extenders ...*HTTPExtender) *Profile {
+	t.Helper()
+	profile, err := NewProfile(ProfileConfig{SchedulerName: models.DefaultSchedulerName}, extenders)
+	if err != nil {
+		t.Fatalf("NewProfile: %v", err)
+	}
+	return profile
+}
+
+// testNodes makes a node for every set of labels, named n1, n2 and so on
+func testNodes(labels ...map[string]string) []*NodeInfo {
+	nodes := make([]*NodeInfo, 0, len(labels))
+	for i, nodeLabels := range labels {
+		nodes = append(nodes, newNodeInfo(&models.Node{
+			Name:   fmt.Sprintf("n%d", i+1),
+			Labels: nodeLabels,
+			Status: models.NodeReady,
+		}))
+	}
+	return nodes
+}
+
+func nodeNames(nodes []*NodeInfo) []string {
+	names := make([]string, 0, len(nodes))
+	for _, nodeInfo := range nodes {
+		names = append(names, nodeInfo.Node.Name)
+	}
+	return names
+}
+
+func testPod() *models.Pod {
+	return &models.Pod{Name: "web", Namespace: "default", SchedulerName: models.DefaultSchedulerName, Phase: models.PodPending}
+}
+
+func TestExtenderFilterRemovesNodes(t *testing.T) {
+	extender := startStubExtender(t, &stubextender.Extender{RejectKey: "disk", RejectValue: "hdd"}, ExtenderConfig{FilterVerb: "filter"})
+	profile := newTestProfile(t, extender)
+	nodes := testNodes(map[string]string{"disk": "ssd"}, map[string]string{"disk": "hdd"}, nil)
+
+	failures := make(map[string]error)
+	feasible, err := profile.runExtenderFilters(testPod(), nodes, failures)
+	if err != nil {
+		t.Fatalf("runExtenderFilters: %v", err)
+	}
+	if got := strings.Join(nodeNames(feasible), ","); got != "n1,n3" {
+		t.Errorf("feasible nodes = %s, want n1,n3", got)
+	}
+	if len(failures) != 1 || failures["n2"] == nil {
+		t.Fatalf("failures = %v, want only n2", failures)
+	}
+	if !strings.Contains(failures["n2"].Error(), "disk=hdd") {
+		t.Errorf("failure of n2 = %q, want the extender's reason", failures["n2"])
+	}
+}
+
+func TestExtenderPrioritizeScaledByWeight(t *testing.T) {
+	const weight = 3
+	extender := startStubExtender(t, &stubextender.Extender{PreferKey: "zone", PreferValue: "a"},
+		ExtenderConfig{PrioritizeVerb: "prioritize", Weight: weight})
+	profile := newTestProfile(t, extender)
+	nodes := testNodes(map[string]string{"zone": "a"}, map[string]string{"zone": "b"})
+
+	scores := profile.runScores(CycleState{}, testPod(), nodes)
+	pluginName := "extender " + extender.Name()
+	want := map[string]int64{"n1": MaxNodeScore * weight, "n2": 0}
+	for node, score := range want {
+		got, ok := scores[node].Plugins[pluginName]
+		if !ok {
+			t.Fatalf("node %s has no score from the extender: %v", node, scores[node].Plugins)
+		}
+		if got != score {
+			t.Errorf("extender score of %s = %d, want %d", node, got, score)
+		}
+	}
+}
+
+// fakePodAPI serves just enough of the API server for a pod to be bound and its events recorded
+type fakePodAPI struct {
+	mu     sync.Mutex
+	pods   map[string]models.Pod
+	events []models.Event
+}
+
+func startFakePodAPI(t *testing.T, pods ...models.Pod) (*fakePodAPI, *client.Client) {
+	t.Helper()
+	api := &fakePodAPI{pods: make(map[string]models.Pod)}
+	for _, pod := range pods {
+		api.pods[pod.Namespace+"/"+pod.Name] = pod
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/namespace/{namespace}/pods/{name}", func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		pod, ok := api.pods[r.PathValue("namespace")+"/"+r.PathValue("name")]
+		api.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(pod)
+	})
+	mux.HandleFunc("PUT /api/v1/namespace/{namespace}/pods/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var pod models.Pod
+		if err := json.NewDecoder(r.Body).Decode(&pod); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.mu.Lock()
+		api.pods[pod.Namespace+"/"+pod.Name] = pod
+		api.mu.Unlock()
+		json.NewEncoder(w).Encode(pod)
+	})
+	mux.HandleFunc("POST /api/v1/namespace/{namespace}/events", func(w http.ResponseWriter, r *http.Request) {
+		var event models.Event
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		api.mu.Lock()
+		api.events = append(api.events, event)
+		api.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(event)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	cl, err := client.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("NewClient: %v", err)
+	}
+	return api, cl
+}
+
+func TestExtenderDelegatedBind(t *testing.T) {
+	pod := testPod()
+	api, cl := startFakePodAPI(t, *pod)
+	extender := startStubExtender(t, &stubextender.Extender{Client: cl}, ExtenderConfig{BindVerb: "bind"})
+	profile := newTestProfile(t, extender)
+	if profile.binder() != extender {
+		t.Fatalf("profile does not bind through the extender")
+	}
+
+	s := &Scheduler{Client: cl}
+	if err := s.bind(profile, &queuedPodInfo{pod: pod}, "n2"); err != nil {
+		t.Fatalf("bind: %v", err)
+	}
+
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	bound := api.pods["default/web"]
+	if bound.NodeName != "n2" || bound.Phase != models.PodScheduled {
+		t.Errorf("pod is on node %q in phase %s, want n2 and %s", bound.NodeName, bound.Phase, models.PodScheduled)
+	}
+	if len(api.events) != 1 || api.events[0].Reason != reasonScheduled {
+		t.Errorf("events = %v, want a single %s event", api.events, reasonScheduled)
+	}
+}
+
+func TestExtenderTimeout(t *testing.T) {
+	for _, ignorable := range []bool{true, false} {
+		name := "not ignorable"
+		if ignorable {
+			name = "ignorable"
+		}
+		t.Run(name, func(t *testing.T) {
+			// the stub would reject n2 if it answered in time
+			stub := &stubextender.Extender{RejectKey: "disk", RejectValue: "hdd", Delay: 200 * time.Millisecond}
+			extender := startStubExtender(t, stub, ExtenderConfig{FilterVerb: "filter", HTTPTimeout: "20ms", Ignorable: ignorable})
+			profile := newTestProfile(t, extender)
+			nodes := testNodes(map[string]string{"disk": "ssd"}, map[string]string{"disk": "hdd"})
+
+			feasible, err := profile.runExtenderFilters(testPod(), nodes, make(map[string]error))
+			if !ignorable {
+				if err == nil {
+					t.Fatalf("runExtenderFilters succeeded with feasible nodes %v, want a timeout", nodeNames(feasible))
+				}
+				if !strings.Contains(err.Error(), "Timeout") {
+					t.Errorf("error = %q, want a timeout", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runExtenderFilters: %v", err)
+			}
+			if got := strings.Join(nodeNames(feasible), ","); got != "n1,n2" {
+				t.Errorf("feasible nodes = %s, want every node once the extender is skipped", got)
+			}
+		})
+	}
+}
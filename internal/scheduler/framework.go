@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// MaxNodeScore is the upper bound of a normalized plugin score
+const MaxNodeScore int64 = 100
+
+// CycleState carries data computed once per scheduling attempt between the extension points of the plugins
+type CycleState map[string]any
+
+// StateData is cycle state that preemption changes while it tries out victims, so it is copied for every node
+type StateData interface {
+	Clone() StateData
+}
+
+// Clone copies the state, deeply for the StateData entries, so changes to the copy leave the original alone
+func (s CycleState) Clone() CycleState {
+	clone := make(CycleState, len(s))
+	for key, value := range s {
+		if data, ok := value.(StateData); ok {
+			value = data.Clone()
+		}
+		clone[key] = value
+	}
+	return clone
+}
+
+// A PreFilterPlugin computes whatever its filter or score needs from the whole cluster before nodes are checked
+type PreFilterPlugin interface {
+	Name() string
+	PreFilter(state CycleState, pod *models.Pod, snapshot []*NodeInfo) error
+}
+
+// PreFilterExtensions is implemented by a PreFilterPlugin whose state depends on the pods already placed. Preemption
+// calls it as it takes pods off a node and puts them back, so the filters judge the node as it would then be
+type PreFilterExtensions interface {
+	AddPod(state CycleState, pod, podToAdd *models.Pod, nodeInfo *NodeInfo) error
+	RemovePod(state CycleState, pod, podToRemove *models.Pod, nodeInfo *NodeInfo) error
+}
+
+// A FilterPlugin rules out nodes that cannot run a pod, returning the reason as an error
+type FilterPlugin interface {
+	Name() string
+	Filter(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) error
+}
+
+// A ScorePlugin ranks the feasible nodes. NormalizeScore rescales the raw scores into [0, MaxNodeScore]
+type ScorePlugin interface {
+	Name() string
+	Score(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) int64
+	NormalizeScore(state CycleState, pod *models.Pod, scores map[string]int64)
+}
+
+// normalizeReverse maps the lowest raw score to MaxNodeScore and the highest to 0
+func normalizeReverse(scores map[string]int64) {
+	if len(scores) == 0 {
+		return
+	}
+	var lowest, highest int64
+	first := true
+	for _, score := range scores {
+		if first || score < lowest {
+			lowest = score
+		}
+		if first || score > highest {
+			highest = score
+		}
+		first = false
+	}
+	for name, score := range scores {
+		if highest == lowest {
+			scores[name] = MaxNodeScore
+			continue
+		}
+		scores[name] = MaxNodeScore * (highest - score) / (highest - lowest)
+	}
+}
@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// a member that found a node but is waiting for the rest of its group. It holds the node's resources in every
+// snapshot until the group is bound or released
+type gangReservation struct {
+	podInfo  *queuedPodInfo
+	profile  *Profile
+	nodeName string
+}
+
+type gang struct {
+	group    *models.PodGroup
+	reserved map[string]*gangReservation
+	timer    *time.Timer
+}
+
+type gangManager struct {
+	mu    sync.Mutex
+	gangs map[string]*gang
+}
+
+func newGangManager() *gangManager {
+	return &gangManager{gangs: make(map[string]*gang)}
+}
+
+func podGroupKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// addReservationsTo counts reserved members against the nodes they are waiting on
+func (gm *gangManager) addReservationsTo(snapshot []*NodeInfo) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	for _, g := range gm.gangs {
+		for _, r := range g.reserved {
+			for _, nodeInfo := range snapshot {
+				if nodeInfo.Node.Name == r.nodeName {
+					reservedPod := *r.podInfo.pod
+					reservedPod.NodeName = r.nodeName
+					nodeInfo.addPod(&reservedPod)
+				}
+			}
+		}
+	}
+}
+
+// take removes a gang from the manager so its reservations can be bound or released outside the lock
+func (gm *gangManager) take(key string) *gang {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	g, ok := gm.gangs[key]
+	if !ok {
+		return nil
+	}
+	g.timer.Stop()
+	delete(gm.gangs, key)
+	return g
+}
+
+// dropReservation gives up what a deleted pod held. Deletion events only carry the pod's name and namespace, so
+// the reservation is looked up by the pod alone rather than through its group
+func (gm *gangManager) dropReservation(pod *models.Pod) {
+	gm.mu.Lock()
+	defer gm.mu.Unlock()
+
+	for _, g := range gm.gangs {
+		delete(g.reserved, podKey(pod))
+	}
+}
+
+// countGangMembers counts the members of a group that are not being deleted, all of them or only the bound ones
+func countGangMembers(pods []models.Pod, namespace, group string, boundOnly bool) int {
+	members := 0
+	for i := range pods {
+		pod := &pods[i]
+		if pod.PodGroupName != group || pod.Namespace != namespace || pod.DeletionTimestamp != nil || pod.Phase == models.PodDeleted {
+			continue
+		}
+		if !boundOnly || pod.NodeName != "" {
+			members++
+		}
+	}
+	return members
+}
+
+// checkGangQuorum fails fast while the group does not even have enough members to reach minMember
+func (s *Scheduler) checkGangQuorum(pod *models.Pod, pods []models.Pod) (*models.PodGroup, error) {
+	group, err := s.Client.GetPodGroup(pod.Namespace, pod.PodGroupName)
+	if err != nil {
+		return nil, &FitError{Pod: pod, Message: fmt.Sprintf("pod group %s could not be fetched: %v", pod.PodGroupName, err)}
+	}
+
+	members := countGangMembers(pods, group.Namespace, group.Name, false)
+	if members < int(group.MinMember) {
+		return nil, &FitError{Pod: pod, Message: fmt.Sprintf("pod group %s has %d of the %d members it needs", group.Name, members, group.MinMember)}
+	}
+	return group, nil
+}
+
+// reserveGangMember holds the chosen node for a group member. Once enough members hold a node, or are already
+// bound from an earlier round, every reserved member is bound together. Should one fail to bind, the members not
+// bound yet give up their reservations and retry, and the group is reported as partially bound
+func (s *Scheduler) reserveGangMember(profile *Profile, podInfo *queuedPodInfo, group *models.PodGroup, nodeName string, pods []models.Pod) {
+	key := podGroupKey(group.Namespace, group.Name)
+
+	s.gangs.mu.Lock()
+	g, ok := s.gangs.gangs[key]
+	if !ok {
+		timeout := time.Duration(group.ScheduleTimeoutSeconds) * time.Second
+		if timeout <= 0 {
+			timeout = models.DefaultPodGroupScheduleTimeoutSeconds * time.Second
+		}
+		g = &gang{
+			group:    group,
+			reserved: make(map[string]*gangReservation),
+			timer:    time.AfterFunc(timeout, func() { s.releaseGang(key, "timed out waiting for members") }),
+		}
+		s.gangs.gangs[key] = g
+	}
+	g.reserved[podKey(podInfo.pod)] = &gangReservation{podInfo: podInfo, profile: profile, nodeName: nodeName}
+
+	bound := countGangMembers(pods, group.Namespace, group.Name, true)
+	ready := bound+len(g.reserved) >= int(group.MinMember)
+	waiting := len(g.reserved)
+	s.gangs.mu.Unlock()
+
+	if !ready {
+		log.Printf("Reserved node %s for pod %s/%s, pod group %s is waiting with %d of %d members",
+			nodeName, podInfo.pod.Namespace, podInfo.pod.Name, group.Name, bound+waiting, group.MinMember)
+		return
+	}
+
+	g = s.gangs.take(key)
+	if g == nil {
+		return
+	}
+	log.Printf("Pod group %s has enough members, binding %d reserved pods", group.Name, len(g.reserved))
+	reserved := len(g.reserved)
+	for member, r := range g.reserved {
+		if err := s.bind(r.profile, r.podInfo, r.nodeName); err != nil {
+			// the members bound so far keep their nodes and count towards the group when the rest retry
+			message := fmt.Sprintf("Pod group %s is partially bound, %d of %d reserved members were bound before pod %s failed to bind: %v",
+				group.Name, reserved-len(g.reserved), reserved, r.podInfo.pod.Name, err)
+			log.Print(message)
+			s.recordEvent(r.profile, r.podInfo.pod, models.EventWarning, reasonPartiallyBound, message, nil)
+			s.requeueGang(g, "binding failed")
+			return
+		}
+		delete(g.reserved, member)
+	}
+}
+
+// releaseGang gives up every reservation held by a group and sends its members back to retry later
+func (s *Scheduler) releaseGang(key, reason string) {
+	g := s.gangs.take(key)
+	if g == nil {
+		return
+	}
+	s.requeueGang(g, reason)
+}
+
+// requeueGang sends the members still holding a reservation in a gang taken from the manager back to retry later
+func (s *Scheduler) requeueGang(g *gang, reason string) {
+	log.Printf("Releasing %d reservations of pod group %s: %s", len(g.reserved), g.group.Name, reason)
+	for _, r := range g.reserved {
+		s.queue.AddBackoff(r.podInfo)
+	}
+	s.queue.MoveAllToActiveOrBackoff("PodGroupReleased")
+}
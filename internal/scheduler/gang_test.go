@@ -0,0 +1,44 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+func gangMember(name string) *models.Pod {
+	return &models.Pod{Name: name, Namespace: "default", PodGroupName: "workers", Phase: models.PodPending}
+}
+
+func TestDeletedGangMemberGivesUpReservation(t *testing.T) {
+	// without a client, binding the group would panic, so reaching quorum fails the test
+	s := &Scheduler{queue: newSchedulingQueue(), gangs: newGangManager()}
+	profile := newTestProfile(t)
+	group := &models.PodGroup{Name: "workers", Namespace: "default", MinMember: 3, ScheduleTimeoutSeconds: 3600}
+	// taking the gang stops its timeout
+	t.Cleanup(func() { s.gangs.take(podGroupKey(group.Namespace, group.Name)) })
+
+	s.reserveGangMember(profile, &queuedPodInfo{pod: gangMember("a")}, group, "n1", nil)
+	s.reserveGangMember(profile, &queuedPodInfo{pod: gangMember("b")}, group, "n1", nil)
+
+	// the deletion event carries only the pod's name and namespace
+	s.handlePodEvent(models.WatchEvent{EventType: models.DeletionEvent, Pod: &models.Pod{Name: "a", Namespace: "default"}})
+
+	snapshot := testNodes(nil)
+	s.gangs.addReservationsTo(snapshot)
+	if got := len(snapshot[0].Pods); got != 1 {
+		t.Errorf("node holds %d reserved pods, want 1 once a is deleted", got)
+	}
+
+	s.reserveGangMember(profile, &queuedPodInfo{pod: gangMember("c")}, group, "n1", nil)
+	s.gangs.mu.Lock()
+	g, ok := s.gangs.gangs[podGroupKey(group.Namespace, group.Name)]
+	var reserved int
+	if ok {
+		reserved = len(g.reserved)
+	}
+	s.gangs.mu.Unlock()
+	if !ok || reserved != 2 {
+		t.Errorf("gang holds %d reservations, want b and c still waiting for a third member", reserved)
+	}
+}
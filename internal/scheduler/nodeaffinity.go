@@ -0,0 +1,51 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// nodeAffinity keeps pods on nodes matching their nodeSelector and required node affinity
+type nodeAffinity struct{}
+
+func (nodeAffinity) Name() string { return "NodeAffinity" }
+
+func (nodeAffinity) Filter(_ CycleState, pod *models.Pod, nodeInfo *NodeInfo) error {
+	if !pod.MatchesNodeLabels(nodeInfo.Node.Labels) {
+		return fmt.Errorf("node does not match the pod's node selector or affinity")
+	}
+	return nil
+}
+
+// taintToleration rules out nodes with NoSchedule or NoExecute taints the pod does not tolerate, and ranks nodes
+// lower the more untolerated PreferNoSchedule taints they carry
+type taintToleration struct{}
+
+func (taintToleration) Name() string { return "TaintToleration" }
+
+func (taintToleration) Filter(_ CycleState, pod *models.Pod, nodeInfo *NodeInfo) error {
+	for _, taint := range nodeInfo.Node.Taints {
+		if taint.Effect == models.TaintEffectPreferNoSchedule {
+			continue
+		}
+		if !pod.ToleratesTaint(taint) {
+			return fmt.Errorf("node has taint %s=%s:%s that the pod does not tolerate", taint.Key, taint.Value, taint.Effect)
+		}
+	}
+	return nil
+}
+
+func (taintToleration) Score(_ CycleState, pod *models.Pod, nodeInfo *NodeInfo) int64 {
+	var intolerable int64
+	for _, taint := range nodeInfo.Node.Taints {
+		if taint.Effect == models.TaintEffectPreferNoSchedule && !pod.ToleratesTaint(taint) {
+			intolerable++
+		}
+	}
+	return intolerable
+}
+
+func (taintToleration) NormalizeScore(_ CycleState, _ *models.Pod, scores map[string]int64) {
+	normalizeReverse(scores)
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// NodeInfo is a node together with the pods currently assigned to it and what they request
+type NodeInfo struct {
+	Node      *models.Node
+	Pods      []*models.Pod
+	Requested models.ResourceList
+}
+
+func newNodeInfo(node *models.Node) *NodeInfo {
+	return &NodeInfo{Node: node}
+}
+
+func (n *NodeInfo) addPod(pod *models.Pod) {
+	n.Pods = append(n.Pods, pod)
+	n.Requested = addResources(n.Requested, pod.SchedulingRequests())
+}
+
+func (n *NodeInfo) removePod(pod *models.Pod) {
+	for i, p := range n.Pods {
+		if podKey(p) == podKey(pod) {
+			n.Pods = append(n.Pods[:i:i], n.Pods[i+1:]...)
+			n.Requested = subtractResources(n.Requested, pod.SchedulingRequests())
+			return
+		}
+	}
+}
+
+func (n *NodeInfo) clone() *NodeInfo {
+	return &NodeInfo{
+		Node:      n.Node,
+		Pods:      append([]*models.Pod(nil), n.Pods...),
+		Requested: n.Requested,
+	}
+}
+
+// buildSnapshot groups the live pods by the node they are bound to. Terminated pods no longer hold any resources
+func buildSnapshot(nodes []models.Node, pods []models.Pod) []*NodeInfo {
+	nodeInfos := make([]*NodeInfo, 0, len(nodes))
+	byName := make(map[string]*NodeInfo, len(nodes))
+	for i := range nodes {
+		info := newNodeInfo(&nodes[i])
+		nodeInfos = append(nodeInfos, info)
+		byName[nodes[i].Name] = info
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.NodeName == "" || pod.IsTerminated() {
+			continue
+		}
+		if info, ok := byName[pod.NodeName]; ok {
+			info.addPod(pod)
+		}
+	}
+	return nodeInfos
+}
+
+func podKey(pod *models.Pod) string {
+	return pod.Namespace + "/" + pod.Name
+}
+
+func addResources(a, b models.ResourceList) models.ResourceList {
+	return models.ResourceList{
+		CPU:    a.CPU + b.CPU,
+		Memory: a.Memory + b.Memory,
+		Pods:   a.Pods + b.Pods,
+	}
+}
+
+func subtractResources(a, b models.ResourceList) models.ResourceList {
+	return models.ResourceList{
+		CPU:    a.CPU - b.CPU,
+		Memory: a.Memory - b.Memory,
+		Pods:   a.Pods - b.Pods,
+	}
+}
@@ -0,0 +1,69 @@
+package scheduler
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+type nodeResourcesFit struct{}
+
+func (nodeResourcesFit) Name() string { return "NodeResourcesFit" }
+
+func (nodeResourcesFit) Filter(_ CycleState, pod *models.Pod, nodeInfo *NodeInfo) error {
+	capacity := nodeInfo.Node.Capacity
+	requested := addResources(nodeInfo.Requested, pod.SchedulingRequests())
+
+	if capacity.CPU > 0 && requested.CPU > capacity.CPU {
+		return fmt.Errorf("insufficient cpu")
+	}
+	if capacity.Memory > 0 && requested.Memory > capacity.Memory {
+		return fmt.Errorf("insufficient memory")
+	}
+	if capacity.Pods > 0 && requested.Pods > capacity.Pods {
+		return fmt.Errorf("too many pods")
+	}
+	return nil
+}
+
+// nodeResourcesAllocated scores nodes by the share of their capacity that would be requested once the pod is
+// placed. Least allocated spreads load across nodes, most allocated packs pods onto as few nodes as possible
+type nodeResourcesAllocated struct {
+	mostAllocated bool
+}
+
+func (p nodeResourcesAllocated) Name() string {
+	if p.mostAllocated {
+		return "NodeResourcesMostAllocated"
+	}
+	return "NodeResourcesLeastAllocated"
+}
+
+func (p nodeResourcesAllocated) Score(_ CycleState, pod *models.Pod, nodeInfo *NodeInfo) int64 {
+	capacity := nodeInfo.Node.Capacity
+	requested := addResources(nodeInfo.Requested, pod.SchedulingRequests())
+
+	// unbounded resources say nothing about how full a node is, so only bounded ones are averaged
+	var total, counted int64
+	for _, r := range []struct{ requested, capacity int64 }{
+		{requested.CPU, capacity.CPU},
+		{requested.Memory, capacity.Memory},
+	} {
+		if r.capacity <= 0 {
+			continue
+		}
+		used := min(r.requested, r.capacity)
+		if p.mostAllocated {
+			total += used * MaxNodeScore / r.capacity
+		} else {
+			total += (r.capacity - used) * MaxNodeScore / r.capacity
+		}
+		counted++
+	}
+	if counted == 0 {
+		return 0
+	}
+	return total / counted
+}
+
+func (nodeResourcesAllocated) NormalizeScore(CycleState, *models.Pod, map[string]int64) {}
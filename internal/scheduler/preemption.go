@@ -0,0 +1,152 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+type preemptionCandidate struct {
+	nodeInfo *NodeInfo
+	victims  []*models.Pod
+}
+
+// podEligibleToPreempt stops a pod from preempting again while the victims of its last preemption are still terminating
+func podEligibleToPreempt(pod *models.Pod, snapshot []*NodeInfo) bool {
+	if pod.PreemptionPolicy == models.PreemptNever {
+		return false
+	}
+	if pod.NominatedNodeName == "" {
+		return true
+	}
+	for _, nodeInfo := range snapshot {
+		if nodeInfo.Node.Name != pod.NominatedNodeName {
+			continue
+		}
+		for _, p := range nodeInfo.Pods {
+			if p.DeletionTimestamp != nil && p.Priority < pod.Priority {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// selectVictimsOnNode removes every lower priority pod from the node and then adds back as many as possible,
+// highest priority first, so that the smallest and least important set of pods is evicted
+func (p *Profile) selectVictimsOnNode(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) ([]*models.Pod, bool) {
+	info := nodeInfo.clone()
+	// the prefilters' state follows the pods taken off this node and put back, without touching the other nodes'
+	state = state.Clone()
+
+	var potentialVictims []*models.Pod
+	for _, victim := range nodeInfo.Pods {
+		// pods already being deleted will free their resources without our help
+		if victim.Priority < pod.Priority && victim.DeletionTimestamp == nil {
+			potentialVictims = append(potentialVictims, victim)
+			info.removePod(victim)
+			if p.runPreFilterRemovePod(state, pod, victim, info) != nil {
+				return nil, false
+			}
+		}
+	}
+	if len(potentialVictims) == 0 {
+		return nil, false
+	}
+	if p.runFilters(state, pod, info) != nil {
+		return nil, false
+	}
+
+	sort.SliceStable(potentialVictims, func(i, j int) bool {
+		return potentialVictims[i].Priority > potentialVictims[j].Priority
+	})
+
+	var victims []*models.Pod
+	for _, victim := range potentialVictims {
+		info.addPod(victim)
+		if p.runPreFilterAddPod(state, pod, victim, info) != nil {
+			return nil, false
+		}
+		if p.runFilters(state, pod, info) == nil {
+			continue
+		}
+		info.removePod(victim)
+		if p.runPreFilterRemovePod(state, pod, victim, info) != nil {
+			return nil, false
+		}
+		victims = append(victims, victim)
+	}
+	return victims, true
+}
+
+// pickPreemptionCandidate prefers the node whose most important victim has the lowest priority, then the
+// lowest total victim priority, then the fewest victims
+func pickPreemptionCandidate(candidates []preemptionCandidate) *preemptionCandidate {
+	var best *preemptionCandidate
+	var bestMax, bestSum int64
+	for i := range candidates {
+		candidate := &candidates[i]
+		var maxPriority, sumPriority int64
+		for j, v := range candidate.victims {
+			if j == 0 || int64(v.Priority) > maxPriority {
+				maxPriority = int64(v.Priority)
+			}
+			sumPriority += int64(v.Priority)
+		}
+
+		switch {
+		case best == nil,
+			maxPriority < bestMax,
+			maxPriority == bestMax && sumPriority < bestSum,
+			maxPriority == bestMax && sumPriority == bestSum && len(candidate.victims) < len(best.victims):
+			best, bestMax, bestSum = candidate, maxPriority, sumPriority
+		}
+	}
+	return best
+}
+
+func (p *Profile) findPreemptionCandidate(state CycleState, pod *models.Pod, snapshot []*NodeInfo) *preemptionCandidate {
+	if !podEligibleToPreempt(pod, snapshot) {
+		log.Printf("Pod %s/%s is not eligible to preempt other pods", pod.Namespace, pod.Name)
+		return nil
+	}
+
+	var candidates []preemptionCandidate
+	for _, nodeInfo := range snapshot {
+		if victims, ok := p.selectVictimsOnNode(state, pod, nodeInfo); ok {
+			candidates = append(candidates, preemptionCandidate{nodeInfo: nodeInfo, victims: victims})
+		}
+	}
+	return pickPreemptionCandidate(candidates)
+}
+
+// preempt marks the victims for graceful deletion and nominates the node for the preemptor, which is
+// bound once the victims are gone. It returns a description of what it did for the scheduling diagnosis
+func (s *Scheduler) preempt(profile *Profile, state CycleState, pod *models.Pod, snapshot []*NodeInfo) string {
+	candidate := profile.findPreemptionCandidate(state, pod, snapshot)
+	if candidate == nil {
+		log.Printf("No node can make room for pod %s/%s by preemption", pod.Namespace, pod.Name)
+		return "preemption found no node where evicting lower priority pods would make room"
+	}
+	nodeName := candidate.nodeInfo.Node.Name
+
+	for _, victim := range candidate.victims {
+		if err := s.Client.DeletePod(victim.Namespace, victim.Name); err != nil {
+			log.Printf("Error preempting pod %s/%s on node %s: %v", victim.Namespace, victim.Name, nodeName, err)
+			return fmt.Sprintf("preemption on node %s failed: %v", nodeName, err)
+		}
+		log.Printf("Preempted pod %s/%s (priority %d) on node %s for pod %s/%s (priority %d)",
+			victim.Namespace, victim.Name, victim.Priority, nodeName, pod.Namespace, pod.Name, pod.Priority)
+	}
+
+	nominatedPod := *pod
+	nominatedPod.NominatedNodeName = nodeName
+	if _, err := s.Client.UpdatePod(&nominatedPod); err != nil {
+		log.Printf("Error nominating node %s for pod %s/%s: %v", nodeName, pod.Namespace, pod.Name, err)
+		return fmt.Sprintf("nominating node %s failed: %v", nodeName, err)
+	}
+	log.Printf("Nominated node %s for pod %s/%s", nodeName, pod.Namespace, pod.Name)
+	return fmt.Sprintf("preempted %d pods on node %s, which is nominated for this pod", len(candidate.victims), nodeName)
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// n1 in zone a runs two low priority replicas, n2 in zone b has no room left. The preemptor is only kept off n1 by
+// its spread constraint, which evicting the replicas there satisfies
+func TestPreemptionFreesSpotBlockedBySkew(t *testing.T) {
+	web := map[string]string{"app": "web"}
+	nodes := []models.Node{
+		{Name: "n1", Status: models.NodeReady, Labels: map[string]string{"zone": "a"}, Capacity: models.ResourceList{CPU: 4000, Memory: 1 << 30, Pods: 10}},
+		{Name: "n2", Status: models.NodeReady, Labels: map[string]string{"zone": "b"}, Capacity: models.ResourceList{CPU: 1000, Memory: 1 << 30, Pods: 10}},
+	}
+	pods := []models.Pod{
+		{Name: "web-1", Namespace: "default", NodeName: "n1", Phase: models.PodRunning, Priority: 1, Labels: web},
+		{Name: "web-2", Namespace: "default", NodeName: "n1", Phase: models.PodRunning, Priority: 2, Labels: web},
+		{Name: "db", Namespace: "default", NodeName: "n2", Phase: models.PodRunning, Priority: 100,
+			Resources: models.ResourceRequirements{Requests: models.ResourceList{CPU: 1000}}},
+	}
+	preemptor := &models.Pod{
+		Name: "web-3", Namespace: "default", Phase: models.PodPending, Priority: 10, Labels: web,
+		Resources: models.ResourceRequirements{Requests: models.ResourceList{CPU: 500}},
+		TopologySpreadConstraints: []models.TopologySpreadConstraint{{
+			MaxSkew:           1,
+			TopologyKey:       "zone",
+			WhenUnsatisfiable: models.DoNotSchedule,
+			LabelSelector:     &models.LabelSelector{MatchLabels: web},
+		}},
+	}
+
+	profile := newTestProfile(t)
+	snapshot := buildSnapshot(nodes, pods)
+	state := CycleState{}
+	if err := profile.runPreFilters(state, preemptor, snapshot); err != nil {
+		t.Fatalf("runPreFilters: %v", err)
+	}
+	if feasible, _ := profile.findFeasibleNodes(state, preemptor, snapshot, pods); len(feasible) != 0 {
+		t.Fatalf("feasible nodes = %v, want none before preemption", nodeNames(feasible))
+	}
+
+	candidate := profile.findPreemptionCandidate(state, preemptor, snapshot)
+	if candidate == nil {
+		t.Fatal("no preemption candidate, want n1")
+	}
+	if candidate.nodeInfo.Node.Name != "n1" {
+		t.Errorf("candidate node = %s, want n1", candidate.nodeInfo.Node.Name)
+	}
+	// with one replica left in zone a the skew would be 2, so both go
+	if len(candidate.victims) != 2 {
+		t.Errorf("victims = %d, want both replicas on n1", len(candidate.victims))
+	}
+
+	// the counts of the scheduling attempt itself are left as they were
+	spread := state[topologySpreadStateKey].(*topologySpreadState)
+	if got := spread.constraints[0].counts["a"]; got != 2 {
+		t.Errorf("zone a count after preemption = %d, want 2", got)
+	}
+}
@@ -0,0 +1,238 @@
+package scheduler
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+type weightedScorePlugin struct {
+	ScorePlugin
+	weight int64
+}
+
+// A Profile is one named scheduling policy: the plugins it runs and its own round-robin position
+type Profile struct {
+	SchedulerName string
+
+	preFilters  []PreFilterPlugin
+	filters     []FilterPlugin
+	scorers     []weightedScorePlugin
+	extenders   []*HTTPExtender
+	nextNodeIdx int
+}
+
+func NewProfile(cfg ProfileConfig, extenders []*HTTPExtender) (*Profile, error) {
+	if cfg.SchedulerName == "" {
+		return nil, fmt.Errorf("a scheduler profile must have a schedulerName")
+	}
+	filterNames := cfg.Filters
+	if len(filterNames) == 0 {
+		filterNames = defaultFilters
+	}
+	scoreConfigs := cfg.Scores
+	if len(scoreConfigs) == 0 {
+		scoreConfigs = defaultScores
+	}
+
+	profile := &Profile{SchedulerName: cfg.SchedulerName, extenders: extenders}
+	preFilterSeen := make(map[string]bool)
+	addPreFilter := func(plugin any) {
+		if preFilter, ok := plugin.(PreFilterPlugin); ok && !preFilterSeen[preFilter.Name()] {
+			preFilterSeen[preFilter.Name()] = true
+			profile.preFilters = append(profile.preFilters, preFilter)
+		}
+	}
+
+	for _, name := range filterNames {
+		factory, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown plugin %s", cfg.SchedulerName, name)
+		}
+		plugin := factory()
+		filter, ok := plugin.(FilterPlugin)
+		if !ok {
+			return nil, fmt.Errorf("profile %s: plugin %s is not a filter plugin", cfg.SchedulerName, name)
+		}
+		profile.filters = append(profile.filters, filter)
+		addPreFilter(plugin)
+	}
+
+	for _, scoreCfg := range scoreConfigs {
+		factory, ok := registry[scoreCfg.Name]
+		if !ok {
+			return nil, fmt.Errorf("profile %s: unknown plugin %s", cfg.SchedulerName, scoreCfg.Name)
+		}
+		plugin := factory()
+		scorer, ok := plugin.(ScorePlugin)
+		if !ok {
+			return nil, fmt.Errorf("profile %s: plugin %s is not a score plugin", cfg.SchedulerName, scoreCfg.Name)
+		}
+		weight := scoreCfg.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		profile.scorers = append(profile.scorers, weightedScorePlugin{ScorePlugin: scorer, weight: weight})
+		addPreFilter(plugin)
+	}
+	return profile, nil
+}
+
+func (p *Profile) runPreFilters(state CycleState, pod *models.Pod, snapshot []*NodeInfo) error {
+	for _, plugin := range p.preFilters {
+		if err := plugin.PreFilter(state, pod, snapshot); err != nil {
+			return fmt.Errorf("%s: %w", plugin.Name(), err)
+		}
+	}
+	return nil
+}
+
+// runPreFilterAddPod tells the prefilters that podToAdd now runs on the node
+func (p *Profile) runPreFilterAddPod(state CycleState, pod, podToAdd *models.Pod, nodeInfo *NodeInfo) error {
+	for _, plugin := range p.preFilters {
+		if extensions, ok := plugin.(PreFilterExtensions); ok {
+			if err := extensions.AddPod(state, pod, podToAdd, nodeInfo); err != nil {
+				return fmt.Errorf("%s: %w", plugin.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// runPreFilterRemovePod tells the prefilters that podToRemove no longer runs on the node
+func (p *Profile) runPreFilterRemovePod(state CycleState, pod, podToRemove *models.Pod, nodeInfo *NodeInfo) error {
+	for _, plugin := range p.preFilters {
+		if extensions, ok := plugin.(PreFilterExtensions); ok {
+			if err := extensions.RemovePod(state, pod, podToRemove, nodeInfo); err != nil {
+				return fmt.Errorf("%s: %w", plugin.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Profile) runFilters(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) error {
+	for _, filter := range p.filters {
+		if err := filter.Filter(state, pod, nodeInfo); err != nil {
+			return fmt.Errorf("%s: %w", filter.Name(), err)
+		}
+	}
+	return nil
+}
+
+// runScores returns every feasible node's weighted total along with what each plugin and extender contributed
+func (p *Profile) runScores(state CycleState, pod *models.Pod, feasible []*NodeInfo) map[string]*models.NodeScore {
+	scores := make(map[string]*models.NodeScore, len(feasible))
+	for _, nodeInfo := range feasible {
+		scores[nodeInfo.Node.Name] = &models.NodeScore{Node: nodeInfo.Node.Name, Plugins: make(map[string]int64)}
+	}
+
+	for _, plugin := range p.scorers {
+		pluginScores := make(map[string]int64, len(feasible))
+		for _, nodeInfo := range feasible {
+			pluginScores[nodeInfo.Node.Name] = plugin.Score(state, pod, nodeInfo)
+		}
+		plugin.NormalizeScore(state, pod, pluginScores)
+		for name, score := range pluginScores {
+			scores[name].Plugins[plugin.Name()] = score * plugin.weight
+			scores[name].Total += score * plugin.weight
+		}
+	}
+
+	// a failing prioritize call only costs the extender its say in the ranking
+	for _, extender := range p.extenders {
+		extenderScores, err := extender.Prioritize(pod, feasible)
+		if err != nil {
+			log.Printf("Ignoring scores from extender %s: %v", extender.Name(), err)
+			continue
+		}
+		for name, score := range extenderScores {
+			if nodeScore, ok := scores[name]; ok {
+				nodeScore.Plugins["extender "+extender.Name()] = score
+				nodeScore.Total += score
+			}
+		}
+	}
+	return scores
+}
+
+// runExtenderFilters narrows the feasible nodes through every filtering extender in turn
+func (p *Profile) runExtenderFilters(pod *models.Pod, feasible []*NodeInfo, failures map[string]error) ([]*NodeInfo, error) {
+	for _, extender := range p.extenders {
+		if len(feasible) == 0 {
+			break
+		}
+		filtered, failed, err := extender.Filter(pod, feasible)
+		if err != nil {
+			if extender.IsIgnorable() {
+				log.Printf("Skipping ignorable extender %s: %v", extender.Name(), err)
+				continue
+			}
+			return nil, err
+		}
+		for name, reason := range failed {
+			failures[name] = fmt.Errorf("extender %s: %s", extender.Name(), reason)
+		}
+		feasible = filtered
+	}
+	return feasible, nil
+}
+
+// binder returns the extender that binds pods for this profile, if any
+func (p *Profile) binder() *HTTPExtender {
+	for _, extender := range p.extenders {
+		if extender.IsBinder() {
+			return extender
+		}
+	}
+	return nil
+}
+
+// findFeasibleNodes runs the filters against every node. Pods nominated to a node with at least the same priority
+// are counted as already running there, so a preemptor's room is not taken by someone less important
+func (p *Profile) findFeasibleNodes(state CycleState, pod *models.Pod, snapshot []*NodeInfo, pods []models.Pod) ([]*NodeInfo, map[string]error) {
+	var feasible []*NodeInfo
+	failures := make(map[string]error)
+
+	for _, nodeInfo := range snapshot {
+		info := nodeInfo
+		for i := range pods {
+			nominated := &pods[i]
+			if nominated.NominatedNodeName == nodeInfo.Node.Name && nominated.NodeName == "" &&
+				nominated.Priority >= pod.Priority && podKey(nominated) != podKey(pod) {
+				if info == nodeInfo {
+					info = nodeInfo.clone()
+				}
+				info.addPod(nominated)
+			}
+		}
+
+		if err := p.runFilters(state, pod, info); err != nil {
+			failures[nodeInfo.Node.Name] = err
+			continue
+		}
+		feasible = append(feasible, nodeInfo)
+	}
+	return feasible, failures
+}
+
+// selectHost picks the highest scoring node, going round-robin among nodes that tie
+func (p *Profile) selectHost(feasible []*NodeInfo, scores map[string]*models.NodeScore) string {
+	var best []string
+	var bestScore int64
+	for _, nodeInfo := range feasible {
+		name := nodeInfo.Node.Name
+		total := scores[name].Total
+		switch {
+		case len(best) == 0 || total > bestScore:
+			best, bestScore = []string{name}, total
+		case total == bestScore:
+			best = append(best, name)
+		}
+	}
+
+	selected := best[p.nextNodeIdx%len(best)]
+	p.nextNodeIdx++
+	return selected
+}
@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"container/heap"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const (
+	initialBackoff = 1 * time.Second
+	maxBackoff     = 10 * time.Second
+
+	// unschedulable pods are retried after this long even if no cluster event moved them
+	unschedulableTimeout = 60 * time.Second
+
+	flushInterval = 1 * time.Second
+)
+
+type queuedPodInfo struct {
+	pod      *models.Pod
+	seq      uint64 // keeps pods of equal priority in arrival order
+	attempts int
+
+	// when the last attempt failed, and the scheduling cycle it was popped in
+	failedAt time.Time
+	cycle    int64
+
+	// explanation of the latest attempt
+	diagnosis *models.SchedulingDiagnosis
+}
+
+func (info *queuedPodInfo) backoffExpiry() time.Time {
+	backoff := initialBackoff
+	for i := 1; i < info.attempts && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	return info.failedAt.Add(backoff)
+}
+
+// podHeap implements heap.Interface, highest priority first
+type podHeap []*queuedPodInfo
+
+func (h podHeap) Len() int { return len(h) }
+
+func (h podHeap) Less(i, j int) bool {
+	if h[i].pod.Priority != h[j].pod.Priority {
+		return h[i].pod.Priority > h[j].pod.Priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h podHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *podHeap) Push(x any) { *h = append(*h, x.(*queuedPodInfo)) }
+
+func (h *podHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
+
+// schedulingQueue keeps pending pods in one of three places: the active heap of pods ready for an attempt, the
+// backoff pool of pods waiting out a retry delay, and the unschedulable pool of pods that only a change in the
+// cluster can help
+type schedulingQueue struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	activeQ       podHeap
+	backoffQ      map[string]*queuedPodInfo
+	unschedulable map[string]*queuedPodInfo
+
+	nextSeq          uint64
+	schedulingCycle  int64
+	moveRequestCycle int64
+}
+
+func newSchedulingQueue() *schedulingQueue {
+	q := &schedulingQueue{
+		backoffQ:         make(map[string]*queuedPodInfo),
+		unschedulable:    make(map[string]*queuedPodInfo),
+		moveRequestCycle: -1,
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// Run periodically moves pods whose backoff has expired, and pods left unschedulable for too long, back to active
+func (q *schedulingQueue) Run() {
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		q.flushBackoffCompleted()
+		q.flushUnschedulableLeftover()
+	}
+}
+
+func (q *schedulingQueue) activeIndex(key string) int {
+	for i, info := range q.activeQ {
+		if podKey(info.pod) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *schedulingQueue) pushActive(info *queuedPodInfo) {
+	heap.Push(&q.activeQ, info)
+	q.cond.Signal()
+}
+
+// Add queues a newly pending pod for an immediate attempt
+func (q *schedulingQueue) Add(pod *models.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := podKey(pod)
+	if i := q.activeIndex(key); i >= 0 {
+		q.activeQ[i].pod = pod
+		heap.Fix(&q.activeQ, i)
+		return
+	}
+	delete(q.backoffQ, key)
+	delete(q.unschedulable, key)
+
+	q.pushActive(&queuedPodInfo{pod: pod, seq: q.nextSeq})
+	q.nextSeq++
+}
+
+// Update refreshes the copy of a pod that is already queued, leaving it where it is
+func (q *schedulingQueue) Update(pod *models.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := podKey(pod)
+	if i := q.activeIndex(key); i >= 0 {
+		q.activeQ[i].pod = pod
+		heap.Fix(&q.activeQ, i)
+		return
+	}
+	if info, ok := q.backoffQ[key]; ok {
+		info.pod = pod
+		return
+	}
+	if info, ok := q.unschedulable[key]; ok {
+		info.pod = pod
+	}
+}
+
+func (q *schedulingQueue) Delete(pod *models.Pod) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := podKey(pod)
+	if i := q.activeIndex(key); i >= 0 {
+		heap.Remove(&q.activeQ, i)
+	}
+	delete(q.backoffQ, key)
+	delete(q.unschedulable, key)
+}
+
+// Pop blocks until a pod is active and starts a new scheduling cycle for it
+func (q *schedulingQueue) Pop() *queuedPodInfo {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.activeQ) == 0 {
+		q.cond.Wait()
+	}
+	info := heap.Pop(&q.activeQ).(*queuedPodInfo)
+	q.schedulingCycle++
+	info.cycle = q.schedulingCycle
+	info.attempts++
+	return info
+}
+
+// AddUnschedulable parks a pod that no node could take. If the cluster changed while the attempt was running the
+// pod only waits out its backoff, since the change may already have made room
+func (q *schedulingQueue) AddUnschedulable(info *queuedPodInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	info.failedAt = time.Now()
+	if q.moveRequestCycle >= info.cycle {
+		q.backoffQ[podKey(info.pod)] = info
+		return
+	}
+	q.unschedulable[podKey(info.pod)] = info
+}
+
+// AddBackoff retries a pod whose attempt failed for reasons unrelated to the cluster, such as an API error
+func (q *schedulingQueue) AddBackoff(info *queuedPodInfo) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	info.failedAt = time.Now()
+	q.backoffQ[podKey(info.pod)] = info
+}
+
+// MoveAllToActiveOrBackoff is called on cluster events that may make unschedulable pods fit
+func (q *schedulingQueue) MoveAllToActiveOrBackoff(reason string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.unschedulable) > 0 {
+		log.Printf("Moving %d unschedulable pods to the active queue on %s", len(q.unschedulable), reason)
+	}
+	now := time.Now()
+	for key, info := range q.unschedulable {
+		delete(q.unschedulable, key)
+		if now.Before(info.backoffExpiry()) {
+			q.backoffQ[key] = info
+		} else {
+			q.pushActive(info)
+		}
+	}
+	q.moveRequestCycle = q.schedulingCycle
+}
+
+func (q *schedulingQueue) flushBackoffCompleted() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for key, info := range q.backoffQ {
+		if !now.Before(info.backoffExpiry()) {
+			delete(q.backoffQ, key)
+			q.pushActive(info)
+		}
+	}
+}
+
+func (q *schedulingQueue) flushUnschedulableLeftover() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for key, info := range q.unschedulable {
+		if now.Sub(info.failedAt) > unschedulableTimeout {
+			delete(q.unschedulable, key)
+			q.pushActive(info)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package scheduler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const DefaultNamespace = "default"
+
+type Scheduler struct {
+	Client *client.Client
+
+	queue    *schedulingQueue
+	profiles map[string]*Profile
+	gangs    *gangManager
+}
+
+func NewScheduler(cl *client.Client, cfg *Configuration) (*Scheduler, error) {
+	extenders := make([]*HTTPExtender, 0, len(cfg.Extenders))
+	for _, extenderCfg := range cfg.Extenders {
+		extender, err := NewHTTPExtender(extenderCfg)
+		if err != nil {
+			return nil, err
+		}
+		extenders = append(extenders, extender)
+	}
+
+	profiles := make(map[string]*Profile, len(cfg.Profiles))
+	for _, profileCfg := range cfg.Profiles {
+		if _, exists := profiles[profileCfg.SchedulerName]; exists {
+			return nil, fmt.Errorf("duplicate scheduler profile %s", profileCfg.SchedulerName)
+		}
+		profile, err := NewProfile(profileCfg, extenders)
+		if err != nil {
+			return nil, err
+		}
+		profiles[profile.SchedulerName] = profile
+	}
+
+	return &Scheduler{
+		Client:   cl,
+		queue:    newSchedulingQueue(),
+		profiles: profiles,
+		gangs:    newGangManager(),
+	}, nil
+}
+
+// FitError reports that no node could run the pod, or Message when the pod was turned away before nodes were checked
+type FitError struct {
+	Pod       *models.Pod
+	NumNodes  int
+	Failures  map[string]error
+	Message   string
+	Diagnosis *models.SchedulingDiagnosis
+}
+
+func (f *FitError) Error() string {
+	if f.Message != "" {
+		return f.Message
+	}
+	if f.NumNodes == 0 {
+		return "no ready nodes available"
+	}
+	return fmt.Sprintf("0/%d nodes are available: %s", f.NumNodes, summarizeFailures(f.Failures))
+}
+
+// Run schedules pods off the queue while feeding it from the pod and node watches, returning once a watch is closed
+func (s *Scheduler) Run() error {
+	// watches are opened before the initial list so that nothing created in between is missed
+	podCh, err := s.Client.WatchPods(DefaultNamespace)
+	if err != nil {
+		return fmt.Errorf("error watching pods: %w", err)
+	}
+	nodeCh, err := s.Client.WatchNodes()
+	if err != nil {
+		return fmt.Errorf("error watching nodes: %w", err)
+	}
+
+	pendingPods, err := s.Client.ListPods(DefaultNamespace, models.PodPending)
+	if err != nil {
+		return fmt.Errorf("error listing pending pods: %w", err)
+	}
+	queued := 0
+	for i := range pendingPods {
+		if s.responsibleFor(&pendingPods[i]) && needsScheduling(&pendingPods[i]) {
+			s.queue.Add(&pendingPods[i])
+			queued++
+		}
+	}
+	log.Printf("Queued %d pending pods found at startup", queued)
+
+	go s.queue.Run()
+	go func() {
+		for {
+			s.scheduleOne(s.queue.Pop())
+		}
+	}()
+
+	for {
+		select {
+		case event, ok := <-podCh:
+			if !ok {
+				return fmt.Errorf("pod watch closed")
+			}
+			log.Printf("Received event: %v", event)
+			s.handlePodEvent(event)
+
+		case event, ok := <-nodeCh:
+			if !ok {
+				return fmt.Errorf("node watch closed")
+			}
+			log.Printf("Received event: %v", event)
+			s.handleNodeEvent(event)
+		}
+	}
+}
+
+// responsibleFor reports whether one of our profiles serves the pod. Pods addressed to other schedulers are left alone
+func (s *Scheduler) responsibleFor(pod *models.Pod) bool {
+	_, ok := s.profiles[pod.SchedulerName]
+	return ok
+}
+
+func needsScheduling(pod *models.Pod) bool {
+	return pod.Phase == models.PodPending && pod.NodeName == "" && pod.DeletionTimestamp == nil
+}
+
+func (s *Scheduler) handlePodEvent(event models.WatchEvent) {
+	pod := event.Pod
+
+	switch event.EventType {
+	case models.AddEvent:
+		if s.responsibleFor(pod) && needsScheduling(pod) {
+			s.queue.Add(pod)
+		}
+
+	case models.ModificationEvent:
+		if s.responsibleFor(pod) && needsScheduling(pod) {
+			s.queue.Update(pod)
+			return
+		}
+		s.queue.Delete(pod)
+		// a terminated pod frees what it held on its node
+		if pod.IsTerminated() {
+			s.queue.MoveAllToActiveOrBackoff("PodTerminated")
+		}
+
+	case models.DeletionEvent:
+		s.queue.Delete(pod)
+		s.gangs.dropReservation(pod)
+		s.queue.MoveAllToActiveOrBackoff("PodDeleted")
+	}
+}
+
+func (s *Scheduler) handleNodeEvent(event models.WatchEvent) {
+	node := event.Node
+
+	switch event.EventType {
+	case models.AddEvent:
+		s.queue.MoveAllToActiveOrBackoff("NodeAdded")
+
+	case models.ModificationEvent:
+		if node.Status == models.NodeReady {
+			s.queue.MoveAllToActiveOrBackoff("NodeReady")
+		}
+	}
+}
+
+func (s *Scheduler) scheduleOne(podInfo *queuedPodInfo) {
+	// the queued copy may be stale by the time it is popped
+	pod, err := s.Client.GetPod(podInfo.pod.Namespace, podInfo.pod.Name)
+	if err != nil {
+		log.Printf("Error fetching pod %s/%s: %v", podInfo.pod.Namespace, podInfo.pod.Name, err)
+		s.queue.AddBackoff(podInfo)
+		return
+	}
+
+	if pod.DeletionTimestamp != nil {
+		log.Printf("Scheduler could not schedule pod %s/%s that is marked for deletion", pod.Namespace, pod.Name)
+		return
+	}
+	if !needsScheduling(pod) {
+		return
+	}
+	profile, ok := s.profiles[pod.SchedulerName]
+	if !ok {
+		return
+	}
+	podInfo.pod = pod
+
+	if err := s.schedulePod(profile, podInfo); err != nil {
+		var fitErr *FitError
+		if errors.As(err, &fitErr) {
+			log.Printf("Pod %s/%s is unschedulable after %d attempts: %v", pod.Namespace, pod.Name, podInfo.attempts, err)
+			// one member that cannot fit means the group cannot start, so the others stop holding nodes
+			if pod.PodGroupName != "" {
+				s.releaseGang(podGroupKey(pod.Namespace, pod.PodGroupName), fmt.Sprintf("pod %s is unschedulable", pod.Name))
+			}
+			s.recordUnschedulable(profile, podInfo, fitErr)
+			s.queue.AddUnschedulable(podInfo)
+			return
+		}
+		log.Printf("Error scheduling pod %s/%s, retrying with backoff: %v", pod.Namespace, pod.Name, err)
+		s.queue.AddBackoff(podInfo)
+	}
+}
+
+func (s *Scheduler) schedulePod(profile *Profile, podInfo *queuedPodInfo) error {
+	pod := podInfo.pod
+	diagnosis := &models.SchedulingDiagnosis{
+		Profile: profile.SchedulerName,
+		Attempt: podInfo.attempts,
+		Time:    time.Now(),
+	}
+	podInfo.diagnosis = diagnosis
+
+	readyNodes, err := s.Client.ListNodes(models.NodeReady)
+	if err != nil {
+		return fmt.Errorf("error fetching nodes: %w", err)
+	}
+
+	if len(readyNodes) == 0 {
+		return &FitError{Pod: pod, Diagnosis: diagnosis}
+	}
+
+	pods, err := s.Client.ListPods(DefaultNamespace, "")
+	if err != nil {
+		return fmt.Errorf("error fetching pods: %w", err)
+	}
+
+	var group *models.PodGroup
+	if pod.PodGroupName != "" {
+		if group, err = s.checkGangQuorum(pod, pods); err != nil {
+			var fitErr *FitError
+			if errors.As(err, &fitErr) {
+				fitErr.Diagnosis = diagnosis
+			}
+			return err
+		}
+	}
+
+	snapshot := buildSnapshot(readyNodes, pods)
+	s.gangs.addReservationsTo(snapshot)
+	diagnosis.NodeCount = len(snapshot)
+
+	state := CycleState{}
+	if err := profile.runPreFilters(state, pod, snapshot); err != nil {
+		return err
+	}
+
+	feasibleNodes, failures := profile.findFeasibleNodes(state, pod, snapshot, pods)
+	feasibleNodes, err = profile.runExtenderFilters(pod, feasibleNodes, failures)
+	if err != nil {
+		return err
+	}
+	diagnosis.FilteredNodes = make(map[string]string, len(failures))
+	for name, failure := range failures {
+		diagnosis.FilteredNodes[name] = failure.Error()
+	}
+
+	if len(feasibleNodes) == 0 {
+		// evicting pods for a group that may still not fit as a whole would only strand the victims
+		if group == nil {
+			diagnosis.Preemption = s.preempt(profile, state, pod, snapshot)
+		}
+		return &FitError{Pod: pod, NumNodes: len(snapshot), Failures: failures, Diagnosis: diagnosis}
+	}
+
+	scores := profile.runScores(state, pod, feasibleNodes)
+	nodeName := profile.selectHost(feasibleNodes, scores)
+	for _, nodeScore := range scores {
+		diagnosis.Scores = append(diagnosis.Scores, *nodeScore)
+	}
+	sort.Slice(diagnosis.Scores, func(i, j int) bool {
+		if diagnosis.Scores[i].Total != diagnosis.Scores[j].Total {
+			return diagnosis.Scores[i].Total > diagnosis.Scores[j].Total
+		}
+		return diagnosis.Scores[i].Node < diagnosis.Scores[j].Node
+	})
+	diagnosis.SelectedNode = nodeName
+
+	if group != nil {
+		s.reserveGangMember(profile, podInfo, group, nodeName, pods)
+		return nil
+	}
+	return s.bind(profile, podInfo, nodeName)
+}
+
+func (s *Scheduler) bind(profile *Profile, podInfo *queuedPodInfo, nodeName string) error {
+	pod := podInfo.pod
+	if binder := profile.binder(); binder != nil {
+		if err := binder.Bind(pod, nodeName); err != nil {
+			return fmt.Errorf("error binding pod to node %s through extender: %w", nodeName, err)
+		}
+		log.Printf("Extender %s bound pod %s/%s to node %s", binder.Name(), pod.Namespace, pod.Name, nodeName)
+		s.recordScheduled(profile, podInfo, nodeName)
+		return nil
+	}
+
+	updatedPod := *pod
+	updatedPod.NodeName = nodeName
+	updatedPod.Phase = models.PodScheduled
+	updatedPod.NominatedNodeName = ""
+	updatedPod.Conditions = append([]models.PodCondition(nil), pod.Conditions...)
+	updatedPod.SetCondition(models.PodCondition{
+		Type:   models.PodScheduledCondition,
+		Status: models.ConditionTrue,
+	})
+
+	if _, err := s.Client.UpdatePod(&updatedPod); err != nil {
+		return fmt.Errorf("error binding pod to node %s: %w", nodeName, err)
+	}
+	log.Printf("Scheduled pod %s/%s to node %s with profile %s", pod.Namespace, pod.Name, nodeName, profile.SchedulerName)
+	s.recordScheduled(profile, podInfo, nodeName)
+	return nil
+}
+
+func summarizeFailures(failures map[string]error) string {
+	reasons := make([]string, 0, len(failures))
+	for nodeName, err := range failures {
+		reasons = append(reasons, fmt.Sprintf("%s (%v)", nodeName, err))
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ", ")
+}
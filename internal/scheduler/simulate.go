@@ -0,0 +1,241 @@
+package scheduler
+
+import (
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+// ClusterSnapshot is the state a simulation runs against, loaded from a file or pulled from an API server
+type ClusterSnapshot struct {
+	PriorityClasses []models.PriorityClass `json:"priorityClasses,omitempty"`
+	PodGroups       []models.PodGroup      `json:"podGroups,omitempty"`
+	Nodes           []models.Node          `json:"nodes"`
+	Pods            []models.Pod           `json:"pods"`
+}
+
+type Placement struct {
+	Pod     string `json:"pod"`
+	Node    string `json:"node"`
+	Profile string `json:"profile"`
+	Score   int64  `json:"score"`
+}
+
+type UnschedulablePod struct {
+	Pod        string `json:"pod"`
+	Reason     string `json:"reason"`
+	Preemption string `json:"preemption,omitempty"`
+}
+
+type NodeUtilization struct {
+	Node      string              `json:"node"`
+	Ready     bool                `json:"ready"`
+	Capacity  models.ResourceList `json:"capacity"`
+	Requested models.ResourceList `json:"requested"`
+}
+
+type SimulationResult struct {
+	Placements    []Placement        `json:"placements"`
+	Unschedulable []UnschedulablePod `json:"unschedulable"`
+	Ignored       []string           `json:"ignored,omitempty"` // pending pods addressed to schedulers not in the configuration
+	Utilization   []NodeUtilization  `json:"utilization"`
+}
+
+// Simulate runs the configured profiles over every pending pod in the snapshot, highest priority first, as if
+// each placement had been bound. Nothing is written anywhere: extenders are not called and preemption is only
+// reported, never carried out
+func Simulate(cfg *Configuration, cluster *ClusterSnapshot) (*SimulationResult, error) {
+	profiles := make(map[string]*Profile, len(cfg.Profiles))
+	for _, profileCfg := range cfg.Profiles {
+		profile, err := NewProfile(profileCfg, nil)
+		if err != nil {
+			return nil, err
+		}
+		profiles[profile.SchedulerName] = profile
+	}
+
+	pods := append([]models.Pod(nil), cluster.Pods...)
+	if err := applyPodDefaults(pods, cluster.PriorityClasses); err != nil {
+		return nil, err
+	}
+
+	var readyNodes []models.Node
+	for _, node := range cluster.Nodes {
+		if node.Status == models.NodeReady || node.Status == "" {
+			readyNodes = append(readyNodes, node)
+		}
+	}
+
+	var pending []int
+	for i := range pods {
+		if needsScheduling(&pods[i]) {
+			pending = append(pending, i)
+		}
+	}
+	sort.SliceStable(pending, func(a, b int) bool {
+		return pods[pending[a]].Priority > pods[pending[b]].Priority
+	})
+
+	// a pod group that does not reach minMember would never be bound, and the pods placed after it must not
+	// count on what it held, so placement starts over without it until every group left is complete
+	excluded := make(map[string]string) // pod group key to why its members are left out
+	var result *SimulationResult
+	var placed []models.Pod
+	for {
+		placed = append([]models.Pod(nil), pods...)
+		var placedIn map[int]bool
+		result, placedIn = placePending(profiles, readyNodes, placed, pending, excluded)
+		incomplete := incompleteGangs(placed, placedIn, cluster.PodGroups)
+		if len(incomplete) == 0 {
+			break
+		}
+		maps.Copy(excluded, incomplete)
+	}
+
+	final := buildSnapshot(readyNodes, placed)
+	for _, node := range cluster.Nodes {
+		utilization := NodeUtilization{Node: node.Name, Capacity: node.Capacity}
+		for _, nodeInfo := range final {
+			if nodeInfo.Node.Name == node.Name {
+				utilization.Ready = true
+				utilization.Requested = nodeInfo.Requested
+			}
+		}
+		result.Utilization = append(result.Utilization, utilization)
+	}
+	return result, nil
+}
+
+// placePending places the pending pods, in order, as if each placement had been bound. Members of the excluded
+// pod groups are reported unschedulable without being tried
+func placePending(profiles map[string]*Profile, readyNodes []models.Node, pods []models.Pod, pending []int, excluded map[string]string) (*SimulationResult, map[int]bool) {
+	result := &SimulationResult{}
+	placedIn := make(map[int]bool)
+
+	for _, idx := range pending {
+		pod := &pods[idx]
+		profile, ok := profiles[pod.SchedulerName]
+		if !ok {
+			result.Ignored = append(result.Ignored, fmt.Sprintf("%s (scheduler %s)", podKey(pod), pod.SchedulerName))
+			continue
+		}
+		if reason, ok := excluded[podGroupKey(pod.Namespace, pod.PodGroupName)]; ok && pod.PodGroupName != "" {
+			result.Unschedulable = append(result.Unschedulable, UnschedulablePod{Pod: podKey(pod), Reason: reason})
+			continue
+		}
+		if len(readyNodes) == 0 {
+			result.Unschedulable = append(result.Unschedulable, UnschedulablePod{Pod: podKey(pod), Reason: "no ready nodes available"})
+			continue
+		}
+
+		snapshot := buildSnapshot(readyNodes, pods)
+		state := CycleState{}
+		if err := profile.runPreFilters(state, pod, snapshot); err != nil {
+			result.Unschedulable = append(result.Unschedulable, UnschedulablePod{Pod: podKey(pod), Reason: err.Error()})
+			continue
+		}
+
+		feasible, failures := profile.findFeasibleNodes(state, pod, snapshot, pods)
+		if len(feasible) == 0 {
+			unschedulable := UnschedulablePod{
+				Pod:    podKey(pod),
+				Reason: (&FitError{Pod: pod, NumNodes: len(snapshot), Failures: failures}).Error(),
+			}
+			if podEligibleToPreempt(pod, snapshot) {
+				if candidate := profile.findPreemptionCandidate(state, pod, snapshot); candidate != nil {
+					unschedulable.Preemption = fmt.Sprintf("would preempt %d pods on node %s", len(candidate.victims), candidate.nodeInfo.Node.Name)
+				}
+			}
+			result.Unschedulable = append(result.Unschedulable, unschedulable)
+			continue
+		}
+
+		scores := profile.runScores(state, pod, feasible)
+		nodeName := profile.selectHost(feasible, scores)
+		pod.NodeName = nodeName
+		pod.Phase = models.PodScheduled
+
+		placedIn[idx] = true
+		result.Placements = append(result.Placements, Placement{
+			Pod:     podKey(pod),
+			Node:    nodeName,
+			Profile: profile.SchedulerName,
+			Score:   scores[nodeName].Total,
+		})
+	}
+	return result, placedIn
+}
+
+// incompleteGangs finds the pod groups with a placed member that do not reach minMember, along with why, since the
+// real scheduler would never bind them
+func incompleteGangs(pods []models.Pod, placedIn map[int]bool, groups []models.PodGroup) map[string]string {
+	minMembers := make(map[string]int32, len(groups))
+	for _, group := range groups {
+		minMembers[podGroupKey(group.Namespace, group.Name)] = group.MinMember
+	}
+
+	incomplete := make(map[string]string)
+	for idx := range placedIn {
+		pod := &pods[idx]
+		if pod.PodGroupName == "" {
+			continue
+		}
+		key := podGroupKey(pod.Namespace, pod.PodGroupName)
+		minMember, ok := minMembers[key]
+		if !ok {
+			incomplete[key] = fmt.Sprintf("pod group %s does not exist", pod.PodGroupName)
+			continue
+		}
+		if bound := countGangMembers(pods, pod.Namespace, pod.PodGroupName, true); bound < int(minMember) {
+			incomplete[key] = fmt.Sprintf("pod group %s only fits %d of the %d members it needs", pod.PodGroupName, bound, minMember)
+		}
+	}
+	return incomplete
+}
+
+// applyPodDefaults fills in what the API server would have set on pods that come straight from a file
+func applyPodDefaults(pods []models.Pod, priorityClasses []models.PriorityClass) error {
+	classes := make(map[string]models.PriorityClass, len(priorityClasses))
+	var globalDefault *models.PriorityClass
+	for i, pc := range priorityClasses {
+		classes[pc.Name] = pc
+		if pc.GlobalDefault {
+			globalDefault = &priorityClasses[i]
+		}
+	}
+
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Namespace == "" {
+			pod.Namespace = DefaultNamespace
+		}
+		if pod.SchedulerName == "" {
+			pod.SchedulerName = models.DefaultSchedulerName
+		}
+		if pod.Phase == "" {
+			pod.Phase = models.PodPending
+			if pod.NodeName != "" {
+				pod.Phase = models.PodRunning
+			}
+		}
+
+		if pod.Priority != 0 {
+			continue
+		}
+		if pod.PriorityClassName != "" {
+			pc, ok := classes[pod.PriorityClassName]
+			if !ok {
+				return fmt.Errorf("pod %s references unknown priority class %s", podKey(pod), pod.PriorityClassName)
+			}
+			pod.Priority = pc.Value
+			if pod.PreemptionPolicy == "" {
+				pod.PreemptionPolicy = pc.PreemptionPolicy
+			}
+		} else if globalDefault != nil {
+			pod.Priority = globalDefault.Value
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+func TestSimulateDoesNotCountTerminatingGangMembers(t *testing.T) {
+	deleted := time.Now()
+	cluster := &ClusterSnapshot{
+		PodGroups: []models.PodGroup{{Name: "workers", Namespace: "default", MinMember: 2}},
+		Nodes:     []models.Node{{Name: "n1", Status: models.NodeReady, Capacity: models.ResourceList{CPU: 4000, Memory: 1 << 30, Pods: 10}}},
+		Pods: []models.Pod{
+			{Name: "old", PodGroupName: "workers", NodeName: "n1", DeletionTimestamp: &deleted},
+			{Name: "new", PodGroupName: "workers"},
+		},
+	}
+
+	result, err := Simulate(DefaultConfiguration(), cluster)
+	if err != nil {
+		t.Fatalf("Simulate: %v", err)
+	}
+	if len(result.Placements) != 0 {
+		t.Errorf("placements = %v, want none while the only other member is being deleted", result.Placements)
+	}
+	if len(result.Unschedulable) != 1 || result.Unschedulable[0].Pod != "default/new" {
+		t.Errorf("unschedulable = %v, want default/new", result.Unschedulable)
+	}
+}
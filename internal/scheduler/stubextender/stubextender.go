@@ -0,0 +1,97 @@
+// Package stubextender is a stand-in scheduler extender for trying out the extender protocol. It rejects nodes
+// carrying one label, prefers nodes carrying another, and can be made slow or broken to exercise timeouts and
+// ignorable extenders. cmd/stub-extender serves it on its own and the scheduler's tests serve it with httptest
+package stubextender
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/client"
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+type Extender struct {
+	RejectKey, RejectValue string
+	PreferKey, PreferValue string
+	Delay                  time.Duration
+	Fail                   bool
+
+	// the bind verb binds pods through this API server
+	Client *client.Client
+}
+
+// Handler serves the filter, prioritize and bind verbs under /filter, /prioritize and /bind
+func (e *Extender) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/filter", e.filter)
+	mux.HandleFunc("/prioritize", e.prioritize)
+	mux.HandleFunc("/bind", e.bind)
+	return mux
+}
+
+func (e *Extender) handle(w http.ResponseWriter, r *http.Request, args any, respond func() any) {
+	time.Sleep(e.Delay)
+	if e.Fail {
+		http.Error(w, "stub extender configured to fail", http.StatusInternalServerError)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(args); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(respond())
+}
+
+func (e *Extender) filter(w http.ResponseWriter, r *http.Request) {
+	var args models.ExtenderArgs
+	e.handle(w, r, &args, func() any {
+		result := models.ExtenderFilterResult{FailedNodes: make(map[string]string)}
+		for _, node := range args.Nodes {
+			if e.RejectKey != "" && node.Labels[e.RejectKey] == e.RejectValue {
+				result.FailedNodes[node.Name] = fmt.Sprintf("node has label %s=%s", e.RejectKey, e.RejectValue)
+				continue
+			}
+			result.Nodes = append(result.Nodes, node)
+		}
+		log.Printf("Filter for pod %s/%s: %d of %d nodes pass", args.Pod.Namespace, args.Pod.Name, len(result.Nodes), len(args.Nodes))
+		return result
+	})
+}
+
+func (e *Extender) prioritize(w http.ResponseWriter, r *http.Request) {
+	var args models.ExtenderArgs
+	e.handle(w, r, &args, func() any {
+		result := make([]models.HostPriority, 0, len(args.Nodes))
+		for _, node := range args.Nodes {
+			var score int64
+			if e.PreferKey != "" && node.Labels[e.PreferKey] == e.PreferValue {
+				score = models.MaxExtenderPriority
+			}
+			result = append(result, models.HostPriority{Host: node.Name, Score: score})
+		}
+		return result
+	})
+}
+
+func (e *Extender) bind(w http.ResponseWriter, r *http.Request) {
+	var args models.ExtenderBindingArgs
+	e.handle(w, r, &args, func() any {
+		pod, err := e.Client.GetPod(args.PodNamespace, args.PodName)
+		if err != nil {
+			return models.ExtenderBindingResult{Error: err.Error()}
+		}
+		pod.NodeName = args.Node
+		pod.Phase = models.PodScheduled
+		pod.NominatedNodeName = ""
+		if _, err := e.Client.UpdatePod(pod); err != nil {
+			return models.ExtenderBindingResult{Error: err.Error()}
+		}
+		log.Printf("Bound pod %s/%s to node %s", args.PodNamespace, args.PodName, args.Node)
+		return models.ExtenderBindingResult{}
+	})
+}
@@ -0,0 +1,182 @@
+package scheduler
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+)
+
+const topologySpreadStateKey = "PodTopologySpread"
+
+type spreadConstraintState struct {
+	constraint models.TopologySpreadConstraint
+	counts     map[string]int  // matching pods per topology domain
+	eligible   map[string]bool // domains with a node the pod's node selector and affinity accept
+	minCount   int
+	selfMatch  int // the pod being scheduled counts towards its own skew if the selector matches it
+}
+
+// updateMinCount takes the minimum over the domains the pod could be placed in
+func (cs *spreadConstraintState) updateMinCount() {
+	cs.minCount = 0
+	first := true
+	for domain := range cs.eligible {
+		if count := cs.counts[domain]; first || count < cs.minCount {
+			cs.minCount = count
+		}
+		first = false
+	}
+}
+
+type topologySpreadState struct {
+	constraints []*spreadConstraintState
+}
+
+func (s *topologySpreadState) Clone() StateData {
+	clone := &topologySpreadState{constraints: make([]*spreadConstraintState, 0, len(s.constraints))}
+	for _, cs := range s.constraints {
+		copied := *cs
+		copied.counts = maps.Clone(cs.counts)
+		clone.constraints = append(clone.constraints, &copied)
+	}
+	return clone
+}
+
+// podTopologySpread enforces DoNotSchedule constraints as a filter and prefers the least crowded domains for
+// ScheduleAnyway constraints as a score
+type podTopologySpread struct{}
+
+func (podTopologySpread) Name() string { return "PodTopologySpread" }
+
+func (podTopologySpread) PreFilter(state CycleState, pod *models.Pod, snapshot []*NodeInfo) error {
+	spreadState := &topologySpreadState{}
+
+	for _, constraint := range pod.TopologySpreadConstraints {
+		cs := &spreadConstraintState{
+			constraint: constraint,
+			counts:     make(map[string]int),
+			eligible:   make(map[string]bool),
+		}
+		if constraint.LabelSelector.Matches(pod.Labels) {
+			cs.selfMatch = 1
+		}
+
+		// every value of the key seen on a node is a domain, even one that holds no matching pods yet. Only the
+		// domains the pod could be placed in, with a node its node selector and affinity accept, set the minimum
+		for _, nodeInfo := range snapshot {
+			domain, ok := nodeInfo.Node.Labels[constraint.TopologyKey]
+			if !ok {
+				continue
+			}
+			cs.counts[domain] += countMatchingPods(pod.Namespace, constraint.LabelSelector, nodeInfo.Pods)
+			if pod.MatchesNodeLabels(nodeInfo.Node.Labels) {
+				cs.eligible[domain] = true
+			}
+		}
+		cs.updateMinCount()
+		spreadState.constraints = append(spreadState.constraints, cs)
+	}
+
+	state[topologySpreadStateKey] = spreadState
+	return nil
+}
+
+func (podTopologySpread) AddPod(state CycleState, pod, podToAdd *models.Pod, nodeInfo *NodeInfo) error {
+	updateSpreadCounts(state, pod, podToAdd, nodeInfo, 1)
+	return nil
+}
+
+func (podTopologySpread) RemovePod(state CycleState, pod, podToRemove *models.Pod, nodeInfo *NodeInfo) error {
+	updateSpreadCounts(state, pod, podToRemove, nodeInfo, -1)
+	return nil
+}
+
+// updateSpreadCounts moves the count of every domain the node is in by delta, for the constraints that count the
+// pod placed on or taken off it
+func updateSpreadCounts(state CycleState, pod, changed *models.Pod, nodeInfo *NodeInfo, delta int) {
+	spreadState, ok := state[topologySpreadStateKey].(*topologySpreadState)
+	if !ok {
+		return
+	}
+	for _, cs := range spreadState.constraints {
+		domain, ok := nodeInfo.Node.Labels[cs.constraint.TopologyKey]
+		if !ok || countMatchingPods(pod.Namespace, cs.constraint.LabelSelector, []*models.Pod{changed}) == 0 {
+			continue
+		}
+		cs.counts[domain] += delta
+		cs.updateMinCount()
+	}
+}
+
+func (podTopologySpread) Filter(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) error {
+	spreadState, ok := state[topologySpreadStateKey].(*topologySpreadState)
+	if !ok {
+		return nil
+	}
+
+	for _, cs := range spreadState.constraints {
+		if cs.constraint.WhenUnsatisfiable == models.ScheduleAnyway {
+			continue
+		}
+		domain, ok := nodeInfo.Node.Labels[cs.constraint.TopologyKey]
+		if !ok {
+			return fmt.Errorf("node does not have topology key %s", cs.constraint.TopologyKey)
+		}
+		skew := cs.counts[domain] + cs.selfMatch - cs.minCount
+		if skew > int(cs.constraint.MaxSkew) {
+			return fmt.Errorf("placing pod in %s=%s would make the skew %d, exceeding maxSkew %d",
+				cs.constraint.TopologyKey, domain, skew, cs.constraint.MaxSkew)
+		}
+	}
+	return nil
+}
+
+func (podTopologySpread) Score(state CycleState, pod *models.Pod, nodeInfo *NodeInfo) int64 {
+	spreadState, ok := state[topologySpreadStateKey].(*topologySpreadState)
+	if !ok {
+		return 0
+	}
+
+	var score int64
+	for _, cs := range spreadState.constraints {
+		if cs.constraint.WhenUnsatisfiable != models.ScheduleAnyway {
+			continue
+		}
+		domain, ok := nodeInfo.Node.Labels[cs.constraint.TopologyKey]
+		if !ok {
+			// nodes outside every domain are ranked as if they were the most crowded
+			score += int64(maxCount(cs.counts))
+			continue
+		}
+		score += int64(cs.counts[domain])
+	}
+	return score
+}
+
+func (podTopologySpread) NormalizeScore(_ CycleState, _ *models.Pod, scores map[string]int64) {
+	normalizeReverse(scores)
+}
+
+func countMatchingPods(namespace string, selector *models.LabelSelector, pods []*models.Pod) int {
+	count := 0
+	for _, p := range pods {
+		if p.Namespace != namespace || p.DeletionTimestamp != nil {
+			continue
+		}
+		if selector.Matches(p.Labels) {
+			count++
+		}
+	}
+	return count
+}
+
+func maxCount(counts map[string]int) int {
+	highest := 0
+	for _, count := range counts {
+		if count > highest {
+			highest = count
+		}
+	}
+	return highest
+}
@@ -0,0 +1,107 @@
+package encryption
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshL1215/k8s-lite/internal/api/manifest"
+)
+
+// Resources that can be encrypted, as named in the configuration
+const (
+	ResourceSecrets    = "secrets"
+	ResourceConfigMaps = "configmaps"
+)
+
+// Config lists the resources to encrypt and the keys to do it with. It is read from a JSON or YAML file:
+//
+//	resources:
+//	  - resources: [secrets]
+//	    keys:
+//	      - name: key2
+//	        secret: <base64 encoded 16, 24 or 32 byte key>
+//	      - name: key1
+//	        secret: <base64 encoded 16, 24 or 32 byte key>
+//
+// Values are written with the first key and read with whichever key they name, so a key is rotated by adding the
+// new one first, re-encrypting everything and then removing the old one
+type Config struct {
+	Resources []ResourceConfig `json:"resources"`
+}
+
+type ResourceConfig struct {
+	Resources []string `json:"resources"`
+	Keys      []Key    `json:"keys"`
+	// writes values in plain text while the keys are still used to read encrypted ones, for turning encryption off
+	Identity bool `json:"identity,omitempty"`
+}
+
+type Key struct {
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading encryption configuration: %w", err)
+	}
+	var cfg Config
+	if err := manifest.Decode(data, &cfg); err != nil {
+		return nil, fmt.Errorf("error parsing encryption configuration: %w", err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("encryption configuration %s is invalid: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+func (cfg *Config) validate() error {
+	seen := make(map[string]bool)
+	for _, resource := range cfg.Resources {
+		if len(resource.Resources) == 0 {
+			return errors.New("an entry lists no resources")
+		}
+		for _, name := range resource.Resources {
+			if name != ResourceSecrets && name != ResourceConfigMaps {
+				return fmt.Errorf("resource %s cannot be encrypted, only %s and %s can", name, ResourceSecrets, ResourceConfigMaps)
+			}
+			if seen[name] {
+				return fmt.Errorf("resource %s is listed more than once", name)
+			}
+			seen[name] = true
+		}
+		if len(resource.Keys) == 0 && !resource.Identity {
+			return fmt.Errorf("resources %s have no keys", strings.Join(resource.Resources, ", "))
+		}
+		keyNames := make(map[string]bool)
+		for _, key := range resource.Keys {
+			if key.Name == "" || strings.Contains(key.Name, ":") {
+				return fmt.Errorf("key name %q must be non-empty and cannot contain ':'", key.Name)
+			}
+			if keyNames[key.Name] {
+				return fmt.Errorf("key %s is listed more than once", key.Name)
+			}
+			keyNames[key.Name] = true
+			if _, err := decodeKey(key.Secret); err != nil {
+				return fmt.Errorf("key %s: %w", key.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func decodeKey(secret string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(secret)
+	if err != nil {
+		return nil, fmt.Errorf("secret is not valid base64: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	}
+	return nil, fmt.Errorf("secret is %d bytes, AES needs 16, 24 or 32", len(key))
+}
@@ -0,0 +1,264 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+// EncryptedStore wraps another store and encrypts the values of the configured resources with AES-GCM before they
+// reach it. Names and everything but the data stay readable, as upstream. Other resources pass straight through
+type EncryptedStore struct {
+	store.StoreInterface
+
+	mu           sync.RWMutex
+	transformers map[string]*transformer
+}
+
+func CreateEncryptedStore(backend store.StoreInterface, cfg *Config) (*EncryptedStore, error) {
+	s := &EncryptedStore{StoreInterface: backend}
+	if err := s.SetConfig(cfg); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// SetConfig switches the store to new keys. Objects already stored keep the key they were written with until they
+// are written again
+func (s *EncryptedStore) SetConfig(cfg *Config) error {
+	transformers := make(map[string]*transformer)
+	for _, resource := range cfg.Resources {
+		t, err := newTransformer(resource)
+		if err != nil {
+			return err
+		}
+		for _, name := range resource.Resources {
+			transformers[name] = t
+		}
+	}
+	s.mu.Lock()
+	s.transformers = transformers
+	s.mu.Unlock()
+	return nil
+}
+
+// WatchConfig loads the configuration file again whenever its contents change, checking on the given interval. A
+// file that cannot be loaded leaves the current keys in place
+func (s *EncryptedStore) WatchConfig(path string, interval time.Duration) {
+	// the store was created from the file as it is now
+	var loaded [sha256.Size]byte
+	if data, err := os.ReadFile(path); err == nil {
+		loaded = sha256.Sum256(data)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("Error reading encryption configuration %s: %v", path, err)
+			continue
+		}
+		hash := sha256.Sum256(data)
+		if hash == loaded {
+			continue
+		}
+		cfg, err := LoadConfig(path)
+		if err == nil {
+			err = s.SetConfig(cfg)
+		}
+		if err != nil {
+			log.Printf("Error reloading encryption configuration, keeping the current keys: %v", err)
+			continue
+		}
+		loaded = hash
+		log.Printf("Reloaded encryption configuration from %s", path)
+	}
+}
+
+func (s *EncryptedStore) transformer(resource string) *transformer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.transformers[resource]
+}
+
+func location(resource, namespace, name, key string) string {
+	return fmt.Sprintf("%s/%s/%s/%s", resource, namespace, name, key)
+}
+
+// transformValues returns a copy of values with every value passed through fn, leaving values itself untouched as
+// the caller or the backend may still hold it
+func transformValues(values map[string][]byte, fn func(value []byte, key string) ([]byte, error)) (map[string][]byte, error) {
+	if values == nil {
+		return nil, nil
+	}
+	out := make(map[string][]byte, len(values))
+	for key, value := range values {
+		transformed, err := fn(value, key)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = transformed
+	}
+	return out, nil
+}
+
+func transformStrings(values map[string]string, fn func(value []byte, key string) ([]byte, error)) (map[string]string, error) {
+	if values == nil {
+		return nil, nil
+	}
+	out := make(map[string]string, len(values))
+	for key, value := range values {
+		transformed, err := fn([]byte(value), key)
+		if err != nil {
+			return nil, err
+		}
+		out[key] = string(transformed)
+	}
+	return out, nil
+}
+
+func (s *EncryptedStore) encryptSecret(secret *models.Secret) (*models.Secret, error) {
+	t := s.transformer(ResourceSecrets)
+	encrypted := *secret
+	var err error
+	encrypted.Data, err = transformValues(secret.Data, func(value []byte, key string) ([]byte, error) {
+		return t.encrypt(value, location(ResourceSecrets, secret.Namespace, secret.Name, key))
+	})
+	if err != nil {
+		return nil, err
+	}
+	encrypted.StringData = nil
+	return &encrypted, nil
+}
+
+func (s *EncryptedStore) decryptSecret(secret *models.Secret) (*models.Secret, error) {
+	t := s.transformer(ResourceSecrets)
+	decrypted := *secret
+	var err error
+	decrypted.Data, err = transformValues(secret.Data, func(value []byte, key string) ([]byte, error) {
+		return t.decrypt(value, location(ResourceSecrets, secret.Namespace, secret.Name, key))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
+}
+
+// config map keys are unique across Data and BinaryData, so the key alone locates a value
+func (s *EncryptedStore) encryptConfigMap(cm *models.ConfigMap) (*models.ConfigMap, error) {
+	t := s.transformer(ResourceConfigMaps)
+	encrypt := func(value []byte, key string) ([]byte, error) {
+		return t.encrypt(value, location(ResourceConfigMaps, cm.Namespace, cm.Name, key))
+	}
+	encrypted := *cm
+	var err error
+	if encrypted.Data, err = transformStrings(cm.Data, encrypt); err != nil {
+		return nil, err
+	}
+	if encrypted.BinaryData, err = transformValues(cm.BinaryData, encrypt); err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+func (s *EncryptedStore) decryptConfigMap(cm *models.ConfigMap) (*models.ConfigMap, error) {
+	t := s.transformer(ResourceConfigMaps)
+	decrypt := func(value []byte, key string) ([]byte, error) {
+		return t.decrypt(value, location(ResourceConfigMaps, cm.Namespace, cm.Name, key))
+	}
+	decrypted := *cm
+	var err error
+	if decrypted.Data, err = transformStrings(cm.Data, decrypt); err != nil {
+		return nil, err
+	}
+	if decrypted.BinaryData, err = transformValues(cm.BinaryData, decrypt); err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
+}
+
+func (s *EncryptedStore) CreateSecret(secret *models.Secret) error {
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		return err
+	}
+	return s.StoreInterface.CreateSecret(encrypted)
+}
+
+func (s *EncryptedStore) GetSecret(namespace, name string) (*models.Secret, error) {
+	secret, err := s.StoreInterface.GetSecret(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.decryptSecret(secret)
+}
+
+func (s *EncryptedStore) UpdateSecret(secret *models.Secret) error {
+	encrypted, err := s.encryptSecret(secret)
+	if err != nil {
+		return err
+	}
+	return s.StoreInterface.UpdateSecret(encrypted)
+}
+
+func (s *EncryptedStore) ListSecrets(namespace string) ([]*models.Secret, error) {
+	secrets, err := s.StoreInterface.ListSecrets(namespace)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*models.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		decrypted, err := s.decryptSecret(secret)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, decrypted)
+	}
+	return list, nil
+}
+
+func (s *EncryptedStore) CreateConfigMap(cm *models.ConfigMap) error {
+	encrypted, err := s.encryptConfigMap(cm)
+	if err != nil {
+		return err
+	}
+	return s.StoreInterface.CreateConfigMap(encrypted)
+}
+
+func (s *EncryptedStore) GetConfigMap(namespace, name string) (*models.ConfigMap, error) {
+	cm, err := s.StoreInterface.GetConfigMap(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return s.decryptConfigMap(cm)
+}
+
+func (s *EncryptedStore) UpdateConfigMap(cm *models.ConfigMap) error {
+	encrypted, err := s.encryptConfigMap(cm)
+	if err != nil {
+		return err
+	}
+	return s.StoreInterface.UpdateConfigMap(encrypted)
+}
+
+func (s *EncryptedStore) ListConfigMaps(namespace string) ([]*models.ConfigMap, error) {
+	configMaps, err := s.StoreInterface.ListConfigMaps(namespace)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]*models.ConfigMap, 0, len(configMaps))
+	for _, cm := range configMaps {
+		decrypted, err := s.decryptConfigMap(cm)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, decrypted)
+	}
+	return list, nil
+}
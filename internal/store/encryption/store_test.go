@@ -0,0 +1,115 @@
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store/memory"
+)
+
+func secretsConfig(keys ...Key) *Config {
+	return &Config{Resources: []ResourceConfig{{Resources: []string{ResourceSecrets}, Keys: keys}}}
+}
+
+func TestEncryptedStoreRotatesKeys(t *testing.T) {
+	backend := memory.CreateInMemoryStore()
+	s, err := CreateEncryptedStore(backend, secretsConfig(testKey("key1", 1)))
+	if err != nil {
+		t.Fatalf("CreateEncryptedStore: %v", err)
+	}
+	for _, name := range []string{"db", "cache"} {
+		secret := &models.Secret{Name: name, Namespace: "default", Data: map[string][]byte{"password": []byte("hunter2")}}
+		if err := s.CreateSecret(secret); err != nil {
+			t.Fatalf("CreateSecret: %v", err)
+		}
+	}
+	stored, err := backend.GetSecret("default", "db")
+	if err != nil {
+		t.Fatalf("backend GetSecret: %v", err)
+	}
+	if !bytes.HasPrefix(stored.Data["password"], []byte(EncryptedPrefix+"key1:")) {
+		t.Fatalf("backend holds %q, want it encrypted under key1", stored.Data["password"])
+	}
+
+	// rotate: the new key first, then rewrite one of the secrets as reencrypt would
+	if err := s.SetConfig(secretsConfig(testKey("key2", 2), testKey("key1", 1))); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	db, err := s.GetSecret("default", "db")
+	if err != nil {
+		t.Fatalf("GetSecret under the old key: %v", err)
+	}
+	if err := s.UpdateSecret(db); err != nil {
+		t.Fatalf("UpdateSecret: %v", err)
+	}
+	stored, _ = backend.GetSecret("default", "db")
+	if !bytes.HasPrefix(stored.Data["password"], []byte(EncryptedPrefix+"key2:")) {
+		t.Errorf("backend holds %q after rewriting, want it encrypted under key2", stored.Data["password"])
+	}
+
+	// with the old key gone, only the rewritten secret can still be read
+	if err := s.SetConfig(secretsConfig(testKey("key2", 2))); err != nil {
+		t.Fatalf("SetConfig: %v", err)
+	}
+	db, err = s.GetSecret("default", "db")
+	if err != nil || string(db.Data["password"]) != "hunter2" {
+		t.Errorf("GetSecret of the rewritten secret = %v, %v, want hunter2", db, err)
+	}
+	if _, err := s.GetSecret("default", "cache"); err == nil {
+		t.Error("GetSecret of a secret left under the removed key succeeded")
+	}
+}
+
+func TestEncryptedStoreBindsValuesToTheirObject(t *testing.T) {
+	backend := memory.CreateInMemoryStore()
+	s, err := CreateEncryptedStore(backend, secretsConfig(testKey("key1", 1)))
+	if err != nil {
+		t.Fatalf("CreateEncryptedStore: %v", err)
+	}
+	if err := s.CreateSecret(&models.Secret{Name: "db", Namespace: "default", Data: map[string][]byte{"password": []byte("hunter2")}}); err != nil {
+		t.Fatalf("CreateSecret: %v", err)
+	}
+	stored, err := backend.GetSecret("default", "db")
+	if err != nil {
+		t.Fatalf("backend GetSecret: %v", err)
+	}
+
+	// someone with access to the backend copies the ciphertext into another secret
+	copied := &models.Secret{Name: "mine", Namespace: "default", Data: map[string][]byte{"password": stored.Data["password"]}}
+	if err := backend.CreateSecret(copied); err != nil {
+		t.Fatalf("backend CreateSecret: %v", err)
+	}
+	if _, err := s.GetSecret("default", "mine"); err == nil {
+		t.Error("a ciphertext copied to another secret decrypted")
+	}
+}
+
+func TestEncryptedStoreListsEveryNamespace(t *testing.T) {
+	s, err := CreateEncryptedStore(memory.CreateInMemoryStore(), secretsConfig(testKey("key1", 1)))
+	if err != nil {
+		t.Fatalf("CreateEncryptedStore: %v", err)
+	}
+	for _, namespace := range []string{"default", "team-a"} {
+		secret := &models.Secret{Name: "db", Namespace: namespace, Data: map[string][]byte{"password": []byte(namespace)}}
+		if err := s.CreateSecret(secret); err != nil {
+			t.Fatalf("CreateSecret: %v", err)
+		}
+	}
+
+	all, err := s.ListSecrets("")
+	if err != nil {
+		t.Fatalf("ListSecrets: %v", err)
+	}
+	if len(all) != 2 {
+		t.Fatalf("ListSecrets(\"\") = %d secrets, want one from each namespace", len(all))
+	}
+	for _, secret := range all {
+		if string(secret.Data["password"]) != secret.Namespace {
+			t.Errorf("secret in %s holds %q, want it decrypted", secret.Namespace, secret.Data["password"])
+		}
+	}
+	if one, err := s.ListSecrets("team-a"); err != nil || len(one) != 1 {
+		t.Errorf("ListSecrets(team-a) = %d secrets, %v, want 1", len(one), err)
+	}
+}
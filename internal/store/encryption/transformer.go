@@ -0,0 +1,100 @@
+package encryption
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// EncryptedPrefix starts every encrypted value, followed by the name of its key and a colon, so a value written
+// before encryption was turned on can still be told apart and read as it is. No plain text value may start with it
+const EncryptedPrefix = "k8s:enc:aesgcm:v1:"
+
+// ErrReservedPrefix is returned for a plain text value that would be taken for an encrypted one when read back
+var ErrReservedPrefix = errors.New("value starts with the prefix reserved for encrypted values")
+
+// transformer encrypts the values of one resource kind
+type transformer struct {
+	// nil when values are written in plain text
+	writeKey *namedKey
+	keys     map[string]cipher.AEAD
+}
+
+type namedKey struct {
+	name string
+	aead cipher.AEAD
+}
+
+func newTransformer(cfg ResourceConfig) (*transformer, error) {
+	t := &transformer{keys: make(map[string]cipher.AEAD)}
+	for i, key := range cfg.Keys {
+		secret, err := decodeKey(key.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", key.Name, err)
+		}
+		block, err := aes.NewCipher(secret)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		t.keys[key.Name] = aead
+		if i == 0 && !cfg.Identity {
+			t.writeKey = &namedKey{name: key.Name, aead: aead}
+		}
+	}
+	return t, nil
+}
+
+// encrypt seals a value under the newest key. The location of the value is authenticated along with it, so a
+// ciphertext copied to another object or key does not decrypt
+func (t *transformer) encrypt(value []byte, location string) ([]byte, error) {
+	if t == nil || t.writeKey == nil {
+		if bytes.HasPrefix(value, []byte(EncryptedPrefix)) {
+			return nil, fmt.Errorf("%w: %s", ErrReservedPrefix, location)
+		}
+		return value, nil
+	}
+	nonce := make([]byte, t.writeKey.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %w", err)
+	}
+	sealed := t.writeKey.aead.Seal(nonce, nonce, value, []byte(location))
+	return []byte(EncryptedPrefix + t.writeKey.name + ":" + base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// decrypt opens a value written by encrypt with any of the keys, returning a plain text value as it is
+func (t *transformer) decrypt(value []byte, location string) ([]byte, error) {
+	rest, ok := strings.CutPrefix(string(value), EncryptedPrefix)
+	if !ok {
+		return value, nil
+	}
+	name, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return nil, fmt.Errorf("%s: malformed encrypted value", location)
+	}
+	var aead cipher.AEAD
+	if t != nil {
+		aead = t.keys[name]
+	}
+	if aead == nil {
+		return nil, fmt.Errorf("%s: encrypted with key %s, which is not configured", location, name)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%s: malformed encrypted value", location)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, []byte(location))
+	if err != nil {
+		return nil, fmt.Errorf("%s: error decrypting with key %s: %w", location, name, err)
+	}
+	return plain, nil
+}
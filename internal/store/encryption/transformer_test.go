@@ -0,0 +1,160 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func testKey(name string, fill byte) Key {
+	return Key{Name: name, Secret: base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{fill}, 32))}
+}
+
+func newTestTransformer(t *testing.T, cfg ResourceConfig) *transformer {
+	t.Helper()
+	tr, err := newTransformer(cfg)
+	if err != nil {
+		t.Fatalf("newTransformer: %v", err)
+	}
+	return tr
+}
+
+const testLocation = "secrets/default/db/password"
+
+func TestTransformerRoundTrip(t *testing.T) {
+	tr := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	plain := []byte("hunter2")
+
+	encrypted, err := tr.encrypt(plain, testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !bytes.HasPrefix(encrypted, []byte(EncryptedPrefix+"key1:")) {
+		t.Errorf("encrypted value %q does not name its key", encrypted)
+	}
+	if bytes.Contains(encrypted, plain) {
+		t.Errorf("encrypted value %q holds the plain text", encrypted)
+	}
+
+	decrypted, err := tr.decrypt(encrypted, testLocation)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if !bytes.Equal(decrypted, plain) {
+		t.Errorf("decrypted = %q, want %q", decrypted, plain)
+	}
+
+	// the same value encrypts differently every time
+	again, err := tr.encrypt(plain, testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if bytes.Equal(again, encrypted) {
+		t.Error("encrypting twice gave the same ciphertext")
+	}
+}
+
+func TestTransformerReadsPlainText(t *testing.T) {
+	tr := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	decrypted, err := tr.decrypt([]byte("written before encryption"), testLocation)
+	if err != nil {
+		t.Fatalf("decrypt: %v", err)
+	}
+	if string(decrypted) != "written before encryption" {
+		t.Errorf("decrypted = %q, want the value as it is", decrypted)
+	}
+}
+
+func TestTransformerKeyRotation(t *testing.T) {
+	old := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	underOld, err := old.encrypt([]byte("v1"), testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	// the new key goes first and writes, the old one still reads
+	rotated := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key2", 2), testKey("key1", 1)}})
+	if decrypted, err := rotated.decrypt(underOld, testLocation); err != nil || string(decrypted) != "v1" {
+		t.Fatalf("decrypt with the old key still configured = %q, %v, want v1", decrypted, err)
+	}
+	underNew, err := rotated.encrypt([]byte("v2"), testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if !bytes.HasPrefix(underNew, []byte(EncryptedPrefix+"key2:")) {
+		t.Errorf("value written after rotation %q is not under key2", underNew)
+	}
+
+	// once the old key is removed, what it encrypted can no longer be read
+	newOnly := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key2", 2)}})
+	if _, err := newOnly.decrypt(underOld, testLocation); err == nil || !strings.Contains(err.Error(), "key1") {
+		t.Errorf("decrypt with the old key removed: err = %v, want one naming key1", err)
+	}
+	if decrypted, err := newOnly.decrypt(underNew, testLocation); err != nil || string(decrypted) != "v2" {
+		t.Errorf("decrypt with the new key = %q, %v, want v2", decrypted, err)
+	}
+
+	// a key of the same name but different secret does not open the value
+	replaced := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 9)}})
+	if _, err := replaced.decrypt(underOld, testLocation); err == nil {
+		t.Error("decrypt with a different secret under the same key name succeeded")
+	}
+}
+
+func TestTransformerBindsLocation(t *testing.T) {
+	tr := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	encrypted, err := tr.encrypt([]byte("hunter2"), testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	for _, other := range []string{
+		"secrets/default/db/username",
+		"secrets/default/cache/password",
+		"secrets/other/db/password",
+		"configmaps/default/db/password",
+	} {
+		if _, err := tr.decrypt(encrypted, other); err == nil {
+			t.Errorf("value encrypted for %s decrypted at %s", testLocation, other)
+		}
+	}
+}
+
+func TestTransformerIdentity(t *testing.T) {
+	old := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	underOld, err := old.encrypt([]byte("v1"), testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+
+	identity := newTestTransformer(t, ResourceConfig{Identity: true, Keys: []Key{testKey("key1", 1)}})
+	written, err := identity.encrypt([]byte("v2"), testLocation)
+	if err != nil || string(written) != "v2" {
+		t.Errorf("identity encrypt = %q, %v, want the plain text", written, err)
+	}
+	if decrypted, err := identity.decrypt(underOld, testLocation); err != nil || string(decrypted) != "v1" {
+		t.Errorf("identity decrypt of an encrypted value = %q, %v, want v1", decrypted, err)
+	}
+}
+
+func TestPlainTextWritesRejectReservedPrefix(t *testing.T) {
+	value := []byte(EncryptedPrefix + "key1:not really")
+	var unconfigured *transformer
+	identity := newTestTransformer(t, ResourceConfig{Identity: true})
+	for name, tr := range map[string]*transformer{"unconfigured": unconfigured, "identity": identity} {
+		if _, err := tr.encrypt(value, testLocation); !errors.Is(err, ErrReservedPrefix) {
+			t.Errorf("%s: encrypt of a value with the reserved prefix: err = %v, want ErrReservedPrefix", name, err)
+		}
+	}
+
+	// written under a key, the value is sealed like any other and comes back as it was
+	tr := newTestTransformer(t, ResourceConfig{Keys: []Key{testKey("key1", 1)}})
+	encrypted, err := tr.encrypt(value, testLocation)
+	if err != nil {
+		t.Fatalf("encrypt: %v", err)
+	}
+	if decrypted, err := tr.decrypt(encrypted, testLocation); err != nil || !bytes.Equal(decrypted, value) {
+		t.Errorf("decrypt = %q, %v, want %q", decrypted, err, value)
+	}
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreateConfigMap(cm *models.ConfigMap) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(cm.Namespace, cm.Name)
+	if _, exists := s.configMaps[key]; exists {
+		return fmt.Errorf("%w: config map %s already exists in namespace %s", store.ErrConfigMapExists, cm.Name, cm.Namespace)
+	}
+	s.configMaps[key] = cm
+	return nil
+}
+
+func (s *InMemoryStore) GetConfigMap(namespace, name string) (*models.ConfigMap, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	cm, exists := s.configMaps[podKey(namespace, name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: no config map with name %s exists in namespace %s", store.ErrConfigMapNotExist, name, namespace)
+	}
+	return cm, nil
+}
+
+func (s *InMemoryStore) UpdateConfigMap(cm *models.ConfigMap) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(cm.Namespace, cm.Name)
+	if _, exists := s.configMaps[key]; !exists {
+		return fmt.Errorf("%w: no config map with name %s exists in namespace %s", store.ErrConfigMapNotExist, cm.Name, cm.Namespace)
+	}
+	s.configMaps[key] = cm
+	return nil
+}
+
+func (s *InMemoryStore) DeleteConfigMap(namespace, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(namespace, name)
+	if _, exists := s.configMaps[key]; !exists {
+		return fmt.Errorf("%w: no config map with name %s exists in namespace %s", store.ErrConfigMapNotExist, name, namespace)
+	}
+	delete(s.configMaps, key)
+	return nil
+}
+
+func (s *InMemoryStore) ListConfigMaps(namespace string) ([]*models.ConfigMap, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]*models.ConfigMap, 0)
+	for _, cm := range s.configMaps {
+		if namespace == "" || cm.Namespace == namespace {
+			list = append(list, cm)
+		}
+	}
+	return list, nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreateEvent(event *models.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(event.Namespace, event.Name)
+	if _, exists := s.events[key]; exists {
+		return fmt.Errorf("%w: event %s already exists in namespace %s", store.ErrEventExists, event.Name, event.Namespace)
+	}
+	s.events[key] = event
+	return nil
+}
+
+func (s *InMemoryStore) GetEvent(namespace, name string) (*models.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	event, exists := s.events[podKey(namespace, name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: no event with name %s exists in namespace %s", store.ErrEventNotExist, name, namespace)
+	}
+	return event, nil
+}
+
+func (s *InMemoryStore) UpdateEvent(event *models.Event) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(event.Namespace, event.Name)
+	if _, exists := s.events[key]; !exists {
+		return fmt.Errorf("%w: no event with name %s exists in namespace %s", store.ErrEventNotExist, event.Name, event.Namespace)
+	}
+	s.events[key] = event
+	return nil
+}
+
+func (s *InMemoryStore) DeleteEvent(namespace, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(namespace, name)
+	if _, exists := s.events[key]; !exists {
+		return fmt.Errorf("%w: no event with name %s exists in namespace %s", store.ErrEventNotExist, name, namespace)
+	}
+	delete(s.events, key)
+	return nil
+}
+
+func (s *InMemoryStore) ListEvents(namespace string) ([]*models.Event, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	eventList := make([]*models.Event, 0)
+	for _, event := range s.events {
+		if event.Namespace == namespace {
+			eventList = append(eventList, event)
+		}
+	}
+	return eventList, nil
+}
@@ -10,11 +10,29 @@ type InMemoryStore struct {
 	mutex sync.RWMutex
 	pods  map[string]*models.Pod
 	nodes map[string]*models.Node
+
+	priorityClasses map[string]*models.PriorityClass
+	podGroups       map[string]*models.PodGroup
+	events          map[string]*models.Event
+
+	podDisruptionBudgets map[string]*models.PodDisruptionBudget
+
+	configMaps map[string]*models.ConfigMap
+	secrets    map[string]*models.Secret
 }
 
 func CreateInMemoryStore() *InMemoryStore {
 	return &InMemoryStore{
 		pods:  make(map[string]*models.Pod),
 		nodes: make(map[string]*models.Node),
+
+		priorityClasses: make(map[string]*models.PriorityClass),
+		podGroups:       make(map[string]*models.PodGroup),
+		events:          make(map[string]*models.Event),
+
+		podDisruptionBudgets: make(map[string]*models.PodDisruptionBudget),
+
+		configMaps: make(map[string]*models.ConfigMap),
+		secrets:    make(map[string]*models.Secret),
 	}
 }
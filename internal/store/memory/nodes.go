@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
 )
 
 func (s *InMemoryStore) CreateNode(node *models.Node) error {
@@ -11,7 +12,7 @@ func (s *InMemoryStore) CreateNode(node *models.Node) error {
 	defer s.mutex.Unlock()
 
 	if _, exists := s.nodes[node.Name]; exists {
-		return fmt.Errorf("a node named %s already exists", node.Name)
+		return fmt.Errorf("%w: a node named %s already exists", store.ErrNodeExists, node.Name)
 	}
 	s.nodes[node.Name] = node
 	return nil
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreatePodDisruptionBudget(pdb *models.PodDisruptionBudget) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(pdb.Namespace, pdb.Name)
+	if _, exists := s.podDisruptionBudgets[key]; exists {
+		return fmt.Errorf("%w: pod disruption budget %s already exists in namespace %s", store.ErrPodDisruptionBudgetExists, pdb.Name, pdb.Namespace)
+	}
+	s.podDisruptionBudgets[key] = pdb
+	return nil
+}
+
+func (s *InMemoryStore) GetPodDisruptionBudget(namespace, name string) (*models.PodDisruptionBudget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pdb, exists := s.podDisruptionBudgets[podKey(namespace, name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: no pod disruption budget with name %s exists in namespace %s", store.ErrPodDisruptionBudgetNotExist, name, namespace)
+	}
+	return pdb, nil
+}
+
+func (s *InMemoryStore) UpdatePodDisruptionBudget(pdb *models.PodDisruptionBudget) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(pdb.Namespace, pdb.Name)
+	if _, exists := s.podDisruptionBudgets[key]; !exists {
+		return fmt.Errorf("%w: no pod disruption budget with name %s exists in namespace %s", store.ErrPodDisruptionBudgetNotExist, pdb.Name, pdb.Namespace)
+	}
+	s.podDisruptionBudgets[key] = pdb
+	return nil
+}
+
+func (s *InMemoryStore) DeletePodDisruptionBudget(namespace, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(namespace, name)
+	if _, exists := s.podDisruptionBudgets[key]; !exists {
+		return fmt.Errorf("%w: no pod disruption budget with name %s exists in namespace %s", store.ErrPodDisruptionBudgetNotExist, name, namespace)
+	}
+	delete(s.podDisruptionBudgets, key)
+	return nil
+}
+
+func (s *InMemoryStore) ListPodDisruptionBudgets(namespace string) ([]*models.PodDisruptionBudget, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pdbList := make([]*models.PodDisruptionBudget, 0)
+	for _, pdb := range s.podDisruptionBudgets {
+		if pdb.Namespace == namespace {
+			pdbList = append(pdbList, pdb)
+		}
+	}
+	return pdbList, nil
+}
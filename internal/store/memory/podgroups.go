@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreatePodGroup(pg *models.PodGroup) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(pg.Namespace, pg.Name)
+	if _, exists := s.podGroups[key]; exists {
+		return fmt.Errorf("%w: pod group %s already exists in namespace %s", store.ErrPodGroupExists, pg.Name, pg.Namespace)
+	}
+	s.podGroups[key] = pg
+	return nil
+}
+
+func (s *InMemoryStore) GetPodGroup(namespace, name string) (*models.PodGroup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pg, exists := s.podGroups[podKey(namespace, name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: no pod group with name %s exists in namespace %s", store.ErrPodGroupNotExist, name, namespace)
+	}
+	return pg, nil
+}
+
+func (s *InMemoryStore) DeletePodGroup(namespace, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(namespace, name)
+	if _, exists := s.podGroups[key]; !exists {
+		return fmt.Errorf("%w: no pod group with name %s exists in namespace %s", store.ErrPodGroupNotExist, name, namespace)
+	}
+	delete(s.podGroups, key)
+	return nil
+}
+
+func (s *InMemoryStore) ListPodGroups(namespace string) ([]*models.PodGroup, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pgList := make([]*models.PodGroup, 0)
+	for _, pg := range s.podGroups {
+		if pg.Namespace == namespace {
+			pgList = append(pgList, pg)
+		}
+	}
+	return pgList, nil
+}
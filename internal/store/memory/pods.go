@@ -18,7 +18,8 @@ func (s *InMemoryStore) CreatePod(pod *models.Pod) error {
 	defer s.mutex.Unlock()
 
 	key := podKey(pod.Namespace, pod.Name)
-	if _, exists := s.pods[key]; exists {
+	// a Deleted pod is only kept as a record of its last status, so its name may be taken again
+	if currPod, exists := s.pods[key]; exists && currPod.Phase != models.PodDeleted {
 		return fmt.Errorf("%w: pod %s already exists in namespace %s", store.ErrPodExists, pod.Name, pod.Namespace)
 	}
 	s.pods[key] = pod
@@ -49,7 +50,8 @@ func (s *InMemoryStore) UpdatePod(pod *models.Pod) error {
 		return fmt.Errorf("%w: no pod with name %s exists in namespace %s", store.ErrPodNotExist, pod.Name, pod.Namespace)
 	}
 
-	if currPod.DeletionTimestamp != nil {
+	// the kubelet still has to report status on a terminating pod, so only updates that drop the deletion mark are refused
+	if currPod.DeletionTimestamp != nil && pod.DeletionTimestamp == nil {
 		return fmt.Errorf("%w: cannot update pod %s in namespace %s, it is being deleted", store.ErrPodIsDeleting, pod.Namespace, pod.Name)
 	}
 
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreatePriorityClass(pc *models.PriorityClass) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.priorityClasses[pc.Name]; exists {
+		return fmt.Errorf("%w: a priority class named %s already exists", store.ErrPriorityClassExists, pc.Name)
+	}
+	s.priorityClasses[pc.Name] = pc
+	return nil
+}
+
+func (s *InMemoryStore) GetPriorityClass(name string) (*models.PriorityClass, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pc, exists := s.priorityClasses[name]
+	if !exists {
+		return nil, fmt.Errorf("%w: no priority class named %s", store.ErrPriorityClassNotExist, name)
+	}
+	return pc, nil
+}
+
+func (s *InMemoryStore) DeletePriorityClass(name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if _, exists := s.priorityClasses[name]; !exists {
+		return fmt.Errorf("%w: no priority class named %s to delete", store.ErrPriorityClassNotExist, name)
+	}
+	delete(s.priorityClasses, name)
+	return nil
+}
+
+func (s *InMemoryStore) ListPriorityClasses() ([]*models.PriorityClass, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	pcList := make([]*models.PriorityClass, 0, len(s.priorityClasses))
+	for _, pc := range s.priorityClasses {
+		pcList = append(pcList, pc)
+	}
+	return pcList, nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/joshL1215/k8s-lite/internal/api/models"
+	"github.com/joshL1215/k8s-lite/internal/store"
+)
+
+func (s *InMemoryStore) CreateSecret(secret *models.Secret) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(secret.Namespace, secret.Name)
+	if _, exists := s.secrets[key]; exists {
+		return fmt.Errorf("%w: secret %s already exists in namespace %s", store.ErrSecretExists, secret.Name, secret.Namespace)
+	}
+	s.secrets[key] = secret
+	return nil
+}
+
+func (s *InMemoryStore) GetSecret(namespace, name string) (*models.Secret, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	secret, exists := s.secrets[podKey(namespace, name)]
+	if !exists {
+		return nil, fmt.Errorf("%w: no secret with name %s exists in namespace %s", store.ErrSecretNotExist, name, namespace)
+	}
+	return secret, nil
+}
+
+func (s *InMemoryStore) UpdateSecret(secret *models.Secret) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(secret.Namespace, secret.Name)
+	if _, exists := s.secrets[key]; !exists {
+		return fmt.Errorf("%w: no secret with name %s exists in namespace %s", store.ErrSecretNotExist, secret.Name, secret.Namespace)
+	}
+	s.secrets[key] = secret
+	return nil
+}
+
+func (s *InMemoryStore) DeleteSecret(namespace, name string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := podKey(namespace, name)
+	if _, exists := s.secrets[key]; !exists {
+		return fmt.Errorf("%w: no secret with name %s exists in namespace %s", store.ErrSecretNotExist, name, namespace)
+	}
+	delete(s.secrets, key)
+	return nil
+}
+
+func (s *InMemoryStore) ListSecrets(namespace string) ([]*models.Secret, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	list := make([]*models.Secret, 0)
+	for _, secret := range s.secrets {
+		if namespace == "" || secret.Namespace == namespace {
+			list = append(list, secret)
+		}
+	}
+	return list, nil
+}
@@ -13,6 +13,24 @@ var ErrPodIsDeleting = errors.New("pod is already being deleted")
 var ErrNodeExists = errors.New("node already exists")
 var ErrNodeNotExist = errors.New("node of this name does not exist")
 
+var ErrPriorityClassExists = errors.New("priority class already exists")
+var ErrPriorityClassNotExist = errors.New("priority class of this name does not exist")
+
+var ErrPodGroupExists = errors.New("pod group already exists")
+var ErrPodGroupNotExist = errors.New("pod group of this name does not exist")
+
+var ErrEventExists = errors.New("event already exists")
+var ErrEventNotExist = errors.New("event of this name does not exist")
+
+var ErrPodDisruptionBudgetExists = errors.New("pod disruption budget already exists")
+var ErrPodDisruptionBudgetNotExist = errors.New("pod disruption budget of this name does not exist")
+
+var ErrConfigMapExists = errors.New("config map already exists")
+var ErrConfigMapNotExist = errors.New("config map of this name does not exist")
+
+var ErrSecretExists = errors.New("secret already exists")
+var ErrSecretNotExist = errors.New("secret of this name does not exist")
+
 // Defines an agnostic store interface
 type StoreInterface interface {
 	CreatePod(pod *models.Pod) error
@@ -26,4 +44,38 @@ type StoreInterface interface {
 	UpdateNode(node *models.Node) error
 	DeleteNode(name string) error
 	ListNodes() ([]*models.Node, error)
+
+	CreatePriorityClass(pc *models.PriorityClass) error
+	GetPriorityClass(name string) (*models.PriorityClass, error)
+	DeletePriorityClass(name string) error
+	ListPriorityClasses() ([]*models.PriorityClass, error)
+
+	CreatePodGroup(pg *models.PodGroup) error
+	GetPodGroup(namespace, name string) (*models.PodGroup, error)
+	DeletePodGroup(namespace, name string) error
+	ListPodGroups(namespace string) ([]*models.PodGroup, error)
+
+	CreateEvent(event *models.Event) error
+	GetEvent(namespace, name string) (*models.Event, error)
+	UpdateEvent(event *models.Event) error
+	DeleteEvent(namespace, name string) error
+	ListEvents(namespace string) ([]*models.Event, error)
+
+	CreatePodDisruptionBudget(pdb *models.PodDisruptionBudget) error
+	GetPodDisruptionBudget(namespace, name string) (*models.PodDisruptionBudget, error)
+	UpdatePodDisruptionBudget(pdb *models.PodDisruptionBudget) error
+	DeletePodDisruptionBudget(namespace, name string) error
+	ListPodDisruptionBudgets(namespace string) ([]*models.PodDisruptionBudget, error)
+
+	CreateConfigMap(cm *models.ConfigMap) error
+	GetConfigMap(namespace, name string) (*models.ConfigMap, error)
+	UpdateConfigMap(cm *models.ConfigMap) error
+	DeleteConfigMap(namespace, name string) error
+	ListConfigMaps(namespace string) ([]*models.ConfigMap, error) // every namespace when namespace is empty
+
+	CreateSecret(secret *models.Secret) error
+	GetSecret(namespace, name string) (*models.Secret, error)
+	UpdateSecret(secret *models.Secret) error
+	DeleteSecret(namespace, name string) error
+	ListSecrets(namespace string) ([]*models.Secret, error) // every namespace when namespace is empty
 }